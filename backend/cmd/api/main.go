@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"minitask/database"
 	"minitask/internal/handler"
+	"minitask/internal/mailer"
+	"minitask/internal/middleware"
 	"minitask/internal/models"
+	"minitask/internal/realtime"
 	"minitask/internal/repository"
 	"minitask/internal/router"
+	"minitask/internal/scheduler"
 	"minitask/internal/service"
 	"os"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -18,13 +24,61 @@ func main() {
 	db := database.InitDB()
 
 	fmt.Println("Running migrations...")
+	// backfill di-queue sebelum AutoMigrate ngubah schema nya, jadi kalo gagal / crash di tengah
+	// masih kecatet & dicoba lagi pas start berikutnya
+	if err := db.AutoMigrate(&models.SchemaMigration{}); err != nil {
+		panic("Failed to migrate schema_migrations: " + err.Error())
+	}
+	migrationRepo := repository.NewSchemaMigrationRepository(db)
+	// watcher table baru -> creator & assignee task lama otomatis jadi watcher
+	if !db.Migrator().HasTable(&models.TaskWatcher{}) {
+		queueMigration(migrationRepo, "backfill_task_watchers")
+	}
+	// kolom verifikasi baru -> akun yg udah ada dianggep verified, gk tiba2 ke-lock
+	if !db.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt") {
+		queueMigration(migrationRepo, "backfill_email_verified")
+	}
+	// mention & notif dobel lama harus dibuang dulu sebelum unique index nya bisa dibikin
+	if db.Migrator().HasTable(&models.Mention{}) && !db.Migrator().HasIndex(&models.Mention{}, "idx_mention_comment_user") {
+		if err := repository.NewMentionRepository(db).RemoveDuplicates(); err != nil {
+			panic("Failed to remove duplicate mentions: " + err.Error())
+		}
+	}
+	if db.Migrator().HasTable(&models.Notification{}) && !db.Migrator().HasIndex(&models.Notification{}, "idx_notification_dedupe") {
+		if err := repository.NewNotificationRepository(db).RemoveDuplicates(); err != nil {
+			panic("Failed to remove duplicate notifications: " + err.Error())
+		}
+	}
 	err := db.AutoMigrate(
 		&models.User{},
 		&models.Task{},
 		&models.Comment{},
+		&models.CommentRevision{},
 		&models.Workspace{},
 		&models.WorkspaceMember{},
 		&models.PendingOrder{},
+		&models.Mention{},
+		&models.Reaction{},
+		&models.Activity{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.TaskWatcher{},
+		&models.TaskReminder{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.IncomingWebhook{},
+		&models.IntegrationTask{},
+		&models.GitIntegration{},
+		&models.TaskLink{},
+		&models.Attachment{},
+		&models.InboundMailbox{},
+		&models.InboundEmail{},
+		&models.CalendarFeed{},
+		&models.RefreshToken{},
+		&models.Session{},
+		&models.PasswordResetToken{},
+		&models.EmailVerificationToken{},
+		&models.StreamTicket{},
 	)
 	if err != nil {
 		panic("Failed to migrate tables: " + err.Error())
@@ -35,22 +89,129 @@ func main() {
 	taskRepo := repository.NewTaskRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
 	workspaceRepo := repository.NewWorkspaceRepository(db)
+	mentionRepo := repository.NewMentionRepository(db)
+	reactionRepo := repository.NewReactionRepository(db)
+	activityRepo := repository.NewActivityRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	watcherRepo := repository.NewTaskWatcherRepository(db)
+	reminderRepo := repository.NewReminderRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	incomingWebhookRepo := repository.NewIncomingWebhookRepository(db)
+	gitIntegrationRepo := repository.NewGitIntegrationRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	inboundMailboxRepo := repository.NewInboundMailboxRepository(db)
+	calendarFeedRepo := repository.NewCalendarFeedRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	verificationRepo := repository.NewEmailVerificationRepository(db)
+	streamTicketRepo := repository.NewStreamTicketRepository(db)
+
+	runMigrations(migrationRepo, map[string]func(before time.Time) error{
+		"backfill_task_watchers":  watcherRepo.Backfill,
+		"backfill_email_verified": userRepo.BackfillEmailVerified,
+	})
+	if err := workspaceRepo.BackfillKeyPrefixes(); err != nil {
+		log.Printf("Failed to backfill workspace key prefixes: %v", err)
+	}
+	if err := workspaceRepo.BackfillTaskNumbers(); err != nil {
+		log.Printf("Failed to backfill task numbers: %v", err)
+	}
+
+	// REALTIME_BROKER=postgres kalo jalan lebih dari 1 instance
+	var broker realtime.Broker = realtime.NewMemoryBroker()
+	if os.Getenv("REALTIME_BROKER") == "postgres" {
+		broker = realtime.NewPostgresBroker(db, database.DSN())
+	}
+	hub := realtime.NewHub(broker)
+	go hub.Run(context.Background())
+
+	// event dari service masuk ke SSE hub sama outgoing webhook
+	webhookService := service.NewWebhookService(db, webhookRepo, workspaceRepo)
+	publisher := realtime.Publishers{hub, webhookService}
 
-	authService := service.NewAuthService(db, userRepo)
-	taskService := service.NewTaskService(db, taskRepo)
-	commentService := service.NewCommentService(db, commentRepo, taskRepo)
-	workspaceService := service.NewWorkspaceService(db, workspaceRepo, taskRepo, userRepo)
-	paymentService := service.NewPaymentService(db, userRepo)
+	accessPolicy := service.NewTaskAccessPolicy(taskRepo, workspaceRepo)
+	activityService := service.NewActivityService(db, activityRepo, workspaceRepo, accessPolicy)
+	mail := mailer.NewFromEnv()
+	notificationService := service.NewNotificationService(db, notificationRepo, userRepo, mail)
+	watcherService := service.NewWatcherService(db, watcherRepo, accessPolicy, notificationService)
+	reminderService := service.NewReminderService(db, taskRepo, userRepo, reminderRepo, accessPolicy, notificationService, watcherService)
+	mentionService := service.NewMentionService(db, mentionRepo, userRepo, workspaceRepo, notificationService)
+	reactionService := service.NewReactionService(db, reactionRepo, commentRepo, accessPolicy)
+	authService := service.NewAuthService(db, userRepo, refreshTokenRepo, sessionRepo, passwordResetRepo, verificationRepo, mail)
+	// token punya akun yg udah dihapus / sesi yg udah di-revoke langsung ditolak
+	middleware.SetTokenValidator(authService.ValidateToken)
+	taskService := service.NewTaskService(db, taskRepo, mentionService, reactionService, activityService, watcherService, reminderService)
+	commentService := service.NewCommentService(db, commentRepo, taskRepo, accessPolicy, mentionService, reactionService, activityService, publisher, watcherService)
+	workspaceService := service.NewWorkspaceService(db, workspaceRepo, taskRepo, userRepo, mentionService, reactionService, activityService, publisher, notificationService, watcherService, reminderService)
+	incomingWebhookService := service.NewIncomingWebhookService(db, incomingWebhookRepo, workspaceRepo, userRepo, workspaceService)
+	gitService := service.NewGitService(db, gitIntegrationRepo, workspaceRepo, taskRepo, accessPolicy, workspaceService)
+	attachmentService := service.NewAttachmentService(db, attachmentRepo, accessPolicy)
+	inboundEmailService := service.NewInboundEmailService(db, inboundMailboxRepo, workspaceRepo, userRepo, taskRepo, workspaceService, commentService, attachmentService)
+	exportService := service.NewExportService(db, taskRepo, workspaceRepo)
+	importService := service.NewImportService(db, taskRepo, userRepo, workspaceRepo, taskService, workspaceService, commentService)
+	calendarService := service.NewCalendarService(db, calendarFeedRepo, taskRepo, workspaceRepo)
+	sessionService := service.NewSessionService(db, sessionRepo)
+	streamTicketService := service.NewStreamTicketService(db, streamTicketRepo)
+	middleware.SetStreamTicketRedeemer(streamTicketService.Redeem)
+	accountService := service.NewAccountService(db, userRepo, workspaceRepo, workspaceService, publisher)
+	backupService := service.NewBackupService(db, workspaceRepo, taskRepo, userRepo, attachmentRepo, attachmentService, workspaceService)
+	paymentService := service.NewPaymentService(db, userRepo, activityService, notificationService)
 
 	authHandler := handler.NewAuthHandler(authService)
 	taskHandler := handler.NewTaskHandler(taskService)
 	commentHandler := handler.NewCommentHandler(commentService)
 	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
 	paymentHandler := handler.NewPaymentHandler(paymentService)
+	mentionHandler := handler.NewMentionHandler(mentionService)
+	reactionHandler := handler.NewReactionHandler(reactionService)
+	activityHandler := handler.NewActivityHandler(activityService)
+	realtimeHandler := handler.NewRealtimeHandler(hub, workspaceService, streamTicketService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	watcherHandler := handler.NewWatcherHandler(watcherService)
+	reminderHandler := handler.NewReminderHandler(reminderService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	incomingWebhookHandler := handler.NewIncomingWebhookHandler(incomingWebhookService)
+	gitHandler := handler.NewGitHandler(gitService)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService)
+	inboundEmailHandler := handler.NewInboundEmailHandler(inboundEmailService)
+	exportHandler := handler.NewExportHandler(exportService)
+	importHandler := handler.NewImportHandler(importService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+	backupHandler := handler.NewBackupHandler(backupService)
+	accountHandler := handler.NewAccountHandler(accountService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+
+	// background jobs, kalo ada beberapa instance cuma satu yg jalanin (advisory lock)
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic("Failed to get database connection: " + err.Error())
+	}
+	jobs := scheduler.New(sqlDB)
+	jobs.Every("send task reminders", time.Minute, func() error {
+		return reminderService.SendTaskReminders(time.Now())
+	})
+	jobs.Every("send due date reminders", 5*time.Minute, func() error {
+		return reminderService.NotifyDueWindows(time.Now())
+	})
+	jobs.Every("send daily digests", time.Hour, func() error {
+		return reminderService.SendDailyDigests(time.Now())
+	})
+	jobs.Every("check expiring plans", time.Hour, paymentService.NotifyExpiringPlans)
+	jobs.Every("retry webhook deliveries", time.Minute, webhookService.RetryDeliveries)
+	jobs.Every("purge expired sessions", time.Hour, authService.PurgeExpiredTokens)
+	jobs.Every("purge expired stream tickets", time.Hour, streamTicketService.PurgeExpired)
+	// INBOUND_MAILDIR buat setup yg mail server nya nulis langsung ke maildir lokal
+	if maildir := os.Getenv("INBOUND_MAILDIR"); maildir != "" {
+		jobs.Every("process inbound maildir", time.Minute, func() error {
+			return inboundEmailService.ProcessMaildir(maildir)
+		})
+	}
+	go jobs.Run(context.Background())
 
 	e := echo.New()
 
-	r := router.NewRouter(authHandler, taskHandler, commentHandler, workspaceHandler, paymentHandler)
+	r := router.NewRouter(authHandler, taskHandler, commentHandler, workspaceHandler, paymentHandler, mentionHandler, reactionHandler, activityHandler, realtimeHandler, notificationHandler, watcherHandler, reminderHandler, webhookHandler, incomingWebhookHandler, gitHandler, attachmentHandler, inboundEmailHandler, exportHandler, importHandler, calendarHandler, backupHandler, accountHandler, sessionHandler)
 	r.Setup(e)
 
 	port := os.Getenv("PORT")
@@ -63,3 +224,33 @@ func main() {
 		log.Fatal("Failed to start server: ", err)
 	}
 }
+
+func queueMigration(migrationRepo repository.SchemaMigrationRepository, name string) {
+	if err := migrationRepo.Queue(name); err != nil {
+		panic("Failed to queue migration " + name + ": " + err.Error())
+	}
+}
+
+// runMigrations runs every queued step that hasn't succeeded yet. Step nya dapet waktu dia di-queue,
+// data yg lebih baru dari itu gk usah di-backfill
+func runMigrations(migrationRepo repository.SchemaMigrationRepository, steps map[string]func(before time.Time) error) {
+	pending, err := migrationRepo.FindPending()
+	if err != nil {
+		log.Printf("Failed to load pending migrations: %v", err)
+		return
+	}
+	for _, migration := range pending {
+		step, ok := steps[migration.Name]
+		if !ok {
+			log.Printf("Unknown migration %s, skipping", migration.Name)
+			continue
+		}
+		if err := step(migration.CreatedAt); err != nil {
+			log.Printf("Migration %s failed, retrying on next start: %v", migration.Name, err)
+			continue
+		}
+		if err := migrationRepo.MarkApplied(migration.Name, time.Now()); err != nil {
+			log.Printf("Failed to record migration %s: %v", migration.Name, err)
+		}
+	}
+}
@@ -24,27 +24,7 @@ func InitDB() *gorm.DB { // buat load env
 		log.Println("Warning: Could not load .env file from any path")
 	}
 
-	// DSN stands for Data Source Name
-	password := os.Getenv("DB_PASSWORD")
-	var dsn string
-	if password != "" {
-		dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-			os.Getenv("DB_HOST"),
-			os.Getenv("DB_USER"),
-			password,
-			os.Getenv("DB_NAME"),
-			os.Getenv("DB_PORT"),
-			os.Getenv("DB_SSLMODE"),
-		)
-	} else {
-		dsn = fmt.Sprintf("host=%s user=%s dbname=%s port=%s sslmode=%s",
-			os.Getenv("DB_HOST"),
-			os.Getenv("DB_USER"),
-			os.Getenv("DB_NAME"),
-			os.Getenv("DB_PORT"),
-			os.Getenv("DB_SSLMODE"),
-		)
-	}
+	dsn := DSN()
 
 	fmt.Printf("Connecting with DSN: host=%s user=%s dbname=%s port=%s\n",
 		os.Getenv("DB_HOST"), os.Getenv("DB_USER"), os.Getenv("DB_NAME"), os.Getenv("DB_PORT"))
@@ -57,3 +37,25 @@ func InitDB() *gorm.DB { // buat load env
 	fmt.Println("Database connected successfully")
 	return db
 }
+
+// DSN stands for Data Source Name
+func DSN() string {
+	password := os.Getenv("DB_PASSWORD")
+	if password != "" {
+		return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+			os.Getenv("DB_HOST"),
+			os.Getenv("DB_USER"),
+			password,
+			os.Getenv("DB_NAME"),
+			os.Getenv("DB_PORT"),
+			os.Getenv("DB_SSLMODE"),
+		)
+	}
+	return fmt.Sprintf("host=%s user=%s dbname=%s port=%s sslmode=%s",
+		os.Getenv("DB_HOST"),
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_NAME"),
+		os.Getenv("DB_PORT"),
+		os.Getenv("DB_SSLMODE"),
+	)
+}
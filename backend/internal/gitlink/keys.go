@@ -0,0 +1,46 @@
+package gitlink
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	keyPattern   = regexp.MustCompile(`(?i)\b([a-z][a-z0-9]{1,9}-\d+)\b`)
+	closePattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\b:?\s+#?([a-z][a-z0-9]{1,9}-\d+)\b`)
+)
+
+// Reference is one task key mentioned in a commit message, PR title/body or branch name
+type Reference struct {
+	Key    string // uppercase, e.g. WS-42
+	Closes bool   // preceded by a closing keyword ("fixes WS-42")
+}
+
+// ParseReferences finds task keys with the given prefix in text. Keys are matched case-insensitively
+// ("ws-42" in a branch name counts) and returned uppercased, once each, in order of appearance
+func ParseReferences(prefix string, texts ...string) []Reference {
+	prefix = strings.ToUpper(prefix) + "-"
+	closing := map[string]bool{}
+	var order []string
+	seen := map[string]bool{}
+
+	for _, text := range texts {
+		for _, match := range closePattern.FindAllStringSubmatch(text, -1) {
+			closing[strings.ToUpper(match[1])] = true
+		}
+		for _, match := range keyPattern.FindAllStringSubmatch(text, -1) {
+			key := strings.ToUpper(match[1])
+			if !strings.HasPrefix(key, prefix) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			order = append(order, key)
+		}
+	}
+
+	refs := make([]Reference, 0, len(order))
+	for _, key := range order {
+		refs = append(refs, Reference{Key: key, Closes: closing[key]})
+	}
+	return refs
+}
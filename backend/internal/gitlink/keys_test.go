@@ -0,0 +1,34 @@
+package gitlink
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReferences(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		texts  []string
+		want   []Reference
+	}{
+		{"none", "WS", []string{"refactor everything"}, []Reference{}},
+		{"single", "WS", []string{"WS-42 add login"}, []Reference{{Key: "WS-42"}}},
+		{"branch name lowercase", "ws", []string{"feature/ws-7-export"}, []Reference{{Key: "WS-7"}}},
+		{"other prefix ignored", "WS", []string{"OPS-1 and WS-2"}, []Reference{{Key: "WS-2"}}},
+		{"prefix must match whole", "WS", []string{"NEWS-3"}, []Reference{}},
+		{"dedup across texts", "WS", []string{"WS-1 first", "again WS-1, then WS-3"}, []Reference{{Key: "WS-1"}, {Key: "WS-3"}}},
+		{"closing keywords", "WS", []string{"Fixes WS-5, closes #ws-6; resolved: WS-7 and refs WS-8"}, []Reference{
+			{Key: "WS-5", Closes: true}, {Key: "WS-6", Closes: true}, {Key: "WS-7", Closes: true}, {Key: "WS-8"},
+		}},
+		{"close in another text", "WS", []string{"ws-9-branch", "fix ws-9"}, []Reference{{Key: "WS-9", Closes: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseReferences(tt.prefix, tt.texts...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseReferences() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
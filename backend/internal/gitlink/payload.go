@@ -0,0 +1,225 @@
+package gitlink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+var ErrUnsupportedEvent = errors.New("unsupported event")
+
+// Commit is a pushed commit, provider independent
+type Commit struct {
+	ID        string
+	Message   string
+	URL       string
+	Author    string
+	Timestamp time.Time
+}
+
+// PullRequest covers GitHub pull requests and GitLab merge requests
+type PullRequest struct {
+	Number int
+	Title  string
+	Body   string
+	Branch string
+	URL    string
+	Author string
+	State  string // open, closed, merged
+}
+
+// Event is a normalized push or pull request payload. Ping events come back with neither set
+type Event struct {
+	Provider    string
+	Repository  string
+	Branch      string
+	Commits     []Commit
+	PullRequest *PullRequest
+}
+
+// DetectProvider tells GitHub and GitLab deliveries apart by their event header
+func DetectProvider(h http.Header) string {
+	switch {
+	case h.Get("X-GitHub-Event") != "":
+		return ProviderGitHub
+	case h.Get("X-Gitlab-Event") != "":
+		return ProviderGitLab
+	}
+	return ""
+}
+
+// Verify checks the delivery against the integration secret. GitHub signs the body with
+// HMAC-SHA256 (X-Hub-Signature-256), GitLab just echoes the secret back in X-Gitlab-Token
+func Verify(provider string, h http.Header, body []byte, secret string) bool {
+	switch provider {
+	case ProviderGitHub:
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(expected), []byte(h.Get("X-Hub-Signature-256")))
+	case ProviderGitLab:
+		return subtle.ConstantTimeCompare([]byte(secret), []byte(h.Get("X-Gitlab-Token"))) == 1
+	}
+	return false
+}
+
+// Parse turns a provider payload into an Event
+func Parse(provider string, h http.Header, body []byte) (*Event, error) {
+	switch provider {
+	case ProviderGitHub:
+		return parseGitHub(h.Get("X-GitHub-Event"), body)
+	case ProviderGitLab:
+		return parseGitLab(h.Get("X-Gitlab-Event"), body)
+	}
+	return nil, ErrUnsupportedEvent
+}
+
+type githubPayload struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Commits []struct {
+		ID        string    `json:"id"`
+		Message   string    `json:"message"`
+		URL       string    `json:"url"`
+		Timestamp time.Time `json:"timestamp"`
+		Author    struct {
+			Name     string `json:"name"`
+			Username string `json:"username"`
+		} `json:"author"`
+	} `json:"commits"`
+	PullRequest *struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+		State   string `json:"state"`
+		Merged  bool   `json:"merged"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Head struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+	} `json:"pull_request"`
+}
+
+func parseGitHub(event string, body []byte) (*Event, error) {
+	if event != "push" && event != "pull_request" && event != "ping" {
+		return nil, ErrUnsupportedEvent
+	}
+	var p githubPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	ev := &Event{Provider: ProviderGitHub, Repository: p.Repository.FullName}
+
+	switch event {
+	case "push":
+		ev.Branch = strings.TrimPrefix(p.Ref, "refs/heads/")
+		for _, c := range p.Commits {
+			author := c.Author.Username
+			if author == "" {
+				author = c.Author.Name
+			}
+			ev.Commits = append(ev.Commits, Commit{ID: c.ID, Message: c.Message, URL: c.URL, Author: author, Timestamp: c.Timestamp})
+		}
+	case "pull_request":
+		if p.PullRequest == nil {
+			return nil, errors.New("missing pull_request")
+		}
+		pr := p.PullRequest
+		state := pr.State
+		if pr.Merged {
+			state = "merged"
+		}
+		ev.Branch = pr.Head.Ref
+		ev.PullRequest = &PullRequest{
+			Number: pr.Number,
+			Title:  pr.Title,
+			Body:   pr.Body,
+			Branch: pr.Head.Ref,
+			URL:    pr.HTMLURL,
+			Author: pr.User.Login,
+			State:  state,
+		}
+	}
+	return ev, nil
+}
+
+type gitlabPayload struct {
+	Ref      string `json:"ref"`
+	UserName string `json:"user_username"`
+	Project  struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	Commits []struct {
+		ID        string    `json:"id"`
+		Message   string    `json:"message"`
+		URL       string    `json:"url"`
+		Timestamp time.Time `json:"timestamp"`
+		Author    struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commits"`
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		SourceBranch string `json:"source_branch"`
+		URL          string `json:"url"`
+		State        string `json:"state"` // opened, closed, merged, locked
+	} `json:"object_attributes"`
+}
+
+func parseGitLab(event string, body []byte) (*Event, error) {
+	if event != "Push Hook" && event != "Merge Request Hook" {
+		return nil, ErrUnsupportedEvent
+	}
+	var p gitlabPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	ev := &Event{Provider: ProviderGitLab, Repository: p.Project.PathWithNamespace}
+
+	if event == "Push Hook" {
+		ev.Branch = strings.TrimPrefix(p.Ref, "refs/heads/")
+		for _, c := range p.Commits {
+			ev.Commits = append(ev.Commits, Commit{ID: c.ID, Message: c.Message, URL: c.URL, Author: c.Author.Name, Timestamp: c.Timestamp})
+		}
+		return ev, nil
+	}
+
+	mr := p.ObjectAttributes
+	state := mr.State
+	switch state {
+	case "opened", "locked":
+		state = "open"
+	}
+	ev.Branch = mr.SourceBranch
+	ev.PullRequest = &PullRequest{
+		Number: mr.IID,
+		Title:  mr.Title,
+		Body:   mr.Description,
+		Branch: mr.SourceBranch,
+		URL:    mr.URL,
+		Author: p.User.Username,
+		State:  state,
+	}
+	return ev, nil
+}
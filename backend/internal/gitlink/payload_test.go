@@ -0,0 +1,188 @@
+package gitlink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func header(pairs ...string) http.Header {
+	h := http.Header{}
+	for i := 0; i < len(pairs); i += 2 {
+		h.Set(pairs[i], pairs[i+1])
+	}
+	return h
+}
+
+func TestDetectProvider(t *testing.T) {
+	tests := []struct {
+		header http.Header
+		want   string
+	}{
+		{header("X-GitHub-Event", "push"), ProviderGitHub},
+		{header("X-Gitlab-Event", "Push Hook"), ProviderGitLab},
+		{header("X-Other", "x"), ""},
+	}
+	for _, tt := range tests {
+		if got := DetectProvider(tt.header); got != tt.want {
+			t.Errorf("DetectProvider(%v) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name     string
+		provider string
+		header   http.Header
+		want     bool
+	}{
+		{"github valid", ProviderGitHub, header("X-Hub-Signature-256", signature), true},
+		{"github wrong signature", ProviderGitHub, header("X-Hub-Signature-256", "sha256=00"), false},
+		{"github missing", ProviderGitHub, header(), false},
+		{"gitlab valid", ProviderGitLab, header("X-Gitlab-Token", "s3cret"), true},
+		{"gitlab wrong", ProviderGitLab, header("X-Gitlab-Token", "nope"), false},
+		{"unknown provider", "bitbucket", header("X-Gitlab-Token", "s3cret"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.provider, tt.header, body, "s3cret"); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		header   http.Header
+		body     string
+		check    func(t *testing.T, ev *Event)
+		wantErr  error
+	}{
+		{
+			name:     "github push",
+			provider: ProviderGitHub,
+			header:   header("X-GitHub-Event", "push"),
+			body: `{"ref":"refs/heads/ws-1-login","repository":{"full_name":"acme/app"},"commits":[
+				{"id":"abc","message":"fix WS-1","url":"https://github.com/acme/app/commit/abc","timestamp":"2026-01-05T10:00:00Z","author":{"name":"Alice","username":"alice"}},
+				{"id":"def","message":"wip","timestamp":"2026-01-05T11:00:00Z","author":{"name":"Bob"}}]}`,
+			check: func(t *testing.T, ev *Event) {
+				if ev.Repository != "acme/app" || ev.Branch != "ws-1-login" || ev.PullRequest != nil {
+					t.Errorf("event = %+v", ev)
+				}
+				if len(ev.Commits) != 2 || ev.Commits[0].Author != "alice" || ev.Commits[1].Author != "Bob" {
+					t.Errorf("commits = %+v", ev.Commits)
+				}
+			},
+		},
+		{
+			name:     "github merged pull request",
+			provider: ProviderGitHub,
+			header:   header("X-GitHub-Event", "pull_request"),
+			body: `{"repository":{"full_name":"acme/app"},"pull_request":{"number":12,"title":"WS-2 export","body":"closes WS-2",
+				"html_url":"https://github.com/acme/app/pull/12","state":"closed","merged":true,"user":{"login":"alice"},"head":{"ref":"ws-2"}}}`,
+			check: func(t *testing.T, ev *Event) {
+				pr := ev.PullRequest
+				if pr == nil || pr.Number != 12 || pr.State != "merged" || pr.Branch != "ws-2" || pr.Author != "alice" || ev.Branch != "ws-2" {
+					t.Errorf("pull request = %+v", pr)
+				}
+			},
+		},
+		{
+			name:     "github ping",
+			provider: ProviderGitHub,
+			header:   header("X-GitHub-Event", "ping"),
+			body:     `{"zen":"hi","repository":{"full_name":"acme/app"}}`,
+			check: func(t *testing.T, ev *Event) {
+				if ev.PullRequest != nil || len(ev.Commits) != 0 {
+					t.Errorf("ping should carry nothing, got %+v", ev)
+				}
+			},
+		},
+		{
+			name:     "github pull request without payload",
+			provider: ProviderGitHub,
+			header:   header("X-GitHub-Event", "pull_request"),
+			body:     `{}`,
+			wantErr:  errors.New("missing pull_request"),
+		},
+		{
+			name:     "github unsupported event",
+			provider: ProviderGitHub,
+			header:   header("X-GitHub-Event", "issues"),
+			body:     `{}`,
+			wantErr:  ErrUnsupportedEvent,
+		},
+		{
+			name:     "gitlab push",
+			provider: ProviderGitLab,
+			header:   header("X-Gitlab-Event", "Push Hook"),
+			body: `{"ref":"refs/heads/main","project":{"path_with_namespace":"acme/app"},"commits":[
+				{"id":"abc","message":"WS-3 done","url":"https://gitlab.com/acme/app/-/commit/abc","timestamp":"2026-01-05T10:00:00+07:00","author":{"name":"Alice"}}]}`,
+			check: func(t *testing.T, ev *Event) {
+				if ev.Provider != ProviderGitLab || ev.Repository != "acme/app" || ev.Branch != "main" || len(ev.Commits) != 1 || ev.Commits[0].Author != "Alice" {
+					t.Errorf("event = %+v", ev)
+				}
+			},
+		},
+		{
+			name:     "gitlab opened merge request",
+			provider: ProviderGitLab,
+			header:   header("X-Gitlab-Event", "Merge Request Hook"),
+			body: `{"project":{"path_with_namespace":"acme/app"},"user":{"username":"bob"},"object_attributes":{"iid":4,"title":"WS-4",
+				"description":"fixes WS-4","source_branch":"ws-4","url":"https://gitlab.com/acme/app/-/merge_requests/4","state":"opened"}}`,
+			check: func(t *testing.T, ev *Event) {
+				pr := ev.PullRequest
+				if pr == nil || pr.Number != 4 || pr.State != "open" || pr.Author != "bob" || pr.Body != "fixes WS-4" {
+					t.Errorf("merge request = %+v", pr)
+				}
+			},
+		},
+		{
+			name:     "gitlab unsupported event",
+			provider: ProviderGitLab,
+			header:   header("X-Gitlab-Event", "Tag Push Hook"),
+			body:     `{}`,
+			wantErr:  ErrUnsupportedEvent,
+		},
+		{
+			name:     "unknown provider",
+			provider: "",
+			header:   header(),
+			body:     `{}`,
+			wantErr:  ErrUnsupportedEvent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := Parse(tt.provider, tt.header, []byte(tt.body))
+			if tt.wantErr != nil {
+				if err == nil || (errors.Is(tt.wantErr, ErrUnsupportedEvent) && !errors.Is(err, ErrUnsupportedEvent)) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			tt.check(t, ev)
+		})
+	}
+}
+
+func TestParseInvalidJSON(t *testing.T) {
+	if _, err := Parse(ProviderGitHub, header("X-GitHub-Event", "push"), []byte("{")); err == nil {
+		t.Error("Parse() of broken json should fail")
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"minitask/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// Export handler untuk download semua data user sebagai json
+func (h *AccountHandler) Export(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	export, err := h.accountService.Export(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	filename := fmt.Sprintf("minitask-account-%s.json", time.Now().Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.JSON(http.StatusOK, export)
+}
+
+// Delete handler untuk hapus akun, body: password + keputusan buat workspace yg dia punya.
+// Kalo masih ada workspace yg belum diputusin, balikin 409 + daftar workspace nya
+func (h *AccountHandler) Delete(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	var req service.DeleteAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	err := h.accountService.Delete(userID, &req)
+	var owned *service.OwnedWorkspacesError
+	if errors.As(err, &owned) {
+		return c.JSON(http.StatusConflict, map[string]interface{}{"error": err.Error(), "workspaces": owned.Workspaces})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
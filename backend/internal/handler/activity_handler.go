@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"minitask/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ActivityHandler struct {
+	activityService *service.ActivityService
+}
+
+func NewActivityHandler(activityService *service.ActivityService) *ActivityHandler {
+	return &ActivityHandler{activityService: activityService}
+}
+
+// ?page=1&limit=20, kalo kosong/invalid pakai default dari service
+func pageParams(c echo.Context) (int, int) {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	return page, limit
+}
+
+// GetMine handler untuk activity feed dari user yang login
+func (h *ActivityHandler) GetMine(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	page, limit := pageParams(c)
+
+	result, err := h.activityService.GetForUser(userID, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetForTask handler untuk activity feed satu task
+func (h *ActivityHandler) GetForTask(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+	page, limit := pageParams(c)
+
+	result, err := h.activityService.GetForTask(taskID, userID, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetForWorkspace handler untuk activity feed satu workspace
+func (h *ActivityHandler) GetForWorkspace(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	page, limit := pageParams(c)
+
+	result, err := h.activityService.GetForWorkspace(workspaceID, userID, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AttachmentHandler struct {
+	attachmentService *service.AttachmentService
+}
+
+func NewAttachmentHandler(attachmentService *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{attachmentService: attachmentService}
+}
+
+// GetForTask handler untuk list attachment di task
+func (h *AttachmentHandler) GetForTask(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+
+	attachments, err := h.attachmentService.GetForTask(taskID, userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, attachments)
+}
+
+// Upload handler untuk upload file ke task (multipart, field "file")
+func (h *AttachmentHandler) Upload(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+	}
+	if header.Size > service.MaxAttachmentSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": service.ErrAttachmentTooLarge.Error()})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file"})
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(taskID, userID, header.Filename, header.Header.Get("Content-Type"), file)
+	if err != nil {
+		if errors.Is(err, service.ErrTaskAccessDenied) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, attachment)
+}
+
+// Download handler untuk download file attachment
+func (h *AttachmentHandler) Download(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	attachment, path, err := h.attachmentService.Open(c.Param("id"), userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, attachment.ContentType)
+	c.Response().Header().Set("X-Content-Type-Options", "nosniff")
+	return c.Attachment(path, attachment.Filename)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"minitask/internal/service"
 	"net/http"
 
@@ -22,7 +23,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
 
-	response, err := h.authService.Register(&req)
+	response, err := h.authService.Register(&req, clientInfo(c))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -37,7 +38,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
 
-	response, err := h.authService.Login(&req)
+	response, err := h.authService.Login(&req, clientInfo(c))
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
 	}
@@ -45,6 +46,109 @@ func (h *AuthHandler) Login(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+// Refresh handler untuk tuker refresh token jadi access token baru (refresh token nya ikut diganti)
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req service.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	response, err := h.authService.Refresh(&req, clientInfo(c))
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Logout handler untuk revoke sesi dari refresh token nya
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req service.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := h.authService.Logout(&req); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ForgotPassword handler untuk kirim link reset password, selalu 202 biar gk bocorin email mana yg kedaftar
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	var req service.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := h.authService.ForgotPassword(&req, clientInfo(c)); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "If the email is registered, a reset link is on its way"})
+}
+
+// ResetPassword handler untuk set password baru dari link reset
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var req service.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := h.authService.ResetPassword(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ChangePassword handler untuk ganti password user yg lagi login
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	sessionID := c.Get("session_id").(string)
+
+	var req service.ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := h.authService.ChangePassword(userID, sessionID, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// VerifyEmail handler untuk konfirmasi email dari link verifikasi
+func (h *AuthHandler) VerifyEmail(c echo.Context) error {
+	var req service.VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	user, err := h.authService.VerifyEmail(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// ResendVerification handler untuk kirim ulang link verifikasi ke user yg lagi login
+func (h *AuthHandler) ResendVerification(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	if err := h.authService.ResendVerification(userID); err != nil {
+		if errors.Is(err, service.ErrVerificationThrottled) {
+			return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "Verification email sent"})
+}
+
 // GetProfile handler untuk mengambil data user yang sedang login
 func (h *AuthHandler) GetProfile(c echo.Context) error {
 	userID := c.Get("user_id").(string)
@@ -56,3 +160,7 @@ func (h *AuthHandler) GetProfile(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, user)
 }
+
+func clientInfo(c echo.Context) service.ClientInfo {
+	return service.ClientInfo{UserAgent: c.Request().UserAgent(), IP: c.RealIP()}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"minitask/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxBackupFile batas archive yg bisa di-restore, attachment nya ikut di dalem jadi lebih gede dari import biasa
+const maxBackupFile = 100 << 20
+
+type BackupHandler struct {
+	backupService *service.BackupService
+}
+
+func NewBackupHandler(backupService *service.BackupService) *BackupHandler {
+	return &BackupHandler{backupService: backupService}
+}
+
+// Backup handler untuk download backup workspace (zip), cuma owner
+func (h *BackupHandler) Backup(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	workspace, err := h.backupService.CheckBackup(workspaceID, userID)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	filename := fmt.Sprintf("%s-backup-%s.zip", workspace.KeyPrefix, time.Now().Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+	if err := h.backupService.Backup(workspace, c.Response()); err != nil {
+		// header udah kekirim, cuma bisa di-log
+		log.Printf("[BackupHandler] backup of workspace %s failed: %v", workspaceID, err)
+	}
+	return nil
+}
+
+// Restore handler untuk bikin workspace baru dari file backup.
+// Multipart: file, name (opsional, default nama workspace di backup)
+func (h *BackupHandler) Restore(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	limitBody(c, maxBackupFile)
+
+	header, err := c.FormFile("file")
+	if bodyTooLarge(err) {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "file is larger than 100MB"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+	}
+	if header.Size > maxBackupFile {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "file is larger than 100MB"})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file"})
+	}
+	defer file.Close()
+
+	result, err := h.backupService.Restore(userID, file, header.Size, c.FormValue("name"))
+	if errors.Is(err, service.ErrBackupInvalid) || errors.Is(err, service.ErrAttachmentTooLarge) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
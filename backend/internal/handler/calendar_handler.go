@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"minitask/internal/ical"
+	"minitask/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+}
+
+func NewCalendarHandler(calendarService *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+// Feed handler untuk ICS feed, NO JWT, token di url. ?type=todo buat VTODO (default VEVENT)
+func (h *CalendarHandler) Feed(c echo.Context) error {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	component := ical.ComponentEvent
+	if c.QueryParam("type") == "todo" {
+		component = ical.ComponentTodo
+	}
+
+	// di-render ke buffer dulu biar error nya masih bisa jadi status code yg bener
+	var buf bytes.Buffer
+	if err := h.calendarService.Render(token, component, &buf); err != nil {
+		if errors.Is(err, service.ErrFeedNotFound) {
+			return c.String(http.StatusNotFound, "calendar feed not found")
+		}
+		log.Printf("[CalendarHandler] failed to render feed: %v", err)
+		return c.String(http.StatusInternalServerError, "failed to render calendar")
+	}
+
+	c.Response().Header().Set("Cache-Control", "private, max-age=300")
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// GetFeeds handler untuk list calendar feed milik user
+func (h *CalendarHandler) GetFeeds(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	feeds, err := h.calendarService.GetFeeds(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, feeds)
+}
+
+// CreateFeed handler untuk bikin feed personal / workspace, token cuma dikasih sekali
+func (h *CalendarHandler) CreateFeed(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	var req service.CreateCalendarFeedRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	feed, err := h.calendarService.CreateFeed(userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, feed)
+}
+
+// UpdateFeed handler untuk ganti timezone feed
+func (h *CalendarHandler) UpdateFeed(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	var req service.UpdateCalendarFeedRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	feed, err := h.calendarService.UpdateFeed(c.Param("id"), userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, feed)
+}
+
+// RegenerateToken handler untuk ganti url feed, url lama langsung mati
+func (h *CalendarHandler) RegenerateToken(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	feed, err := h.calendarService.RegenerateToken(c.Param("id"), userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, feed)
+}
+
+// DeleteFeed handler untuk hapus feed
+func (h *CalendarHandler) DeleteFeed(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	if err := h.calendarService.DeleteFeed(c.Param("id"), userID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "calendar feed deleted"})
+}
@@ -34,11 +34,12 @@ func (h *CommentHandler) Create(c echo.Context) error {
 
 // GetByTaskID handler untuk mengambil semua comment dari suatu task
 func (h *CommentHandler) GetByTaskID(c echo.Context) error {
+	userID := c.Get("user_id").(string)
 	taskID := c.Param("taskId")
 
-	comments, err := h.commentService.GetAllByTaskID(taskID)
+	comments, err := h.commentService.GetAllByTaskID(taskID, userID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, comments)
@@ -46,9 +47,10 @@ func (h *CommentHandler) GetByTaskID(c echo.Context) error {
 
 // GetByID handler untuk mengambil comment berdasarkan ID
 func (h *CommentHandler) GetByID(c echo.Context) error {
+	userID := c.Get("user_id").(string)
 	commentID := c.Param("id")
 
-	comment, err := h.commentService.GetByID(commentID)
+	comment, err := h.commentService.GetByID(commentID, userID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 	}
@@ -74,7 +76,20 @@ func (h *CommentHandler) Update(c echo.Context) error {
 	return c.JSON(http.StatusOK, comment)
 }
 
-// Delete handler untuk menghapus comment (cmn pemilik yang bisa hapus)
+// GetRevisions handler untuk lihat riwayat edit comment
+func (h *CommentHandler) GetRevisions(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	commentID := c.Param("id")
+
+	revisions, err := h.commentService.GetRevisions(commentID, userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, revisions)
+}
+
+// Delete handler untuk menghapus comment (pemilik comment atau owner workspace)
 func (h *CommentHandler) Delete(c echo.Context) error {
 	userID := c.Get("user_id").(string)
 	commentID := c.Param("id")
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"minitask/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// ExportPersonal handler untuk export personal task, ?format=csv|json (default csv)
+func (h *ExportHandler) ExportPersonal(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	format := exportFormat(c)
+	if format != service.ExportCSV && format != service.ExportJSON {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": service.ErrExportFormat.Error()})
+	}
+
+	startExport(c, "tasks", format)
+	if err := h.exportService.ExportPersonal(userID, format, c.Response()); err != nil {
+		// header udah kekirim, cuma bisa di-log
+		log.Printf("[ExportHandler] personal export for %s failed: %v", userID, err)
+	}
+	return nil
+}
+
+// ExportWorkspace handler untuk export task workspace, semua member boleh
+func (h *ExportHandler) ExportWorkspace(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	format := exportFormat(c)
+
+	if err := h.exportService.CheckWorkspaceExport(workspaceID, userID, format); err != nil {
+		if errors.Is(err, service.ErrExportFormat) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	startExport(c, "workspace-tasks", format)
+	if err := h.exportService.ExportWorkspace(workspaceID, format, c.Response()); err != nil {
+		log.Printf("[ExportHandler] workspace export for %s failed: %v", workspaceID, err)
+	}
+	return nil
+}
+
+func exportFormat(c echo.Context) string {
+	if format := c.QueryParam("format"); format != "" {
+		return format
+	}
+	return service.ExportCSV
+}
+
+func startExport(c echo.Context, name, format string) {
+	contentType := "text/csv; charset=utf-8"
+	if format == service.ExportJSON {
+		contentType = echo.MIMEApplicationJSONCharsetUTF8
+	}
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"io"
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxGitPayload batas body push/PR webhook, push dengan banyak commit bisa lumayan gede
+const maxGitPayload = 5 << 20
+
+type GitHandler struct {
+	gitService *service.GitService
+}
+
+func NewGitHandler(gitService *service.GitService) *GitHandler {
+	return &GitHandler{gitService: gitService}
+}
+
+// Receive handler untuk webhook GitHub/GitLab, NO JWT, auth nya lewat signature/secret token
+func (h *GitHandler) Receive(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxGitPayload+1))
+	if err != nil || len(body) > maxGitPayload {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "payload too large"})
+	}
+
+	result, err := h.gitService.HandleWebhook(c.Param("workspaceId"), c.Request().Header, body)
+	if err != nil {
+		if err.Error() == "invalid signature" {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// Get handler untuk liat setting git integration workspace (owner only)
+func (h *GitHandler) Get(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	integration, err := h.gitService.Get(workspaceID, userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, integration)
+}
+
+// Enable handler untuk nyalain git integration, secret cuma dikasih sekali
+func (h *GitHandler) Enable(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	var req service.EnableGitIntegrationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	integration, err := h.gitService.Enable(workspaceID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, integration)
+}
+
+// Update handler untuk ganti setting auto close on merge
+func (h *GitHandler) Update(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	var req service.UpdateGitIntegrationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	integration, err := h.gitService.Update(workspaceID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, integration)
+}
+
+// RotateSecret handler untuk ganti secret webhook
+func (h *GitHandler) RotateSecret(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	integration, err := h.gitService.RotateSecret(workspaceID, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, integration)
+}
+
+// Disable handler untuk matiin git integration
+func (h *GitHandler) Disable(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	if err := h.gitService.Disable(workspaceID, userID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "git integration disabled"})
+}
+
+// GetTaskLinks handler untuk list commit & PR yang nyebut task ini
+func (h *GitHandler) GetTaskLinks(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+
+	links, err := h.gitService.GetLinksForTask(taskID, userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, links)
+}
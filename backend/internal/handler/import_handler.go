@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"minitask/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxImportFile batas file csv, 1000 baris jarang lebih dari ini
+const maxImportFile = 5 << 20
+
+// maxBoardFile batas export Trello/Todoist, JSON Trello isinya semua actions jadi lebih gede
+const maxBoardFile = 20 << 20
+
+// multipartOverhead nambahin batas body buat boundary sama field form lain selain file nya
+const multipartOverhead = 1 << 20
+
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// Preview handler untuk step mapping: header, 5 baris pertama, sama tebakan mapping nya
+func (h *ImportHandler) Preview(c echo.Context) error {
+	limitBody(c, maxImportFile)
+	file, err := openImportFile(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	defer file.Close()
+
+	preview, err := h.importService.Preview(file)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+// ImportPersonal handler untuk import csv ke personal task.
+// Multipart: file, mapping (json {"title":"Header",...}), dryRun=true buat validasi doang
+func (h *ImportHandler) ImportPersonal(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	file, mapping, dryRun, err := importParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	defer file.Close()
+
+	result, err := h.importService.ImportPersonal(userID, file, mapping, dryRun)
+	return importResponse(c, result, err)
+}
+
+// ImportWorkspace handler untuk import csv ke workspace
+func (h *ImportHandler) ImportWorkspace(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	file, mapping, dryRun, err := importParams(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	defer file.Close()
+
+	result, err := h.importService.ImportWorkspace(workspaceID, userID, file, mapping, dryRun)
+	return importResponse(c, result, err)
+}
+
+// ImportBoard handler untuk import Trello board / Todoist backup jadi workspace baru.
+// Multipart: source (trello|todoist), file, options (json, opsional)
+func (h *ImportHandler) ImportBoard(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	limitBody(c, maxBoardFile)
+
+	var opts service.BoardImportOptions
+	if raw := c.FormValue("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid options"})
+		}
+	}
+	header, err := c.FormFile("file")
+	if bodyTooLarge(err) {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "file is larger than 20MB"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file is required"})
+	}
+	if header.Size > maxBoardFile {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "file is larger than 20MB"})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file"})
+	}
+	defer file.Close()
+
+	result, err := h.importService.ImportBoard(userID, c.FormValue("source"), header.Filename, file, &opts)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+func openImportFile(c echo.Context) (io.ReadCloser, error) {
+	header, err := c.FormFile("file")
+	if bodyTooLarge(err) {
+		return nil, errors.New("file is larger than 5MB")
+	}
+	if err != nil {
+		return nil, errors.New("file is required")
+	}
+	if header.Size > maxImportFile {
+		return nil, errors.New("file is larger than 5MB")
+	}
+	return header.Open()
+}
+
+func importParams(c echo.Context) (io.ReadCloser, service.ImportMapping, bool, error) {
+	limitBody(c, maxImportFile)
+	var mapping service.ImportMapping
+	if err := json.Unmarshal([]byte(c.FormValue("mapping")), &mapping); err != nil {
+		return nil, nil, false, errors.New("mapping must be a JSON object of field to column")
+	}
+	dryRun, _ := strconv.ParseBool(c.FormValue("dryRun"))
+
+	file, err := openImportFile(c)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return file, mapping, dryRun, nil
+}
+
+func importResponse(c echo.Context, result *service.ImportResult, err error) error {
+	if errors.Is(err, service.ErrImportInvalid) {
+		return c.JSON(http.StatusUnprocessableEntity, result)
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if result.DryRun {
+		return c.JSON(http.StatusOK, result)
+	}
+	return c.JSON(http.StatusCreated, result)
+}
+
+// limitBody caps the request body before the multipart form is parsed, kalo gk file segede apapun
+// udah keburu ditulis ke temp file sebelum size nya dicek
+func limitBody(c echo.Context, maxFile int64) {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxFile+multipartOverhead)
+}
+
+func bodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
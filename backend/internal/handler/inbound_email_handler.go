@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxInboundEmail batas raw message, 25MB kayak limit attachment kebanyakan mail server
+const maxInboundEmail = 25 << 20
+
+type InboundEmailHandler struct {
+	inboundEmailService *service.InboundEmailService
+}
+
+func NewInboundEmailHandler(inboundEmailService *service.InboundEmailService) *InboundEmailHandler {
+	return &InboundEmailHandler{inboundEmailService: inboundEmailService}
+}
+
+// Receive handler untuk raw email (message/rfc822) dari mail server / forwarding service.
+// NO JWT, auth nya lewat header X-Inbound-Secret
+func (h *InboundEmailHandler) Receive(c echo.Context) error {
+	if !h.inboundEmailService.VerifyEndpoint(c.Request().Header.Get("X-Inbound-Secret")) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid secret"})
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(c.Request().Body, maxInboundEmail+1))
+	if err != nil || len(raw) > maxInboundEmail {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "message too large"})
+	}
+
+	record, err := h.inboundEmailService.Process(raw)
+	if err != nil {
+		// rejected = 422 biar forwarder gk retry, error lain 500 biar di-retry
+		if errors.Is(err, service.ErrInboundRejected) {
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to process message"})
+	}
+	if record == nil {
+		return c.JSON(http.StatusOK, map[string]string{"message": "already processed"})
+	}
+
+	return c.JSON(http.StatusCreated, record)
+}
+
+// Get handler untuk liat inbound address workspace (owner only)
+func (h *InboundEmailHandler) Get(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	mailbox, err := h.inboundEmailService.Get(workspaceID, userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, mailbox)
+}
+
+// Enable handler untuk bikin inbound address
+func (h *InboundEmailHandler) Enable(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	mailbox, err := h.inboundEmailService.Enable(workspaceID, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, mailbox)
+}
+
+// RotateAddress handler untuk ganti inbound address, address lama langsung gk berlaku
+func (h *InboundEmailHandler) RotateAddress(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	mailbox, err := h.inboundEmailService.RotateAddress(workspaceID, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, mailbox)
+}
+
+// Disable handler untuk matiin email-to-task
+func (h *InboundEmailHandler) Disable(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	if err := h.inboundEmailService.Disable(workspaceID, userID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "inbound email disabled"})
+}
+
+// GetEmails handler untuk log email yg masuk ke workspace
+func (h *InboundEmailHandler) GetEmails(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	page, limit := pageParams(c)
+
+	result, err := h.inboundEmailService.GetEmails(workspaceID, userID, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
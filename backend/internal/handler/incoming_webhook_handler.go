@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxIncomingPayload batas body incoming webhook, 64KB udah lebih dari cukup buat satu task
+const maxIncomingPayload = 64 << 10
+
+type IncomingWebhookHandler struct {
+	incomingWebhookService *service.IncomingWebhookService
+}
+
+func NewIncomingWebhookHandler(incomingWebhookService *service.IncomingWebhookService) *IncomingWebhookHandler {
+	return &IncomingWebhookHandler{incomingWebhookService: incomingWebhookService}
+}
+
+// Receive handler untuk incoming webhook, NO JWT, auth nya lewat token di url
+func (h *IncomingWebhookHandler) Receive(c echo.Context) error {
+	webhook, err := h.incomingWebhookService.Authenticate(c.Param("token"))
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(c.Request().Body, maxIncomingPayload+1))
+	if err != nil || len(raw) > maxIncomingPayload {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "payload too large"})
+	}
+	var payload service.IncomingTaskPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON payload"})
+	}
+
+	task, err := h.incomingWebhookService.CreateTask(webhook, &payload, raw, c.RealIP())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, task)
+}
+
+// GetAll handler untuk list incoming webhook di workspace (owner only)
+func (h *IncomingWebhookHandler) GetAll(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	webhooks, err := h.incomingWebhookService.GetAll(workspaceID, userID)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+// Create handler untuk bikin incoming webhook + bot user nya, token cuma dikasih sekali
+func (h *IncomingWebhookHandler) Create(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	var req service.CreateIncomingWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	webhook, err := h.incomingWebhookService.Create(workspaceID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+// RotateToken handler untuk ganti token, url lama langsung gk berlaku
+func (h *IncomingWebhookHandler) RotateToken(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	webhookID := c.Param("webhookId")
+
+	webhook, err := h.incomingWebhookService.RotateToken(workspaceID, webhookID, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// Delete handler untuk hapus incoming webhook
+func (h *IncomingWebhookHandler) Delete(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	webhookID := c.Param("webhookId")
+
+	if err := h.incomingWebhookService.Delete(workspaceID, webhookID, userID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "incoming webhook deleted"})
+}
+
+// GetTasks handler untuk audit task yg dibikin satu integrasi
+func (h *IncomingWebhookHandler) GetTasks(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	webhookID := c.Param("webhookId")
+	page, limit := pageParams(c)
+
+	result, err := h.incomingWebhookService.GetTasks(workspaceID, webhookID, userID, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MentionHandler struct {
+	mentionService *service.MentionService
+}
+
+func NewMentionHandler(mentionService *service.MentionService) *MentionHandler {
+	return &MentionHandler{mentionService: mentionService}
+}
+
+// GetMine handler untuk feed "mentions of me"
+func (h *MentionHandler) GetMine(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	mentions, err := h.mentionService.GetMentionsForUser(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, mentions)
+}
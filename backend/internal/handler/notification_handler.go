@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// GetAll handler untuk inbox notifikasi (?unread=true buat yg belum dibaca aja)
+func (h *NotificationHandler) GetAll(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	page, limit := pageParams(c)
+	unreadOnly := c.QueryParam("unread") == "true"
+
+	result, err := h.notificationService.List(userID, unreadOnly, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// UnreadCount handler untuk badge jumlah notifikasi belum dibaca
+func (h *NotificationHandler) UnreadCount(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	count, err := h.notificationService.UnreadCount(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"unread": count})
+}
+
+func (h *NotificationHandler) MarkRead(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	if err := h.notificationService.MarkRead(c.Param("id"), userID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "notification marked as read"})
+}
+
+func (h *NotificationHandler) MarkUnread(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	if err := h.notificationService.MarkUnread(c.Param("id"), userID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "notification marked as unread"})
+}
+
+func (h *NotificationHandler) MarkAllRead(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	if err := h.notificationService.MarkAllRead(userID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "all notifications marked as read"})
+}
+
+func (h *NotificationHandler) GetPreferences(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	prefs, err := h.notificationService.GetPreferences(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, prefs)
+}
+
+func (h *NotificationHandler) UpdatePreferences(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	var req service.UpdateNotificationPreferencesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, prefs)
+}
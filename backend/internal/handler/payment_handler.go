@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"minitask/internal/service"
 	"net/http"
 
@@ -28,6 +29,9 @@ func (h *PaymentHandler) CreateCheckout(c echo.Context) error {
 	}
 
 	result, err := h.paymentService.CreateSnapToken(userID, req.Plan)
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReactionHandler struct {
+	reactionService *service.ReactionService
+}
+
+func NewReactionHandler(reactionService *service.ReactionService) *ReactionHandler {
+	return &ReactionHandler{reactionService: reactionService}
+}
+
+// ToggleOnComment handler untuk tambah/hapus reaction emoji di comment
+func (h *ReactionHandler) ToggleOnComment(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	commentID := c.Param("id")
+
+	var req service.ToggleReactionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	reactions, err := h.reactionService.ToggleOnComment(commentID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, reactions)
+}
+
+// ToggleOnTask handler untuk tambah/hapus reaction emoji di task
+func (h *ReactionHandler) ToggleOnTask(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+
+	var req service.ToggleReactionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	reactions, err := h.reactionService.ToggleOnTask(taskID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, reactions)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"minitask/internal/middleware"
+	"minitask/internal/realtime"
+	"minitask/internal/service"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const heartbeatInterval = 25 * time.Second
+
+type RealtimeHandler struct {
+	hub                 *realtime.Hub
+	workspaceService    *service.WorkspaceService
+	streamTicketService *service.StreamTicketService
+}
+
+func NewRealtimeHandler(hub *realtime.Hub, workspaceService *service.WorkspaceService, streamTicketService *service.StreamTicketService) *RealtimeHandler {
+	return &RealtimeHandler{hub: hub, workspaceService: workspaceService, streamTicketService: streamTicketService}
+}
+
+// CreateTicket handler untuk minta ticket sekali pakai sebelum buka EventSource ke /events?ticket=
+func (h *RealtimeHandler) CreateTicket(c echo.Context) error {
+	claims, _ := c.Get("claims").(*middleware.JWTClaims)
+
+	ticket, err := h.streamTicketService.Issue(claims)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, ticket)
+}
+
+// Stream handler untuk Server-Sent Events board workspace.
+// ?workspaceId=a,b buat workspace tertentu, kalo kosong ikut semua workspace user
+func (h *RealtimeHandler) Stream(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	claims, _ := c.Get("claims").(*middleware.JWTClaims)
+
+	var workspaceIDs []string
+	followMemberships := false
+	if param := c.QueryParam("workspaceId"); param != "" {
+		for _, id := range strings.Split(param, ",") {
+			if _, err := h.workspaceService.GetByID(id, userID); err != nil {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+			}
+			workspaceIDs = append(workspaceIDs, id)
+		}
+	} else {
+		workspaces, err := h.workspaceService.GetAllForUser(userID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		for _, workspace := range workspaces {
+			workspaceIDs = append(workspaceIDs, workspace.ID)
+		}
+		followMemberships = true
+	}
+
+	client := h.hub.Register(userID, workspaceIDs, followMemberships)
+	defer h.hub.Unregister(client)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprint(res, "retry: 3000\n\n")
+	res.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			// access token expired / sesi di-sign out / akun dihapus: stream nya ditutup juga
+			if claims != nil {
+				if err := middleware.Revalidate(claims); err != nil {
+					fmt.Fprint(res, "event: unauthorized\ndata: {}\n\n")
+					res.Flush()
+					return nil
+				}
+			}
+			// comment line biar proxy gk nutup koneksi idle
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case event := <-client.Events:
+			payload, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
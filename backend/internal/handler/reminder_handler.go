@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReminderHandler struct {
+	reminderService *service.ReminderService
+}
+
+func NewReminderHandler(reminderService *service.ReminderService) *ReminderHandler {
+	return &ReminderHandler{reminderService: reminderService}
+}
+
+// GetSettings handler untuk ambil reminder window user
+func (h *ReminderHandler) GetSettings(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	settings, err := h.reminderService.GetSettings(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings handler untuk ganti reminder window user
+func (h *ReminderHandler) UpdateSettings(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+
+	var req service.ReminderSettings
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	settings, err := h.reminderService.UpdateSettings(userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// GetForTask handler untuk list reminder user di satu task
+func (h *ReminderHandler) GetForTask(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+
+	reminders, err := h.reminderService.GetForTask(taskID, userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, reminders)
+}
+
+// Create handler untuk bikin reminder di task
+func (h *ReminderHandler) Create(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+
+	var req service.CreateReminderRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	reminder, err := h.reminderService.Create(taskID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, reminder)
+}
+
+// Delete handler untuk hapus reminder
+func (h *ReminderHandler) Delete(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	reminderID := c.Param("id")
+
+	if err := h.reminderService.Delete(reminderID, userID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "reminder deleted"})
+}
+
+// Snooze handler untuk nunda reminder
+func (h *ReminderHandler) Snooze(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	reminderID := c.Param("id")
+
+	var req service.SnoozeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	reminder, err := h.reminderService.Snooze(reminderID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, reminder)
+}
+
+// SnoozeNotification handler untuk nunda notif task, nanti muncul lagi sebagai reminder
+func (h *ReminderHandler) SnoozeNotification(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	notificationID := c.Param("id")
+
+	var req service.SnoozeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	reminder, err := h.reminderService.SnoozeNotification(notificationID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, reminder)
+}
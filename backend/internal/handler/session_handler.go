@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SessionHandler struct {
+	sessionService *service.SessionService
+}
+
+func NewSessionHandler(sessionService *service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// GetAll handler untuk list device yg lagi login
+func (h *SessionHandler) GetAll(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	sessionID := c.Get("session_id").(string)
+
+	sessions, err := h.sessionService.GetAll(userID, sessionID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// Revoke handler untuk sign out satu device
+func (h *SessionHandler) Revoke(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	id := c.Param("id")
+
+	if err := h.sessionService.Revoke(id, userID); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RevokeOthers handler untuk sign out di semua device lain
+func (h *SessionHandler) RevokeOthers(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	sessionID := c.Get("session_id").(string)
+
+	if err := h.sessionService.RevokeOthers(userID, sessionID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
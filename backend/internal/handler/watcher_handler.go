@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WatcherHandler struct {
+	watcherService *service.WatcherService
+}
+
+func NewWatcherHandler(watcherService *service.WatcherService) *WatcherHandler {
+	return &WatcherHandler{watcherService: watcherService}
+}
+
+// GetByTaskID handler untuk list siapa aja yg watch task
+func (h *WatcherHandler) GetByTaskID(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+
+	watchers, err := h.watcherService.GetWatchers(taskID, userID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, watchers)
+}
+
+// Watch handler untuk mulai watch task
+func (h *WatcherHandler) Watch(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+
+	watchers, err := h.watcherService.Watch(taskID, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, watchers)
+}
+
+// Unwatch handler untuk berhenti watch task
+func (h *WatcherHandler) Unwatch(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	taskID := c.Param("id")
+
+	watchers, err := h.watcherService.Unwatch(taskID, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, watchers)
+}
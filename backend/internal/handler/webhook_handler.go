@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"minitask/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// GetAll handler untuk list outgoing webhook di workspace (owner only)
+func (h *WebhookHandler) GetAll(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	webhooks, err := h.webhookService.GetAll(workspaceID, userID)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+// Create handler untuk daftarin webhook baru, secret cuma dikasih sekali di sini
+func (h *WebhookHandler) Create(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	var req service.CreateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	webhook, err := h.webhookService.Create(workspaceID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+// Update handler untuk ganti url / event filter / aktif-nonaktif webhook
+func (h *WebhookHandler) Update(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	webhookID := c.Param("webhookId")
+
+	var req service.UpdateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	webhook, err := h.webhookService.Update(workspaceID, webhookID, userID, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// Delete handler untuk hapus webhook beserta delivery log nya
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	webhookID := c.Param("webhookId")
+
+	if err := h.webhookService.Delete(workspaceID, webhookID, userID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "webhook deleted"})
+}
+
+// GetDeliveries handler untuk delivery log satu webhook
+func (h *WebhookHandler) GetDeliveries(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	webhookID := c.Param("webhookId")
+	page, limit := pageParams(c)
+
+	result, err := h.webhookService.GetDeliveries(workspaceID, webhookID, userID, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// Redeliver handler untuk kirim ulang delivery lama secara manual
+func (h *WebhookHandler) Redeliver(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+	webhookID := c.Param("webhookId")
+	deliveryID := c.Param("deliveryId")
+
+	delivery, err := h.webhookService.Redeliver(workspaceID, webhookID, deliveryID, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, delivery)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"minitask/internal/service"
 	"net/http"
@@ -104,6 +105,9 @@ func (h *WorkspaceHandler) Join(c echo.Context) error {
 	}
 
 	workspace, err := h.workspaceService.JoinByInviteCode(&req, userID)
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -125,6 +129,26 @@ func (h *WorkspaceHandler) RemoveMember(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"message": "member removed"})
 }
 
+// InviteByEmail handler untuk kirim invite code lewat email (owner only)
+func (h *WorkspaceHandler) InviteByEmail(c echo.Context) error {
+	ownerID := c.Get("user_id").(string)
+	workspaceID := c.Param("id")
+
+	var req service.InviteByEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if err := h.workspaceService.InviteByEmail(workspaceID, ownerID, &req); err != nil {
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "invite sent"})
+}
+
 // RefreshInviteCode handler untuk generate invite code baru (owner only)
 func (h *WorkspaceHandler) RefreshInviteCode(c echo.Context) error {
 	ownerID := c.Get("user_id").(string)
@@ -206,11 +230,6 @@ func (h *WorkspaceHandler) UpdateTask(c echo.Context) error {
 	workspaceID := c.Param("id")
 	taskID := c.Param("taskId")
 
-	// Debug logging
-	println("DEBUG UpdateTask - UserID:", userID)
-	println("DEBUG UpdateTask - WorkspaceID:", workspaceID)
-	println("DEBUG UpdateTask - TaskID:", taskID)
-
 	var req service.UpdateWorkspaceTaskRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid Request"})
@@ -218,7 +237,6 @@ func (h *WorkspaceHandler) UpdateTask(c echo.Context) error {
 
 	task, err := h.workspaceService.UpdateTask(workspaceID, taskID, userID, &req)
 	if err != nil {
-		println("DEBUG UpdateTask - Error:", err.Error())
 		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
 	}
 
@@ -0,0 +1,161 @@
+// Package ical writes RFC 5545 calendars, cuma bagian yg dipake feed task (VEVENT & VTODO)
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	_ "time/tzdata" // LoadLocation tetep jalan di container tanpa zoneinfo
+)
+
+const (
+	ComponentEvent = "VEVENT"
+	ComponentTodo  = "VTODO"
+)
+
+// Entry is one task on the calendar
+type Entry struct {
+	UID          string
+	Summary      string
+	Description  string
+	URL          string
+	Categories   []string
+	Due          time.Time
+	AllDay       bool
+	Status       string // VTODO: NEEDS-ACTION, IN-PROCESS, COMPLETED
+	LastModified time.Time
+}
+
+type Calendar struct {
+	Name     string
+	Location *time.Location
+	Entries  []Entry
+}
+
+// Write renders the calendar. Jam di-render UTC ("Z") biar semua app ngitung zona nya sendiri,
+// kecuali all-day yg pake tanggal di Location calendar nya
+func (c *Calendar) Write(w io.Writer, component string) error {
+	out := &writer{w: bufio.NewWriter(w)}
+	now := time.Now().UTC()
+
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:-//MiniTask//Task Feed//EN")
+	out.line("CALSCALE:GREGORIAN")
+	out.line("METHOD:PUBLISH")
+	out.prop("X-WR-CALNAME", escape(c.Name))
+	out.prop("X-WR-TIMEZONE", c.Location.String())
+	out.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	out.line("X-PUBLISHED-TTL:PT1H")
+
+	for _, entry := range c.Entries {
+		out.line("BEGIN:" + component)
+		out.prop("UID", escape(entry.UID))
+		out.prop("DTSTAMP", formatUTC(now))
+		if !entry.LastModified.IsZero() {
+			out.prop("LAST-MODIFIED", formatUTC(entry.LastModified))
+		}
+		out.prop("SUMMARY", escape(entry.Summary))
+		if entry.Description != "" {
+			out.prop("DESCRIPTION", escape(entry.Description))
+		}
+		if entry.URL != "" {
+			out.prop("URL;VALUE=URI", entry.URL)
+		}
+		if len(entry.Categories) > 0 {
+			escaped := make([]string, len(entry.Categories))
+			for i, category := range entry.Categories {
+				escaped[i] = escape(category)
+			}
+			out.prop("CATEGORIES", strings.Join(escaped, ","))
+		}
+
+		local := entry.Due.In(c.Location)
+		switch {
+		case component == ComponentTodo && entry.AllDay:
+			out.prop("DUE;VALUE=DATE", local.Format("20060102"))
+		case component == ComponentTodo:
+			out.prop("DUE", formatUTC(entry.Due))
+		case entry.AllDay:
+			out.prop("DTSTART;VALUE=DATE", local.Format("20060102"))
+			out.prop("DTEND;VALUE=DATE", local.AddDate(0, 0, 1).Format("20060102"))
+		default:
+			// deadline = titik waktu, dikasih 30 menit biar keliatan di kalender
+			out.prop("DTSTART", formatUTC(entry.Due))
+			out.prop("DTEND", formatUTC(entry.Due.Add(30*time.Minute)))
+		}
+		if component == ComponentTodo && entry.Status != "" {
+			out.prop("STATUS", entry.Status)
+			if entry.Status == "COMPLETED" && !entry.LastModified.IsZero() {
+				out.prop("COMPLETED", formatUTC(entry.LastModified))
+			}
+		}
+		if component == ComponentEvent {
+			out.prop("TRANSP", "TRANSPARENT")
+		}
+		out.line("END:" + component)
+	}
+
+	out.line("END:VCALENDAR")
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape per RFC 5545 3.3.11: backslash, ; , and newlines
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(value)
+}
+
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (w *writer) prop(name, value string) {
+	w.line(name + ":" + value)
+}
+
+// line folds content lines at 75 octets without splitting a UTF-8 character, baris pakai CRLF
+func (w *writer) line(s string) {
+	if w.err != nil {
+		return
+	}
+	first := true
+	for len(s) > 0 {
+		limit := 75
+		if !first {
+			limit = 74 // spasi di awal baris lanjutan ikut dihitung
+		}
+		cut := len(s)
+		if cut > limit {
+			cut = limit
+			for cut > 0 && !utf8Start(s[cut]) {
+				cut--
+			}
+		}
+		if !first {
+			w.write(" ")
+		}
+		w.write(s[:cut] + "\r\n")
+		s = s[cut:]
+		first = false
+	}
+}
+
+func (w *writer) write(s string) {
+	if w.err == nil {
+		_, w.err = w.w.WriteString(s)
+	}
+}
+
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,152 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{`back\slash`, `back\\slash`},
+		{"a;b,c", `a\;b\,c`},
+		{"line1\r\nline2\nline3", `line1\nline2\nline3`},
+		{"stray\rcr", "straycr"},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"short", "SUMMARY:hello"},
+		{"exactly 75", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"ascii", "SUMMARY:" + strings.Repeat("abcdefghij", 20)},
+		{"multibyte", "SUMMARY:" + strings.Repeat("tugas ✓ 日本語 ", 15)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &writer{w: bufio.NewWriter(&buf)}
+			w.line(tt.value)
+			w.w.Flush()
+
+			out := buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output doesn't end with CRLF: %q", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d doesn't start with a space", i)
+				}
+			}
+			unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", "")
+			if unfolded != tt.value {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.value)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC) // 2 Mar 00:00 di Jakarta
+	modified := time.Date(2026, 2, 20, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		component string
+		entry     Entry
+		want      []string
+		missing   []string
+	}{
+		{
+			name:      "timed event",
+			component: ComponentEvent,
+			entry:     Entry{UID: "t1@minitask", Summary: "Ship, v2", Due: due, URL: "https://app.test/t/1", Categories: []string{"bug", "a;b"}},
+			want: []string{
+				"BEGIN:VEVENT", "UID:t1@minitask", `SUMMARY:Ship\, v2`, "DTSTART:20260301T170000Z", "DTEND:20260301T173000Z",
+				"URL;VALUE=URI:https://app.test/t/1", `CATEGORIES:bug,a\;b`, "TRANSP:TRANSPARENT", "END:VEVENT",
+			},
+			missing: []string{"DESCRIPTION", "STATUS", "LAST-MODIFIED"},
+		},
+		{
+			name:      "all-day event uses the calendar timezone",
+			component: ComponentEvent,
+			entry:     Entry{UID: "t2", Summary: "All day", Due: due, AllDay: true},
+			want:      []string{"DTSTART;VALUE=DATE:20260302", "DTEND;VALUE=DATE:20260303"},
+		},
+		{
+			name:      "open todo",
+			component: ComponentTodo,
+			entry:     Entry{UID: "t3", Summary: "Todo", Description: "line1\nline2", Due: due, Status: "NEEDS-ACTION"},
+			want:      []string{"BEGIN:VTODO", "DUE:20260301T170000Z", `DESCRIPTION:line1\nline2`, "STATUS:NEEDS-ACTION", "END:VTODO"},
+			missing:   []string{"COMPLETED:", "TRANSP"},
+		},
+		{
+			name:      "completed all-day todo",
+			component: ComponentTodo,
+			entry:     Entry{UID: "t4", Summary: "Done", Due: due, AllDay: true, Status: "COMPLETED", LastModified: modified},
+			want:      []string{"DUE;VALUE=DATE:20260302", "STATUS:COMPLETED", "COMPLETED:20260220T080000Z", "LAST-MODIFIED:20260220T080000Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal := &Calendar{Name: "MiniTask", Location: jakarta, Entries: []Entry{tt.entry}}
+			var buf bytes.Buffer
+			if err := cal.Write(&buf, tt.component); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			out := buf.String()
+			if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+				t.Errorf("calendar isn't wrapped in VCALENDAR:\n%s", out)
+			}
+			if !strings.Contains(out, "X-WR-TIMEZONE:Asia/Jakarta\r\n") {
+				t.Error("X-WR-TIMEZONE missing")
+			}
+			lines := strings.Split(out, "\r\n")
+			for _, want := range tt.want {
+				if !contains(lines, want) {
+					t.Errorf("missing line %q in:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range tt.missing {
+				if strings.Contains(out, "\r\n"+unwanted) {
+					t.Errorf("unexpected %q in:\n%s", unwanted, out)
+				}
+			}
+		})
+	}
+}
+
+func contains(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
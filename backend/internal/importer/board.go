@@ -0,0 +1,88 @@
+// Package importer reads Trello and Todoist export files into one provider independent Board,
+// yang nanti dibikin jadi workspace + task sama ImportService
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Board is one imported Trello board / Todoist backup
+type Board struct {
+	Name        string
+	Description string
+	Lists       []string // list / section / project names, urut kayak di sumber nya
+	Members     []Member
+	Cards       []Card
+}
+
+// Member is someone from the source tool, dicocokin ke user MiniTask lewat email
+type Member struct {
+	ExternalID string
+	Username   string
+	FullName   string
+	Email      string
+}
+
+type Card struct {
+	ExternalID  string
+	Title       string
+	Description string
+	List        string
+	Labels      []string
+	DueDate     *time.Time
+	Done        bool     // dueComplete (Trello) / checked (Todoist)
+	Archived    bool     // closed card or archived list
+	MemberIDs   []string // ExternalID of Member
+	Checklists  []Checklist
+	Comments    []Comment
+}
+
+type Checklist struct {
+	Name  string
+	Items []ChecklistItem
+}
+
+type ChecklistItem struct {
+	Name string
+	Done bool
+}
+
+type Comment struct {
+	Author    string
+	Text      string
+	CreatedAt time.Time
+}
+
+// DescriptionWithChecklists appends checklists as markdown task lists, MiniTask belum punya checklist sendiri
+func (c *Card) DescriptionWithChecklists() string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(c.Description))
+	for _, checklist := range c.Checklists {
+		if len(checklist.Items) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "**%s**\n", checklist.Name)
+		for _, item := range checklist.Items {
+			mark := " "
+			if item.Done {
+				mark = "x"
+			}
+			fmt.Fprintf(&b, "\n- [%s] %s", mark, item.Name)
+		}
+	}
+	return b.String()
+}
+
+func (b *Board) addList(name string) {
+	for _, list := range b.Lists {
+		if list == name {
+			return
+		}
+	}
+	b.Lists = append(b.Lists, name)
+}
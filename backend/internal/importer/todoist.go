@@ -0,0 +1,266 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type todoistExport struct {
+	Projects []struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		IsDeleted bool   `json:"is_deleted"`
+		IsArchive bool   `json:"is_archived"`
+	} `json:"projects"`
+	Sections []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"sections"`
+	Items []struct {
+		ID             string   `json:"id"`
+		Content        string   `json:"content"`
+		Description    string   `json:"description"`
+		ProjectID      string   `json:"project_id"`
+		SectionID      *string  `json:"section_id"`
+		ParentID       *string  `json:"parent_id"`
+		Labels         []string `json:"labels"`
+		Checked        bool     `json:"checked"`
+		IsDeleted      bool     `json:"is_deleted"`
+		ResponsibleUID *string  `json:"responsible_uid"`
+		Due            *struct {
+			Date string `json:"date"`
+		} `json:"due"`
+	} `json:"items"`
+	Notes []struct {
+		ItemID    string    `json:"item_id"`
+		Content   string    `json:"content"`
+		PostedAt  time.Time `json:"posted_at"`
+		PostedUID string    `json:"posted_uid"`
+		IsDeleted bool      `json:"is_deleted"`
+	} `json:"notes"`
+	Collaborators []struct {
+		ID       string `json:"id"`
+		Email    string `json:"email"`
+		FullName string `json:"full_name"`
+	} `json:"collaborators"`
+}
+
+// ParseTodoistJSON reads a Todoist sync backup (projects, items, notes, collaborators).
+// Project jadi list, sub-task jadi checklist di parent nya
+func ParseTodoistJSON(r io.Reader, name string) (*Board, error) {
+	var export todoistExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, errors.New("invalid Todoist export: " + err.Error())
+	}
+	if len(export.Items) == 0 && len(export.Projects) == 0 {
+		return nil, errors.New("invalid Todoist export: no projects or items")
+	}
+
+	board := &Board{Name: name}
+	projects := map[string]string{}
+	archivedProjects := map[string]bool{}
+	for _, project := range export.Projects {
+		if project.IsDeleted {
+			continue
+		}
+		projects[project.ID] = project.Name
+		archivedProjects[project.ID] = project.IsArchive
+		board.addList(project.Name)
+	}
+	if board.Name == "" {
+		board.Name = "Todoist"
+		if len(board.Lists) == 1 {
+			board.Name = board.Lists[0]
+		}
+	}
+	sections := map[string]string{}
+	for _, section := range export.Sections {
+		sections[section.ID] = section.Name
+	}
+
+	collaborators := map[string]string{}
+	for _, collaborator := range export.Collaborators {
+		collaborators[collaborator.ID] = collaborator.FullName
+		board.Members = append(board.Members, Member{
+			ExternalID: collaborator.ID,
+			FullName:   collaborator.FullName,
+			Email:      strings.ToLower(collaborator.Email),
+		})
+	}
+
+	comments := map[string][]Comment{}
+	for _, note := range export.Notes {
+		if note.IsDeleted || note.Content == "" {
+			continue
+		}
+		author := collaborators[note.PostedUID]
+		if author == "" {
+			author = "Todoist user " + note.PostedUID
+		}
+		comments[note.ItemID] = append(comments[note.ItemID], Comment{Author: author, Text: note.Content, CreatedAt: note.PostedAt})
+	}
+
+	index := map[string]int{}
+	var subtasks []int
+	for i, item := range export.Items {
+		if item.IsDeleted {
+			continue
+		}
+		if item.ParentID != nil && *item.ParentID != "" {
+			subtasks = append(subtasks, i)
+			continue
+		}
+		card := Card{
+			ExternalID:  item.ID,
+			Title:       item.Content,
+			Description: item.Description,
+			List:        projects[item.ProjectID],
+			Labels:      item.Labels,
+			Done:        item.Checked,
+			Archived:    archivedProjects[item.ProjectID],
+			Comments:    comments[item.ID],
+		}
+		if item.SectionID != nil && sections[*item.SectionID] != "" {
+			card.Labels = append(card.Labels, sections[*item.SectionID])
+		}
+		if item.Due != nil {
+			card.DueDate = parseTodoistDate(item.Due.Date)
+		}
+		if item.ResponsibleUID != nil && *item.ResponsibleUID != "" {
+			card.MemberIDs = []string{*item.ResponsibleUID}
+		}
+		index[item.ID] = len(board.Cards)
+		board.Cards = append(board.Cards, card)
+	}
+
+	// sub-task nested lebih dari satu level ikut ke parent paling atas yg ketemu
+	for _, i := range subtasks {
+		item := export.Items[i]
+		parent, ok := index[*item.ParentID]
+		if !ok {
+			continue
+		}
+		card := &board.Cards[parent]
+		if len(card.Checklists) == 0 {
+			card.Checklists = append(card.Checklists, Checklist{Name: "Sub-tasks"})
+		}
+		card.Checklists[0].Items = append(card.Checklists[0].Items, ChecklistItem{Name: item.Content, Done: item.Checked})
+		index[item.ID] = parent
+	}
+	return board, nil
+}
+
+var (
+	todoistLabel  = regexp.MustCompile(`(?:^|\s)@([\p{L}\p{N}_-]+)`)
+	todoistAuthor = regexp.MustCompile(`^(.*?)\s*\((\d+)\)$`)
+)
+
+// ParseTodoistCSV reads a project CSV (Export as template). Satu file = satu project,
+// section jadi list, baris "note" jadi comment task di atas nya, INDENT > 1 jadi checklist
+func ParseTodoistCSV(r io.Reader, name string) (*Board, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("invalid Todoist CSV: " + err.Error())
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	if _, ok := columns["TYPE"]; !ok {
+		return nil, errors.New("invalid Todoist CSV: missing TYPE column")
+	}
+	if _, ok := columns["CONTENT"]; !ok {
+		return nil, errors.New("invalid Todoist CSV: missing CONTENT column")
+	}
+
+	if name == "" {
+		name = "Todoist"
+	}
+	board := &Board{Name: name}
+	list := name
+	board.addList(list)
+	members := map[string]bool{}
+	current := -1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("invalid Todoist CSV: " + err.Error())
+		}
+		get := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		switch strings.ToLower(get("TYPE")) {
+		case "section":
+			list = get("CONTENT")
+			board.addList(list)
+			current = -1
+		case "task":
+			content := get("CONTENT")
+			indent, _ := strconv.Atoi(get("INDENT"))
+			if indent > 1 && current >= 0 {
+				card := &board.Cards[current]
+				if len(card.Checklists) == 0 {
+					card.Checklists = append(card.Checklists, Checklist{Name: "Sub-tasks"})
+				}
+				card.Checklists[0].Items = append(card.Checklists[0].Items, ChecklistItem{Name: content})
+				continue
+			}
+
+			card := Card{
+				Title:       strings.TrimSpace(todoistLabel.ReplaceAllString(content, "")),
+				Description: get("DESCRIPTION"),
+				List:        list,
+				DueDate:     parseTodoistDate(get("DATE")),
+			}
+			for _, match := range todoistLabel.FindAllStringSubmatch(content, -1) {
+				card.Labels = append(card.Labels, match[1])
+			}
+			if match := todoistAuthor.FindStringSubmatch(get("RESPONSIBLE")); match != nil {
+				card.MemberIDs = []string{match[2]}
+				if !members[match[2]] {
+					members[match[2]] = true
+					board.Members = append(board.Members, Member{ExternalID: match[2], FullName: match[1]})
+				}
+			}
+			current = len(board.Cards)
+			board.Cards = append(board.Cards, card)
+		case "note":
+			if current < 0 || get("CONTENT") == "" {
+				continue
+			}
+			author := get("AUTHOR")
+			if match := todoistAuthor.FindStringSubmatch(author); match != nil {
+				author = match[1]
+			}
+			board.Cards[current].Comments = append(board.Cards[current].Comments, Comment{Author: author, Text: get("CONTENT")})
+		}
+	}
+	return board, nil
+}
+
+// parseTodoistDate only understands absolute dates, "every monday" dkk di-skip aja
+func parseTodoistDate(value string) *time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+type trelloExport struct {
+	Name  string `json:"name"`
+	Desc  string `json:"desc"`
+	Lists []struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Closed bool    `json:"closed"`
+		Pos    float64 `json:"pos"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string     `json:"id"`
+		Name        string     `json:"name"`
+		Desc        string     `json:"desc"`
+		IDList      string     `json:"idList"`
+		Closed      bool       `json:"closed"`
+		Due         *time.Time `json:"due"`
+		DueComplete bool       `json:"dueComplete"`
+		IDMembers   []string   `json:"idMembers"`
+		Pos         float64    `json:"pos"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string  `json:"idCard"`
+		Name       string  `json:"name"`
+		Pos        float64 `json:"pos"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"` // complete / incomplete
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		FullName string `json:"fullName"`
+		Email    string `json:"email"` // cuma ada kalo yg export admin workspace nya
+	} `json:"members"`
+	Actions []struct {
+		Type string    `json:"type"`
+		Date time.Time `json:"date"`
+		Data struct {
+			Text string `json:"text"`
+			Card struct {
+				ID string `json:"id"`
+			} `json:"card"`
+		} `json:"data"`
+		MemberCreator struct {
+			Username string `json:"username"`
+			FullName string `json:"fullName"`
+		} `json:"memberCreator"`
+	} `json:"actions"`
+}
+
+// ParseTrello reads a board JSON export (Board menu > Print, export and share > Export as JSON)
+func ParseTrello(r io.Reader) (*Board, error) {
+	var export trelloExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, errors.New("invalid Trello export: " + err.Error())
+	}
+	if export.Name == "" && len(export.Cards) == 0 {
+		return nil, errors.New("invalid Trello export: not a board")
+	}
+
+	board := &Board{Name: export.Name, Description: export.Desc}
+
+	sort.SliceStable(export.Lists, func(i, j int) bool { return export.Lists[i].Pos < export.Lists[j].Pos })
+	listNames := map[string]string{}
+	archivedLists := map[string]bool{}
+	for _, list := range export.Lists {
+		listNames[list.ID] = list.Name
+		archivedLists[list.ID] = list.Closed
+		board.addList(list.Name)
+	}
+
+	for _, member := range export.Members {
+		board.Members = append(board.Members, Member{
+			ExternalID: member.ID,
+			Username:   member.Username,
+			FullName:   member.FullName,
+			Email:      strings.ToLower(member.Email),
+		})
+	}
+
+	checklists := map[string][]Checklist{}
+	sort.SliceStable(export.Checklists, func(i, j int) bool { return export.Checklists[i].Pos < export.Checklists[j].Pos })
+	for _, checklist := range export.Checklists {
+		items := checklist.CheckItems
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+		converted := Checklist{Name: checklist.Name}
+		for _, item := range items {
+			converted.Items = append(converted.Items, ChecklistItem{Name: item.Name, Done: item.State == "complete"})
+		}
+		checklists[checklist.IDCard] = append(checklists[checklist.IDCard], converted)
+	}
+
+	// actions di export Trello urut terbaru duluan, comment nya dibalik biar kronologis
+	comments := map[string][]Comment{}
+	for i := len(export.Actions) - 1; i >= 0; i-- {
+		action := export.Actions[i]
+		if action.Type != "commentCard" || action.Data.Text == "" {
+			continue
+		}
+		author := action.MemberCreator.FullName
+		if author == "" {
+			author = action.MemberCreator.Username
+		}
+		comments[action.Data.Card.ID] = append(comments[action.Data.Card.ID], Comment{
+			Author:    author,
+			Text:      action.Data.Text,
+			CreatedAt: action.Date,
+		})
+	}
+
+	sort.SliceStable(export.Cards, func(i, j int) bool { return export.Cards[i].Pos < export.Cards[j].Pos })
+	for _, card := range export.Cards {
+		var labels []string
+		for _, label := range card.Labels {
+			name := label.Name
+			if name == "" {
+				name = label.Color // label Trello tanpa nama cuma warna
+			}
+			if name != "" {
+				labels = append(labels, name)
+			}
+		}
+		board.Cards = append(board.Cards, Card{
+			ExternalID:  card.ID,
+			Title:       card.Name,
+			Description: card.Desc,
+			List:        listNames[card.IDList],
+			Labels:      labels,
+			DueDate:     card.Due,
+			Done:        card.DueComplete,
+			Archived:    card.Closed || archivedLists[card.IDList],
+			MemberIDs:   card.IDMembers,
+			Checklists:  checklists[card.ID],
+			Comments:    comments[card.ID],
+		})
+	}
+	return board, nil
+}
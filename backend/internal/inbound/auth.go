@@ -0,0 +1,51 @@
+package inbound
+
+import (
+	"regexp"
+	"strings"
+)
+
+var headerComment = regexp.MustCompile(`\([^()]*\)`)
+
+// SenderAuthenticated tells whether our own mail server verified the From domain (RFC 8601).
+// Header From bisa dipalsu siapa aja, jadi cuma percaya Authentication-Results paling atas dan
+// itu pun cuma kalo authserv-id nya punya kita (yg dibawah bisa aja ditulis sendiri sama pengirim).
+// Lolos kalo dmarc=pass buat domain From, atau dkim=pass dengan signing domain == domain From
+func (m *Message) SenderAuthenticated(authservID string) bool {
+	if authservID == "" || len(m.AuthResults) == 0 {
+		return false
+	}
+	at := strings.LastIndex(m.From, "@")
+	if at < 0 {
+		return false
+	}
+	domain := m.From[at+1:]
+
+	parts := strings.Split(headerComment.ReplaceAllString(m.AuthResults[0], ""), ";")
+	if fields := strings.Fields(parts[0]); len(fields) == 0 || !strings.EqualFold(fields[0], authservID) {
+		return false
+	}
+	for _, part := range parts[1:] {
+		fields := strings.Fields(strings.ToLower(part))
+		if len(fields) == 0 {
+			continue
+		}
+		props := map[string]string{}
+		for _, field := range fields[1:] {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				props[key] = strings.Trim(value, `"`)
+			}
+		}
+		switch fields[0] {
+		case "dmarc=pass":
+			if props["header.from"] == domain {
+				return true
+			}
+		case "dkim=pass":
+			if props["header.d"] == domain || strings.HasSuffix(props["header.i"], "@"+domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
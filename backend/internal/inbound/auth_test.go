@@ -0,0 +1,37 @@
+package inbound
+
+import "testing"
+
+func TestSenderAuthenticated(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		results []string
+		want    bool
+	}{
+		{"dkim pass", "owner@acme.com", []string{"mx.minitask.app; dkim=pass header.d=acme.com header.s=s1"}, true},
+		{"dmarc pass", "owner@acme.com", []string{"mx.minitask.app; spf=fail; dmarc=pass (p=reject) header.from=acme.com"}, true},
+		{"dkim identity", "owner@acme.com", []string{"mx.minitask.app 1; dkim=pass header.i=@acme.com"}, true},
+		{"case insensitive", "owner@acme.com", []string{"MX.minitask.app; DKIM=Pass header.d=ACME.com"}, true},
+		{"no header", "owner@acme.com", nil, false},
+		{"dkim fail", "owner@acme.com", []string{"mx.minitask.app; dkim=fail header.d=acme.com"}, false},
+		{"other domain signed", "owner@acme.com", []string{"mx.minitask.app; dkim=pass header.d=evil.com"}, false},
+		{"parent domain only", "owner@acme.com", []string{"mx.minitask.app; dkim=pass header.d=com"}, false},
+		{"foreign authserv", "owner@acme.com", []string{"evil.com; dkim=pass header.d=acme.com"}, false},
+		{"forged below ours", "owner@acme.com", []string{"mx.minitask.app; dkim=none", "mx.minitask.app; dkim=pass header.d=acme.com"}, false},
+		{"pass in comment", "owner@acme.com", []string{"mx.minitask.app; dkim=none (dkim=pass header.d=acme.com)"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Message{From: tt.from, AuthResults: tt.results}
+			if got := msg.SenderAuthenticated("mx.minitask.app"); got != tt.want {
+				t.Errorf("SenderAuthenticated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	msg := &Message{From: "owner@acme.com", AuthResults: []string{"mx.minitask.app; dkim=pass header.d=acme.com"}}
+	if msg.SenderAuthenticated("") {
+		t.Error("SenderAuthenticated without authserv-id should never pass")
+	}
+}
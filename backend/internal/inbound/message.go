@@ -0,0 +1,265 @@
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html/charset"
+)
+
+// maxDepth batas nested multipart, email normal paling 3-4 level
+const maxDepth = 10
+
+var ErrNoRecipient = errors.New("message has no recipient")
+
+// Attachment is one decoded file part of the message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is the part of an RFC 5322 email we care about for tasks
+type Message struct {
+	MessageID   string
+	InReplyTo   string
+	From        string // address only, lowercase
+	FromName    string
+	To          []string // To, Cc and Delivered-To addresses, lowercase
+	Subject     string
+	Text        string
+	Attachments []Attachment
+	AuthResults []string // Authentication-Results headers, paling atas duluan
+}
+
+var decoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// Parse reads a raw message. Body diambil dari text/plain, kalo cuma ada HTML tag nya dibuang
+func Parse(raw []byte) (*Message, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		MessageID:   strings.Trim(m.Header.Get("Message-Id"), "<> "),
+		InReplyTo:   strings.Trim(m.Header.Get("In-Reply-To"), "<> "),
+		AuthResults: m.Header["Authentication-Results"],
+	}
+	if subject, err := decoder.DecodeHeader(m.Header.Get("Subject")); err == nil {
+		msg.Subject = strings.TrimSpace(subject)
+	} else {
+		msg.Subject = strings.TrimSpace(m.Header.Get("Subject"))
+	}
+
+	parser := mail.AddressParser{WordDecoder: decoder}
+	from, err := parser.Parse(m.Header.Get("From"))
+	if err != nil {
+		return nil, errors.New("invalid From header")
+	}
+	msg.From = strings.ToLower(from.Address)
+	msg.FromName = from.Name
+
+	for _, header := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		if m.Header.Get(header) == "" {
+			continue
+		}
+		list, err := parser.ParseList(m.Header.Get(header))
+		if err != nil {
+			continue
+		}
+		for _, addr := range list {
+			msg.To = append(msg.To, strings.ToLower(addr.Address))
+		}
+	}
+	if len(msg.To) == 0 {
+		return nil, ErrNoRecipient
+	}
+
+	var text, html string
+	if err := walk(m.Header, m.Body, 0, msg, &text, &html); err != nil {
+		return nil, err
+	}
+	if text == "" && html != "" {
+		text = htmlToText(html)
+	}
+	msg.Text = strings.TrimSpace(normalizeNewlines(text))
+	return msg, nil
+}
+
+// header is either mail.Header or a part's textproto.MIMEHeader
+type header interface {
+	Get(key string) string
+}
+
+// walk goes through the MIME tree, first text/plain & text/html win, sisanya jadi attachment
+func walk(h header, body io.Reader, depth int, msg *Message, text, html *string) error {
+	if depth > maxDepth {
+		return errors.New("message nested too deep")
+	}
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := walk(part.Header, part, depth+1, msg, text, html); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := decodeBody(h.Get("Content-Transfer-Encoding"), body)
+	if err != nil {
+		return err
+	}
+
+	disposition, dispParams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	filename := dispParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if decoded, err := decoder.DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+
+	isAttachment := disposition == "attachment" || filename != ""
+	switch {
+	case !isAttachment && mediaType == "text/plain" && *text == "":
+		*text = toUTF8(data, params["charset"])
+	case !isAttachment && mediaType == "text/html" && *html == "":
+		*html = toUTF8(data, params["charset"])
+	case isAttachment || !strings.HasPrefix(mediaType, "text/"):
+		if filename == "" {
+			filename = "attachment"
+		}
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Filename:    filepath.Base(filename),
+			ContentType: mediaType,
+			Data:        data,
+		})
+	}
+	return nil
+}
+
+func decodeBody(encoding string, body io.Reader) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return io.ReadAll(base64.NewDecoder(base64.StdEncoding, newlineStripper{body}))
+	case "quoted-printable":
+		return io.ReadAll(quotedprintable.NewReader(body))
+	}
+	return io.ReadAll(body)
+}
+
+// newlineStripper drops CR/LF so base64 lines wrapped at 76 chars decode fine
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	count, err := n.r.Read(p)
+	kept := 0
+	for _, b := range p[:count] {
+		if b != '\r' && b != '\n' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+func toUTF8(data []byte, label string) string {
+	if label == "" || strings.EqualFold(label, "utf-8") || strings.EqualFold(label, "us-ascii") {
+		return string(data)
+	}
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(data))
+	if err != nil {
+		return string(data)
+	}
+	converted, err := io.ReadAll(reader)
+	if err != nil {
+		return string(data)
+	}
+	return string(converted)
+}
+
+var (
+	blockTags  = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/tr|/h[1-6])[^>]*>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	stripAll   = bluemonday.StrictPolicy()
+)
+
+func htmlToText(html string) string {
+	text := blockTags.ReplaceAllString(html, "$0\n")
+	text = stripAll.Sanitize(text)
+	text = strings.NewReplacer("&nbsp;", " ", "&amp;", "&", "&lt;", "<", "&gt;", ">", "&#39;", "'", "&quot;", `"`).Replace(text)
+	return blankLines.ReplaceAllString(text, "\n\n")
+}
+
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+var replyHeader = regexp.MustCompile(`(?m)^(On .+wrote:|Pada .+menulis:|-----Original Message-----|From: .+)$`)
+
+// StripQuoted cuts the quoted history off a reply, yang dipake buat comment cuma balasan barunya
+func StripQuoted(text string) string {
+	if loc := replyHeader.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), ">") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	// signature delimiter "-- "
+	for i, line := range lines {
+		if line == "-- " {
+			lines = lines[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// CleanSubject removes Re:/Fwd: prefixes, dipake buat judul task
+func CleanSubject(subject string) string {
+	for {
+		trimmed := strings.TrimSpace(subject)
+		lower := strings.ToLower(trimmed)
+		cut := false
+		for _, prefix := range []string{"re:", "fw:", "fwd:", "aw:", "bls:"} {
+			if strings.HasPrefix(lower, prefix) {
+				trimmed = trimmed[len(prefix):]
+				cut = true
+				break
+			}
+		}
+		subject = trimmed
+		if !cut {
+			return strings.TrimSpace(subject)
+		}
+	}
+}
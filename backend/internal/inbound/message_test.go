@@ -0,0 +1,220 @@
+package inbound
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// crlf turns a readable fixture into a wire message
+func crlf(s string) []byte {
+	return []byte(strings.ReplaceAll(strings.TrimPrefix(s, "\n"), "\n", "\r\n"))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		check func(t *testing.T, msg *Message)
+	}{
+		{
+			name: "plain text",
+			raw: `
+From: "Alice Client" <Alice@Example.COM>
+To: ws-abc@inbound.test
+Cc: Other <other@example.com>
+Subject: Login broken
+Message-ID: <m1@example.com>
+In-Reply-To: <parent@minitask>
+Authentication-Results: mx.inbound.test; dkim=pass header.d=example.com
+Authentication-Results: forged; dkim=pass
+
+Hi,
+it's broken.
+`,
+			check: func(t *testing.T, msg *Message) {
+				if msg.From != "alice@example.com" || msg.FromName != "Alice Client" {
+					t.Errorf("from = %q %q", msg.From, msg.FromName)
+				}
+				if len(msg.To) != 2 || msg.To[0] != "ws-abc@inbound.test" || msg.To[1] != "other@example.com" {
+					t.Errorf("to = %v", msg.To)
+				}
+				if msg.Subject != "Login broken" || msg.MessageID != "m1@example.com" || msg.InReplyTo != "parent@minitask" {
+					t.Errorf("headers = %q %q %q", msg.Subject, msg.MessageID, msg.InReplyTo)
+				}
+				if msg.Text != "Hi,\nit's broken." {
+					t.Errorf("text = %q", msg.Text)
+				}
+				if len(msg.AuthResults) != 2 || !strings.HasPrefix(msg.AuthResults[0], "mx.inbound.test") {
+					t.Errorf("auth results = %v", msg.AuthResults)
+				}
+			},
+		},
+		{
+			name: "encoded subject and quoted-printable latin1 body",
+			raw: `
+From: bob@example.com
+Delivered-To: ws-abc@inbound.test
+Subject: =?UTF-8?B?VHVnYXMgYmFydTog4pyT?=
+Content-Type: text/plain; charset=iso-8859-1
+Content-Transfer-Encoding: quoted-printable
+
+Caf=E9 ready=
+ now
+`,
+			check: func(t *testing.T, msg *Message) {
+				if msg.Subject != "Tugas baru: ✓" {
+					t.Errorf("subject = %q", msg.Subject)
+				}
+				if msg.Text != "Café ready now" {
+					t.Errorf("text = %q", msg.Text)
+				}
+			},
+		},
+		{
+			name: "html only becomes text",
+			raw: `
+From: bob@example.com
+To: ws-abc@inbound.test
+Subject: html
+Content-Type: text/html; charset=utf-8
+
+<p>First &amp; foremost</p><div>second<br>third</div><script>x</script>
+`,
+			check: func(t *testing.T, msg *Message) {
+				for _, want := range []string{"First & foremost", "second\nthird"} {
+					if !strings.Contains(msg.Text, want) {
+						t.Errorf("text %q is missing %q", msg.Text, want)
+					}
+				}
+				if strings.Contains(msg.Text, "<") {
+					t.Errorf("tags left in text: %q", msg.Text)
+				}
+			},
+		},
+		{
+			name: "multipart with alternative and base64 attachment",
+			raw: `
+From: bob@example.com
+To: ws-abc@inbound.test
+Subject: with file
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary=outer
+
+--outer
+Content-Type: multipart/alternative; boundary=inner
+
+--inner
+Content-Type: text/plain; charset=utf-8
+
+plain wins
+--inner
+Content-Type: text/html; charset=utf-8
+
+<p>html loses</p>
+--inner--
+--outer
+Content-Type: application/pdf; name="ignored.pdf"
+Content-Disposition: attachment; filename="../../etc/report.pdf"
+Content-Transfer-Encoding: base64
+
+JVBERi0x
+LjQK
+--outer
+Content-Type: text/plain
+Content-Disposition: attachment; filename="notes.txt"
+
+attached text
+--outer--
+`,
+			check: func(t *testing.T, msg *Message) {
+				if msg.Text != "plain wins" {
+					t.Errorf("text = %q", msg.Text)
+				}
+				if len(msg.Attachments) != 2 {
+					t.Fatalf("attachments = %+v", msg.Attachments)
+				}
+				pdf := msg.Attachments[0]
+				if pdf.Filename != "report.pdf" || pdf.ContentType != "application/pdf" || string(pdf.Data) != "%PDF-1.4\n" {
+					t.Errorf("pdf = %q %q %q", pdf.Filename, pdf.ContentType, pdf.Data)
+				}
+				if msg.Attachments[1].Filename != "notes.txt" || strings.TrimSpace(string(msg.Attachments[1].Data)) != "attached text" {
+					t.Errorf("text attachment = %+v", msg.Attachments[1])
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Parse(crlf(tt.raw))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			tt.check(t, msg)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{"no recipient", "\nFrom: bob@example.com\nSubject: x\n\nbody\n", ErrNoRecipient},
+		{"bad from", "\nFrom: not an address\nTo: ws@inbound.test\n\nbody\n", nil},
+		{"no headers", "just text", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(crlf(tt.raw))
+			if err == nil {
+				t.Fatal("Parse() should fail")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStripQuoted(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"no quote", "Thanks, done.", "Thanks, done."},
+		{"gmail header", "Sounds good\n\nOn Mon, 5 Jan 2026 at 10:00, MiniTask <x@y> wrote:\n> old", "Sounds good"},
+		{"indonesian header", "Oke siap\n\nPada Sen, 5 Jan 2026, MiniTask menulis:\n> lama", "Oke siap"},
+		{"outlook header", "Noted\n-----Original Message-----\nFrom: someone", "Noted"},
+		{"inline quotes dropped", "> quoted\nreply here\n> more", "reply here"},
+		{"signature", "Fixed it\n-- \nAlice\nACME", "Fixed it"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripQuoted(tt.in); got != tt.want {
+				t.Errorf("StripQuoted() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanSubject(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Login broken", "Login broken"},
+		{"Re: Login broken", "Login broken"},
+		{"RE: Fwd: re:  Login broken ", "Login broken"},
+		{"AW: Bls: FW: x", "x"},
+		{"Regarding invoices", "Regarding invoices"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CleanSubject(tt.in); got != tt.want {
+			t.Errorf("CleanSubject(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package mailer
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"minitask/internal/mailer/mailertest"
+)
+
+func TestSMTPMailerSend(t *testing.T) {
+	server := mailertest.NewServer(t)
+	m := &SMTPMailer{Addr: server.Addr, From: "MiniTask <no-reply@minitask.test>"}
+
+	err := m.Send(Message{
+		To:      "alice@example.com",
+		Subject: "Tugas baru: révision",
+		Text:    "plain body\n.leading dot",
+		HTML:    "<p>html body</p>",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	env := server.Next(t, time.Second)
+	if env.From != "no-reply@minitask.test" {
+		t.Errorf("envelope from = %q", env.From)
+	}
+	if len(env.To) != 1 || env.To[0] != "alice@example.com" {
+		t.Errorf("envelope to = %v", env.To)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(env.Data))
+	if err != nil {
+		t.Fatalf("message doesn't parse: %v", err)
+	}
+	if got := msg.Header.Get("From"); got != "MiniTask <no-reply@minitask.test>" {
+		t.Errorf("From = %q", got)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Tugas baru: révision" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-Id"), "@minitask.test>") {
+		t.Errorf("Message-ID = %q", msg.Header.Get("Message-Id"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", msg.Header.Get("Content-Type"))
+	}
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = strings.ReplaceAll(string(body), "\r\n", "\n")
+	}
+	if parts["text/plain"] != "plain body\n.leading dot" {
+		t.Errorf("text part = %q", parts["text/plain"])
+	}
+	if parts["text/html"] != "<p>html body</p>" {
+		t.Errorf("html part = %q", parts["text/html"])
+	}
+}
+
+func TestSMTPMailerSendTextOnly(t *testing.T) {
+	server := mailertest.NewServer(t)
+	m := &SMTPMailer{Addr: server.Addr, From: "no-reply@minitask.test"}
+	if err := m.Send(Message{To: "bob@example.com", Subject: "hi", Text: "only text"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	env := server.Next(t, time.Second)
+	if strings.Contains(env.Data, "text/html") {
+		t.Error("empty HTML part should be left out")
+	}
+}
+
+func TestSMTPMailerSendUnreachable(t *testing.T) {
+	m := &SMTPMailer{Addr: "127.0.0.1:1", From: "no-reply@minitask.test"}
+	if err := m.Send(Message{To: "bob@example.com", Subject: "hi", Text: "x"}); err == nil {
+		t.Error("Send() to a closed port should fail")
+	}
+}
+
+func TestEnvelopeAddress(t *testing.T) {
+	tests := []struct {
+		from string
+		want string
+	}{
+		{"MiniTask <no-reply@minitask.local>", "no-reply@minitask.local"},
+		{"no-reply@minitask.local", "no-reply@minitask.local"},
+		{"<a@b.c>", "a@b.c"},
+		{"broken <a@b.c", "broken <a@b.c"},
+	}
+	for _, tt := range tests {
+		if got := envelopeAddress(tt.from); got != tt.want {
+			t.Errorf("envelopeAddress(%q) = %q, want %q", tt.from, got, tt.want)
+		}
+	}
+}
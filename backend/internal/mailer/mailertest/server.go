@@ -0,0 +1,142 @@
+// Package mailertest is a tiny in-process SMTP server for tests, cuma ngerti perintah yg dipake
+// net/smtp.SendMail (tanpa STARTTLS & AUTH)
+package mailertest
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Envelope is one message the server accepted
+type Envelope struct {
+	From string
+	To   []string
+	Data string // raw message, dot-unstuffed, CRLF line endings
+}
+
+type Server struct {
+	Addr string
+
+	listener net.Listener
+	messages chan Envelope
+	wg       sync.WaitGroup
+}
+
+// NewServer listens on a random local port, ditutup otomatis pas test nya selesai
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("mailertest: listen: %v", err)
+	}
+	s := &Server{Addr: listener.Addr().String(), listener: listener, messages: make(chan Envelope, 16)}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		s.wg.Wait()
+	})
+	return s
+}
+
+// Next waits for the next accepted message
+func (s *Server) Next(t testing.TB, timeout time.Duration) Envelope {
+	t.Helper()
+	select {
+	case env := <-s.messages:
+		return env
+	case <-time.After(timeout):
+		t.Fatalf("mailertest: no message within %s", timeout)
+		return Envelope{}
+	}
+}
+
+// Pending returns how many accepted messages haven't been read with Next yet
+func (s *Server) Pending() int {
+	return len(s.messages)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 mailertest ESMTP")
+	var env Envelope
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 mailertest")
+		case "MAIL":
+			env = Envelope{From: addressArg(line)}
+			reply("250 OK")
+		case "RCPT":
+			env.To = append(env.To, addressArg(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			env.Data = data.String()
+			s.messages <- env
+			reply("250 queued")
+		case "RSET":
+			env = Envelope{}
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// addressArg pulls the address out of "MAIL FROM:<a@b.c>" / "RCPT TO:<a@b.c>"
+func addressArg(line string) string {
+	start := strings.Index(line, "<")
+	end := strings.LastIndex(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
@@ -0,0 +1,211 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+const (
+	TemplateTaskAssigned    = "task_assigned"
+	TemplateMentioned       = "mentioned"
+	TemplateTaskDueSoon     = "task_due_soon"
+	TemplateWorkspaceInvite = "workspace_invite"
+	TemplateDailyDigest     = "daily_digest"
+	TemplatePasswordReset   = "password_reset"
+	TemplatePasswordChanged = "password_changed"
+	TemplateVerifyEmail     = "verify_email"
+)
+
+// NotificationEmail is the data for assignment, mention and due-soon emails
+type NotificationEmail struct {
+	Recipient string
+	Title     string
+	Body      string
+	Link      string
+}
+
+type InviteEmail struct {
+	InviterName   string
+	WorkspaceName string
+	InviteCode    string
+	Link          string
+}
+
+type DigestTask struct {
+	Title     string
+	Status    string
+	DueDate   string
+	Workspace string
+	Link      string
+}
+
+type DigestEmail struct {
+	Recipient string
+	Date      string
+	Open      []DigestTask
+	Overdue   []DigestTask
+	Link      string
+}
+
+// AccountEmail is the data for password reset / changed and verify emails, bukan notifikasi jadi gk bisa di-opt out
+type AccountEmail struct {
+	Recipient string
+	Link      string
+	ExpiresIn string
+}
+
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+const htmlFooter = `<p style="color:#888;font-size:12px">You can change which emails you get in MiniTask notification settings.</p>`
+
+var notificationText = `Hi {{.Recipient}},
+
+{{.Title}}
+{{if .Body}}
+{{.Body}}
+{{end}}
+Open in MiniTask: {{.Link}}
+`
+
+var notificationHTML = `<p>Hi {{.Recipient}},</p>
+<p><strong>{{.Title}}</strong></p>
+{{if .Body}}<blockquote>{{.Body}}</blockquote>{{end}}
+<p><a href="{{.Link}}">Open in MiniTask</a></p>` + htmlFooter
+
+var templates = map[string]emailTemplate{
+	TemplateTaskAssigned: newTemplate(
+		`{{.Title}}`,
+		notificationText,
+		notificationHTML,
+	),
+	TemplateMentioned: newTemplate(
+		`{{.Title}}`,
+		notificationText,
+		notificationHTML,
+	),
+	TemplateTaskDueSoon: newTemplate(
+		`Reminder: {{.Title}}`,
+		notificationText,
+		notificationHTML,
+	),
+	TemplateWorkspaceInvite: newTemplate(
+		`{{.InviterName}} invited you to {{.WorkspaceName}} on MiniTask`,
+		`Hi,
+
+{{.InviterName}} invited you to join the workspace "{{.WorkspaceName}}" on MiniTask.
+
+Invite code: {{.InviteCode}}
+Join here: {{.Link}}
+`,
+		`<p>Hi,</p>
+<p>{{.InviterName}} invited you to join the workspace <strong>{{.WorkspaceName}}</strong> on MiniTask.</p>
+<p>Invite code: <code>{{.InviteCode}}</code></p>
+<p><a href="{{.Link}}">Join workspace</a></p>`,
+	),
+	TemplateDailyDigest: newTemplate(
+		`Your MiniTask digest for {{.Date}}`,
+		`Hi {{.Recipient}},
+{{if .Overdue}}
+Overdue:
+{{range .Overdue}}- {{.Title}}{{if .Workspace}} [{{.Workspace}}]{{end}} (due {{.DueDate}}) {{.Link}}
+{{end}}{{end}}{{if .Open}}
+Open tasks:
+{{range .Open}}- {{.Title}}{{if .Workspace}} [{{.Workspace}}]{{end}} - {{.Status}}{{if .DueDate}}, due {{.DueDate}}{{end}}
+{{end}}{{end}}
+Open MiniTask: {{.Link}}
+`,
+		`<p>Hi {{.Recipient}},</p>
+{{if .Overdue}}<h3>Overdue</h3>
+<ul>{{range .Overdue}}<li><a href="{{.Link}}">{{.Title}}</a>{{if .Workspace}} [{{.Workspace}}]{{end}} &ndash; due {{.DueDate}}</li>{{end}}</ul>{{end}}
+{{if .Open}}<h3>Open tasks</h3>
+<ul>{{range .Open}}<li><a href="{{.Link}}">{{.Title}}</a>{{if .Workspace}} [{{.Workspace}}]{{end}} &ndash; {{.Status}}{{if .DueDate}}, due {{.DueDate}}{{end}}</li>{{end}}</ul>{{end}}
+<p><a href="{{.Link}}">Open MiniTask</a></p>`+htmlFooter,
+	),
+	TemplatePasswordReset: newTemplate(
+		`Reset your MiniTask password`,
+		`Hi {{.Recipient}},
+
+Someone asked to reset the password of your MiniTask account. Open this link to choose a new one:
+
+{{.Link}}
+
+The link works once and expires in {{.ExpiresIn}}. If you didn't ask for this, you can ignore this email.
+`,
+		`<p>Hi {{.Recipient}},</p>
+<p>Someone asked to reset the password of your MiniTask account.</p>
+<p><a href="{{.Link}}">Choose a new password</a></p>
+<p style="color:#888;font-size:12px">The link works once and expires in {{.ExpiresIn}}. If you didn't ask for this, you can ignore this email.</p>`,
+	),
+	TemplatePasswordChanged: newTemplate(
+		`Your MiniTask password was changed`,
+		`Hi {{.Recipient}},
+
+The password of your MiniTask account was just changed and your other devices were signed out.
+If this wasn't you, reset your password right away: {{.Link}}
+`,
+		`<p>Hi {{.Recipient}},</p>
+<p>The password of your MiniTask account was just changed and your other devices were signed out.</p>
+<p>If this wasn't you, <a href="{{.Link}}">reset your password</a> right away.</p>`,
+	),
+	TemplateVerifyEmail: newTemplate(
+		`Confirm your MiniTask email address`,
+		`Hi {{.Recipient}},
+
+Please confirm this is your email address by opening this link:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}}. If you didn't create a MiniTask account, you can ignore this email.
+`,
+		`<p>Hi {{.Recipient}},</p>
+<p>Please confirm this is your email address.</p>
+<p><a href="{{.Link}}">Confirm email address</a></p>
+<p style="color:#888;font-size:12px">The link expires in {{.ExpiresIn}}. If you didn't create a MiniTask account, you can ignore this email.</p>`,
+	),
+}
+
+func newTemplate(subject, text, html string) emailTemplate {
+	return emailTemplate{
+		subject: texttemplate.Must(texttemplate.New("subject").Parse(subject)),
+		text:    texttemplate.Must(texttemplate.New("text").Parse(text)),
+		html:    htmltemplate.Must(htmltemplate.New("html").Parse(html)),
+	}
+}
+
+// HasTemplate tells whether there is an email version for a notification type
+func HasTemplate(name string) bool {
+	_, ok := templates[name]
+	return ok
+}
+
+// Render fills in a named template and returns a message ready to send to `to`
+func Render(name, to string, data interface{}) (Message, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: subject.String(),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
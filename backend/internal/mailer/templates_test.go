@@ -0,0 +1,88 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		data        interface{}
+		subject     string
+		textHas     []string
+		htmlHas     []string
+		htmlMissing []string
+	}{
+		{
+			name:     "assigned escapes html",
+			template: TemplateTaskAssigned,
+			data:     NotificationEmail{Recipient: "alice", Title: "bob assigned you to <b>X</b>", Body: "a & b", Link: "https://app.test/t/1"},
+			subject:  "bob assigned you to <b>X</b>",
+			textHas:  []string{"Hi alice,", "bob assigned you to <b>X</b>", "a & b", "https://app.test/t/1"},
+			htmlHas:  []string{"&lt;b&gt;X&lt;/b&gt;", "a &amp; b", `href="https://app.test/t/1"`},
+		},
+		{
+			name:        "digest without overdue",
+			template:    TemplateDailyDigest,
+			data:        DigestEmail{Recipient: "alice", Date: "Mon, 5 Jan 2026", Open: []DigestTask{{Title: "Write spec", Status: "not_started", Workspace: "Acme", Link: "https://app.test/t/2"}}, Link: "https://app.test/dashboard"},
+			textHas:     []string{"Write spec"},
+			htmlHas:     []string{"Open tasks", "Write spec", "[Acme]"},
+			htmlMissing: []string{"Overdue"},
+		},
+		{
+			name:     "digest with due dates",
+			template: TemplateDailyDigest,
+			data:     DigestEmail{Recipient: "alice", Date: "Mon, 5 Jan 2026", Overdue: []DigestTask{{Title: "Pay invoice", Status: "in_progress", DueDate: "2 Jan 2026", Link: "https://app.test/t/3"}}, Link: "https://app.test/dashboard"},
+			textHas:  []string{"Pay invoice", "2 Jan 2026"},
+			htmlHas:  []string{"Overdue", "due 2 Jan 2026"},
+		},
+		{
+			name:     "verify email",
+			template: TemplateVerifyEmail,
+			data:     AccountEmail{Recipient: "alice", Link: "https://app.test/verify-email?token=mt_ev_x", ExpiresIn: "24 hours"},
+			subject:  "Confirm your MiniTask email address",
+			textHas:  []string{"https://app.test/verify-email?token=mt_ev_x", "24 hours"},
+			htmlHas:  []string{`href="https://app.test/verify-email?token=mt_ev_x"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Render(tt.template, "alice@example.com", tt.data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if msg.To != "alice@example.com" {
+				t.Errorf("To = %q", msg.To)
+			}
+			if tt.subject != "" && msg.Subject != tt.subject {
+				t.Errorf("Subject = %q, want %q", msg.Subject, tt.subject)
+			}
+			for _, want := range tt.textHas {
+				if !strings.Contains(msg.Text, want) {
+					t.Errorf("text is missing %q:\n%s", want, msg.Text)
+				}
+			}
+			for _, want := range tt.htmlHas {
+				if !strings.Contains(msg.HTML, want) {
+					t.Errorf("html is missing %q:\n%s", want, msg.HTML)
+				}
+			}
+			for _, unwanted := range tt.htmlMissing {
+				if strings.Contains(msg.HTML, unwanted) {
+					t.Errorf("html shouldn't contain %q:\n%s", unwanted, msg.HTML)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	if _, err := Render("nope", "alice@example.com", nil); err == nil {
+		t.Error("Render() of an unknown template should fail")
+	}
+	if HasTemplate("nope") || !HasTemplate(TemplateMentioned) {
+		t.Error("HasTemplate() mismatch")
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -10,12 +11,26 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// AccessTokenTTL sengaja pendek, sesi panjang nya lewat refresh token
+const AccessTokenTTL = 15 * time.Minute
+
 type JWTClaims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
+	// SessionID is the refresh token family the access token came from, di-revoke = token ini ikut mati
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
+// tokenValidator runs after the signature check on every request, buat token yg harus mati sebelum
+// expired (akun dihapus, dll). Di-set dari main lewat SetTokenValidator
+var tokenValidator func(claims *JWTClaims) error
+
+// SetTokenValidator registers the revocation check, nil = semua token yg signature nya valid diterima
+func SetTokenValidator(fn func(claims *JWTClaims) error) {
+	tokenValidator = fn
+}
+
 // validasi jwt tokens buath auth headers
 func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -35,42 +50,115 @@ func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			})
 		}
 
-		tokenString := parts[1]
+		return authenticate(c, next, parts[1])
+	}
+}
+
+// ticketRedeemer turns a ?ticket= from an EventSource connection into the claims it was issued
+// for. Di-set dari main lewat SetStreamTicketRedeemer
+var ticketRedeemer func(ticket string) (*JWTClaims, error)
 
-		// Parse and validate token
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid signing method")
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-		//
-		if err != nil {
+// SetStreamTicketRedeemer registers how stream tickets are redeemed, nil = cuma header yg diterima
+func SetStreamTicketRedeemer(fn func(ticket string) (*JWTClaims, error)) {
+	ticketRedeemer = fn
+}
+
+// JWTStreamMiddleware is JWTMiddleware for EventSource connections, which can't send headers.
+// Instead of the access token they pass a short-lived single-use ?ticket= from POST /events/ticket,
+// jadi access token nya gk pernah nongol di url (dan log). Only use it on streaming routes.
+func JWTStreamMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Header.Get("Authorization") != "" {
+			return JWTMiddleware(next)(c)
+		}
+
+		ticket := c.QueryParam("ticket")
+		if ticket == "" || ticketRedeemer == nil {
 			return c.JSON(http.StatusUnauthorized, map[string]string{
-				"error": "Invalid or expired token",
+				"error": "Missing authorization token",
 			})
 		}
-
-		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-			c.Set("user_id", claims.UserID)
-			c.Set("username", claims.Username)
-			return next(c)
+		claims, err := ticketRedeemer(ticket)
+		if err == nil {
+			err = Revalidate(claims)
+		}
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": err.Error(),
+			})
 		}
+		setClaims(c, claims)
+		return next(c)
+	}
+}
 
+func authenticate(c echo.Context, next echo.HandlerFunc, tokenString string) error {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
 		return c.JSON(http.StatusUnauthorized, map[string]string{
-			"error": "Invalid token claims",
+			"error": err.Error(),
 		})
 	}
+
+	setClaims(c, claims)
+	return next(c)
+}
+
+func setClaims(c echo.Context, claims *JWTClaims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("session_id", claims.SessionID)
+	c.Set("claims", claims)
+}
+
+// Revalidate re-runs the expiry & revocation check for connections that stay open (SSE). Middleware
+// cuma ngecek pas connect, jadi stream yg token nya udah expired / sesi nya di-revoke harus ngecek ulang
+// sendiri. Client nya refresh terus connect lagi pake ticket baru
+func Revalidate(claims *JWTClaims) error {
+	if claims.ExpiresAt == nil || !time.Now().Before(claims.ExpiresAt.Time) {
+		return errors.New("Invalid or expired token")
+	}
+	if tokenValidator == nil {
+		return nil
+	}
+	return tokenValidator(claims)
+}
+
+// ParseToken validates a token string and returns its claims
+func ParseToken(tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		// Validate signing
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid signing method")
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	//
+	if err != nil {
+		return nil, errors.New("Invalid or expired token")
+	}
+
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		if tokenValidator != nil {
+			if err := tokenValidator(claims); err != nil {
+				return nil, err
+			}
+		}
+		return claims, nil
+	}
+
+	return nil, errors.New("Invalid token claims")
 }
 
-// GenerateToken creates a new JWT token for a user
-func GenerateToken(userID, username string) (string, error) {
+// GenerateToken creates a short-lived access token for a user's session
+func GenerateToken(userID, username, sessionID string, expiresAt time.Time) (string, error) {
 	claims := &JWTClaims{ //decode token yg y=udh di  paes
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(72 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
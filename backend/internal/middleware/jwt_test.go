@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRevalidateClosesExpiredTokens(t *testing.T) {
+	validated := 0
+	SetTokenValidator(func(claims *JWTClaims) error {
+		validated++
+		if claims.SessionID == "revoked" {
+			return errors.New("Invalid or expired token")
+		}
+		return nil
+	})
+	t.Cleanup(func() { SetTokenValidator(nil) })
+
+	claims := func(sessionID string, expiresAt time.Time) *JWTClaims {
+		return &JWTClaims{UserID: "u1", SessionID: sessionID, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expiresAt)}}
+	}
+	tests := map[string]struct {
+		claims  *JWTClaims
+		wantErr bool
+	}{
+		"valid":           {claims: claims("s1", time.Now().Add(time.Minute))},
+		"expired":         {claims: claims("s1", time.Now().Add(-time.Second)), wantErr: true},
+		"no expiry":       {claims: &JWTClaims{UserID: "u1", SessionID: "s1"}, wantErr: true},
+		"revoked session": {claims: claims("revoked", time.Now().Add(time.Minute)), wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := Revalidate(tt.claims); (err != nil) != tt.wantErr {
+				t.Errorf("Revalidate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	// token yg expired gk perlu nanya ke db lagi
+	if validated != 2 {
+		t.Errorf("validator ran %d times, want 2", validated)
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ActivityEntityTask      = "task"
+	ActivityEntityWorkspace = "workspace"
+	ActivityEntityMember    = "member"
+	ActivityEntityComment   = "comment"
+	ActivityEntityPayment   = "payment"
+)
+
+const (
+	ActivityCreated         = "created"
+	ActivityUpdated         = "updated"
+	ActivityDeleted         = "deleted"
+	ActivityAssigned        = "assigned"
+	ActivityJoined          = "joined"
+	ActivityRemoved         = "removed"
+	ActivityInviteRefreshed = "invite_refreshed"
+	ActivityCheckoutCreated = "checkout_created"
+	ActivityPlanUpgraded    = "plan_upgraded"
+	ActivityPlanDowngraded  = "plan_downgraded"
+	ActivityOrderClosed     = "order_closed"
+)
+
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ActivityChanges is the before/after diff of an activity, stored as jsonb
+type ActivityChanges map[string]FieldChange
+
+func (c ActivityChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (c *ActivityChanges) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*c = ActivityChanges{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("unsupported type for ActivityChanges")
+	}
+	return json.Unmarshal(raw, c)
+}
+
+type Activity struct {
+	ID          string          `gorm:"type:char(36);primary_key" json:"id"`
+	ActorID     string          `gorm:"type:char(36);not null;index" json:"actorId"`
+	Actor       User            `json:"actor" gorm:"foreignKey:ActorID"`
+	EntityType  string          `gorm:"not null" json:"entityType"`
+	EntityID    string          `gorm:"not null" json:"entityId"`
+	Action      string          `gorm:"not null" json:"action"`
+	WorkspaceID *string         `gorm:"type:char(36);index" json:"workspaceId"`
+	TaskID      *string         `gorm:"type:char(36);index" json:"taskId"`
+	Changes     ActivityChanges `gorm:"type:jsonb" json:"changes"`
+	CreatedAt   time.Time       `gorm:"index" json:"createdAt"`
+}
+
+func (a *Activity) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attachment is a file on a task (and optionally the comment it came with).
+// File nya di disk (ATTACHMENTS_DIR), di db cuma path relatif nya
+type Attachment struct {
+	ID           string    `gorm:"type:char(36);primary_key" json:"id"`
+	TaskID       string    `gorm:"type:char(36);not null;index" json:"taskId"`
+	CommentID    *string   `gorm:"type:char(36);index" json:"commentId"`
+	UploadedByID string    `gorm:"type:char(36);not null" json:"uploadedById"`
+	Filename     string    `gorm:"not null" json:"filename"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `gorm:"not null" json:"size"`
+	StoragePath  string    `gorm:"not null" json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CalendarFeed is a secret ICS url, personal (WorkspaceID nil) or for one workspace.
+// Satu user satu feed per scope, token cuma disimpen hash nya kayak incoming webhook
+type CalendarFeed struct {
+	ID             string     `gorm:"type:char(36);primary_key" json:"id"`
+	UserID         string     `gorm:"type:char(36);not null;uniqueIndex:idx_calendar_feed_scope" json:"userId"`
+	WorkspaceID    *string    `gorm:"type:char(36);uniqueIndex:idx_calendar_feed_scope" json:"workspaceId"`
+	TokenHash      string     `gorm:"not null;uniqueIndex" json:"-"`
+	TokenPrefix    string     `gorm:"not null" json:"tokenPrefix"`
+	Timezone       string     `gorm:"not null;default:'UTC'" json:"timezone"`
+	LastAccessedAt *time.Time `json:"lastAccessedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func (f *CalendarFeed) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -1,21 +1,25 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
-	"time"
 )
 
 type Comment struct {
-	ID        string         `gorm:"type:char(36);primary_key" json:"id"`
-	Content   string         `gorm:"not null" json:"content"`
-	TaskID    string         `gorm:"type:char(36);not null;index" json:"taskId"`
-	Task      Task           `json:"-"`
-	UserID    string         `gorm:"type:char(36);not null" json:"userId"`
-	User      User           `json:"user,omitempty"`
-	CreatedAt time.Time      `json:"createdAt"`
-	UpdatedAt time.Time      `json:"updatedAt"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          string            `gorm:"type:char(36);primary_key" json:"id"`
+	Content     string            `gorm:"not null" json:"content"`
+	ContentHTML string            `gorm:"-" json:"contentHtml"`
+	TaskID      string            `gorm:"type:char(36);not null;index" json:"taskId"`
+	Task        Task              `json:"-"`
+	UserID      string            `gorm:"type:char(36);not null" json:"userId"`
+	User        User              `json:"user,omitempty"`
+	Reactions   []ReactionSummary `gorm:"-" json:"reactions"`
+	EditedAt    *time.Time        `json:"editedAt"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt    `gorm:"index" json:"-"`
 }
 
 func (u *Comment) BeforeCreate(tx *gorm.DB) error { // kalo disini fungsi beforecreate itu buat bikin unique uuid
@@ -24,3 +28,21 @@ func (u *Comment) BeforeCreate(tx *gorm.DB) error { // kalo disini fungsi before
 	}
 	return nil
 }
+
+// CommentRevision keeps the content a comment had before each edit
+type CommentRevision struct {
+	ID          string    `gorm:"type:char(36);primary_key" json:"id"`
+	CommentID   string    `gorm:"type:char(36);not null;index" json:"commentId"`
+	Content     string    `gorm:"not null" json:"content"`
+	ContentHTML string    `gorm:"-" json:"contentHtml"`
+	EditedByID  string    `gorm:"type:char(36);not null" json:"editedById"`
+	EditedBy    User      `json:"editedBy" gorm:"foreignKey:EditedByID"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (r *CommentRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EmailVerificationToken is the link sent after register / resend. Email disimpen juga biar
+// link nya cuma berlaku buat alamat yg dikirimin
+type EmailVerificationToken struct {
+	ID        string     `gorm:"type:char(36);primary_key" json:"id"`
+	UserID    string     `gorm:"type:char(36);not null;index" json:"userId"`
+	Email     string     `gorm:"not null" json:"email"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (t *EmailVerificationToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
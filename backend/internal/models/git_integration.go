@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GitIntegration receives GitHub/GitLab push and PR webhooks for one workspace.
+// Secret disimpen plain karena GitHub signature nya HMAC, harus bisa diitung ulang
+type GitIntegration struct {
+	ID               string     `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID      string     `gorm:"type:char(36);not null;uniqueIndex" json:"workspaceId"`
+	Secret           string     `gorm:"not null" json:"-"`
+	BotUserID        string     `gorm:"type:char(36);not null" json:"botUserId"`
+	AutoCloseOnMerge bool       `gorm:"not null" json:"autoCloseOnMerge"`
+	CreatedByID      string     `gorm:"type:char(36);not null" json:"createdById"`
+	LastEventAt      *time.Time `json:"lastEventAt"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+func (g *GitIntegration) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return nil
+}
+
+const (
+	TaskLinkCommit      = "commit"
+	TaskLinkPullRequest = "pull_request"
+)
+
+// TaskLink is a commit or pull/merge request that mentions a task key.
+// Satu link per (task, provider, repo, type, external id), PR yang ke-update cukup di-upsert
+type TaskLink struct {
+	ID          string    `gorm:"type:char(36);primary_key" json:"id"`
+	TaskID      string    `gorm:"type:char(36);not null;uniqueIndex:idx_task_link" json:"taskId"`
+	WorkspaceID string    `gorm:"type:char(36);not null;index" json:"workspaceId"`
+	Provider    string    `gorm:"not null;uniqueIndex:idx_task_link" json:"provider"`
+	Repository  string    `gorm:"not null;uniqueIndex:idx_task_link" json:"repository"`
+	Type        string    `gorm:"not null;uniqueIndex:idx_task_link" json:"type"`
+	ExternalID  string    `gorm:"not null;uniqueIndex:idx_task_link" json:"externalId"` // commit sha or PR number
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Author      string    `json:"author"`
+	State       string    `json:"state"` // PR only: open, closed, merged
+	Closes      bool      `gorm:"not null" json:"closes"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (l *TaskLink) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InboundMailbox is a workspace's email-to-task address, LocalPart@INBOUND_EMAIL_DOMAIN.
+// LocalPart nya random, jadi address nya sekaligus jadi secret
+type InboundMailbox struct {
+	ID          string    `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID string    `gorm:"type:char(36);not null;uniqueIndex" json:"workspaceId"`
+	LocalPart   string    `gorm:"not null;uniqueIndex" json:"localPart"`
+	Address     string    `gorm:"-" json:"address"`
+	BotUserID   string    `gorm:"type:char(36);not null" json:"botUserId"`
+	CreatedByID string    `gorm:"type:char(36);not null" json:"createdById"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (m *InboundMailbox) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+const (
+	InboundEmailProcessing = "processing"
+	InboundEmailTask       = "task"
+	InboundEmailComment    = "comment"
+	InboundEmailRejected   = "rejected"
+)
+
+// InboundEmail is the processing log of every received message, MessageID dipake buat dedupe
+// kalo email yang sama masuk dua kali (endpoint di-retry, maildir dibaca ulang)
+type InboundEmail struct {
+	ID          string    `gorm:"type:char(36);primary_key" json:"id"`
+	MailboxID   string    `gorm:"type:char(36);not null;uniqueIndex:idx_inbound_message" json:"mailboxId"`
+	WorkspaceID string    `gorm:"type:char(36);not null;index" json:"workspaceId"`
+	MessageID   string    `gorm:"not null;uniqueIndex:idx_inbound_message" json:"messageId"`
+	From        string    `json:"from"`
+	Subject     string    `json:"subject"`
+	Result      string    `gorm:"not null" json:"result"` // processing, task, comment, rejected
+	TaskID      *string   `gorm:"type:char(36)" json:"taskId"`
+	CommentID   *string   `gorm:"type:char(36)" json:"commentId"`
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `gorm:"index" json:"createdAt"`
+}
+
+func (e *InboundEmail) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IncomingWebhook lets an external system create tasks in a workspace as its own bot user.
+// Token cuma disimpen hash nya, TokenPrefix buat bantu ngenalin di UI
+type IncomingWebhook struct {
+	ID          string     `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID string     `gorm:"type:char(36);not null;index" json:"workspaceId"`
+	Name        string     `gorm:"not null" json:"name"`
+	TokenHash   string     `gorm:"not null;uniqueIndex" json:"-"`
+	TokenPrefix string     `gorm:"not null" json:"tokenPrefix"`
+	BotUserID   string     `gorm:"type:char(36);not null" json:"botUserId"`
+	BotUser     *User      `json:"botUser,omitempty" gorm:"foreignKey:BotUserID"`
+	CreatedByID string     `gorm:"type:char(36);not null" json:"createdById"`
+	LastUsedAt  *time.Time `json:"lastUsedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func (w *IncomingWebhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IntegrationTask is the audit trail of which integration created which task
+type IntegrationTask struct {
+	ID                string    `gorm:"type:char(36);primary_key" json:"id"`
+	IncomingWebhookID string    `gorm:"type:char(36);not null;index" json:"incomingWebhookId"`
+	WorkspaceID       string    `gorm:"type:char(36);not null;index" json:"workspaceId"`
+	TaskID            string    `gorm:"type:char(36);not null;index" json:"taskId"`
+	Task              *Task     `json:"task,omitempty" gorm:"foreignKey:TaskID"`
+	Payload           string    `gorm:"type:text" json:"payload"`
+	SourceIP          string    `json:"sourceIp"`
+	CreatedAt         time.Time `gorm:"index" json:"createdAt"`
+}
+
+func (t *IntegrationTask) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	MentionSourceComment = "comment"
+	MentionSourceTask    = "task"
+)
+
+// Mention links one user to one comment or task description. Satu user cuma sekali per sumber,
+// dijaga unique index biar sync yg barengan gk bikin dobel (dan notif dobel)
+type Mention struct {
+	ID              string         `gorm:"type:char(36);primary_key" json:"id"`
+	MentionedUserID string         `gorm:"type:char(36);not null;index;uniqueIndex:idx_mention_comment_user,where:deleted_at IS NULL;uniqueIndex:idx_mention_task_user,where:comment_id IS NULL AND deleted_at IS NULL" json:"mentionedUserId"`
+	AuthorID        string         `gorm:"type:char(36);not null" json:"authorId"`
+	Author          User           `json:"author" gorm:"foreignKey:AuthorID"`
+	SourceType      string         `gorm:"not null" json:"sourceType"`
+	TaskID          string         `gorm:"type:char(36);not null;index;uniqueIndex:idx_mention_task_user,priority:1" json:"taskId"`
+	Task            *Task          `json:"task,omitempty" gorm:"foreignKey:TaskID"`
+	CommentID       *string        `gorm:"type:char(36);index;uniqueIndex:idx_mention_comment_user,priority:1" json:"commentId"`
+	Comment         *Comment       `json:"comment,omitempty" gorm:"foreignKey:CommentID"`
+	WorkspaceID     string         `gorm:"type:char(36);not null;index" json:"workspaceId"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (m *Mention) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	NotificationTaskAssigned     = "task_assigned"
+	NotificationMentioned        = "mentioned"
+	NotificationTaskCommented    = "task_commented"
+	NotificationWorkspaceRemoved = "workspace_removed"
+	NotificationPlanExpiring     = "plan_expiring"
+	NotificationTaskDueSoon      = "task_due_soon"
+	NotificationTaskUpdated      = "task_updated"
+	// email only, opt-in
+	NotificationDailyDigest = "daily_digest"
+)
+
+// NotificationTypes is every type a user can set preferences for
+var NotificationTypes = []string{
+	NotificationTaskAssigned,
+	NotificationMentioned,
+	NotificationTaskCommented,
+	NotificationWorkspaceRemoved,
+	NotificationPlanExpiring,
+	NotificationTaskDueSoon,
+	NotificationTaskUpdated,
+	NotificationDailyDigest,
+}
+
+type Notification struct {
+	ID          string     `gorm:"type:char(36);primary_key" json:"id"`
+	UserID      string     `gorm:"type:char(36);not null;index:idx_notification_user_created;uniqueIndex:idx_notification_dedupe" json:"userId"`
+	Type        string     `gorm:"not null" json:"type"`
+	ActorID     *string    `gorm:"type:char(36)" json:"actorId"`
+	Actor       *User      `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	WorkspaceID *string    `gorm:"type:char(36)" json:"workspaceId"`
+	TaskID      *string    `gorm:"type:char(36)" json:"taskId"`
+	CommentID   *string    `gorm:"type:char(36)" json:"commentId"`
+	Title       string     `gorm:"not null" json:"title"`
+	Body        string     `json:"body"`
+	DedupeKey   *string    `gorm:"uniqueIndex:idx_notification_dedupe" json:"-"` // null = gk di-dedupe
+	Hidden      bool       `gorm:"not null;default:false" json:"-"`              // email-only, cuma buat dedupe, gk muncul di inbox
+	ReadAt      *time.Time `json:"readAt"`
+	Read        bool       `gorm:"-" json:"read"`
+	CreatedAt   time.Time  `gorm:"index:idx_notification_user_created" json:"createdAt"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (n *Notification) AfterFind(tx *gorm.DB) error {
+	n.Read = n.ReadAt != nil
+	return nil
+}
+
+// NotificationPreference overrides the defaults for one notification type.
+// Email nil means "use the default for this type" (on, except the daily digest)
+type NotificationPreference struct {
+	ID        string    `gorm:"type:char(36);primary_key" json:"-"`
+	UserID    string    `gorm:"type:char(36);not null;uniqueIndex:idx_notification_pref" json:"-"`
+	Type      string    `gorm:"not null;uniqueIndex:idx_notification_pref" json:"type"`
+	InApp     bool      `gorm:"not null" json:"inApp"`
+	Email     *bool     `json:"email"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func DefaultEmailPreference(notificationType string) bool {
+	return notificationType != NotificationDailyDigest
+}
+
+func (p *NotificationPreference) EmailEnabled() bool {
+	if p.Email == nil {
+		return DefaultEmailPreference(p.Type)
+	}
+	return *p.Email
+}
+
+func (p *NotificationPreference) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
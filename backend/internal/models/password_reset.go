@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordResetToken is a single-use link from "forgot password", cuma hash nya yg disimpen
+type PasswordResetToken struct {
+	ID        string     `gorm:"type:char(36);primary_key" json:"id"`
+	UserID    string     `gorm:"type:char(36);not null;index" json:"userId"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+	RequestIP string     `json:"requestIp"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (t *PasswordResetToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
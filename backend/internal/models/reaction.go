@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ReactionTargetComment = "comment"
+	ReactionTargetTask    = "task"
+)
+
+type Reaction struct {
+	ID         string    `gorm:"type:char(36);primary_key" json:"id"`
+	TargetType string    `gorm:"not null;uniqueIndex:idx_reaction_unique" json:"targetType"`
+	TargetID   string    `gorm:"type:char(36);not null;uniqueIndex:idx_reaction_unique" json:"targetId"`
+	UserID     string    `gorm:"type:char(36);not null;uniqueIndex:idx_reaction_unique" json:"userId"`
+	Emoji      string    `gorm:"not null;uniqueIndex:idx_reaction_unique" json:"emoji"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ReactionSummary itu hasil aggregate per emoji, gk disimpen di db
+type ReactionSummary struct {
+	Emoji       string `json:"emoji"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reactedByMe"`
+}
+
+func (r *Reaction) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
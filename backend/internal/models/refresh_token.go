@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken is one link in a rotation chain. Tiap login bikin family baru (= Session), tiap
+// refresh token lama ditandai UsedAt & diganti token baru di family yg sama. Token yg udah dipake
+// muncul lagi = bocor, satu family langsung di-revoke
+type RefreshToken struct {
+	ID        string     `gorm:"type:char(36);primary_key" json:"id"`
+	UserID    string     `gorm:"type:char(36);not null;index" json:"userId"`
+	FamilyID  string     `gorm:"type:char(36);not null;index" json:"familyId"` // Session.ID
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultReminderWindows is used until a user picks their own: one reminder a day before the due date
+var DefaultReminderWindows = MinuteList{24 * 60}
+
+// MinuteList is a list of durations in minutes, stored as jsonb
+type MinuteList []int
+
+func (l MinuteList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *MinuteList) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("unsupported type for MinuteList")
+	}
+	return json.Unmarshal(raw, l)
+}
+
+// TaskReminder is a reminder one user set on one task. Either relative to the due date
+// (OffsetMinutes, "2 hours before") or a one-off at RemindAt (snoozed reminders).
+// RemindAt nil = task belum punya due date, jadi gk bakal bunyi
+type TaskReminder struct {
+	ID            string     `gorm:"type:char(36);primary_key" json:"id"`
+	TaskID        string     `gorm:"type:char(36);not null;index" json:"taskId"`
+	UserID        string     `gorm:"type:char(36);not null;index" json:"userId"`
+	OffsetMinutes *int       `json:"offsetMinutes"`
+	RemindAt      *time.Time `gorm:"index" json:"remindAt"`
+	SentAt        *time.Time `json:"sentAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+func (r *TaskReminder) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Schedule sets RemindAt from the task due date for relative reminders and re-arms it
+func (r *TaskReminder) Schedule(dueDate *time.Time) {
+	if r.OffsetMinutes == nil {
+		return
+	}
+	if dueDate == nil {
+		r.RemindAt = nil
+	} else {
+		remindAt := dueDate.Add(-time.Duration(*r.OffsetMinutes) * time.Minute)
+		r.RemindAt = &remindAt
+	}
+	r.SentAt = nil
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// SchemaMigration is a one-off data step (backfill) that has to run after AutoMigrate. Di-queue pas
+// schema nya baru berubah, AppliedAt diisi kalo udah sukses; gagal = dicoba lagi pas start berikutnya
+type SchemaMigration struct {
+	Name      string     `gorm:"primaryKey" json:"name"`
+	AppliedAt *time.Time `json:"appliedAt"`
+	CreatedAt time.Time  `json:"createdAt"` // data yg dibikin setelah ini udah ikut aturan baru, gk perlu di-backfill
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session is one login on one device. ID nya dipake jadi FamilyID refresh token & "sid" di access token,
+// jadi revoke session = refresh token nya mati & access token nya langsung ditolak middleware
+type Session struct {
+	ID         string     `gorm:"type:char(36);primary_key" json:"id"`
+	UserID     string     `gorm:"type:char(36);not null;index" json:"-"`
+	UserAgent  string     `json:"userAgent"`
+	Device     string     `gorm:"-" json:"device"` // e.g. "Chrome on macOS", dari UserAgent
+	IP         string     `json:"ip"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expiresAt"` // expiry refresh token terakhir
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `gorm:"-" json:"current"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
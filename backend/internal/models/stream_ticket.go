@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StreamTicket lets an EventSource connect without putting the access token in the url. Umurnya
+// beberapa detik & sekali pakai, jadi kalo ke-log di proxy juga udah gk ada gunanya
+type StreamTicket struct {
+	ID        string    `gorm:"type:char(36);primary_key" json:"id"`
+	UserID    string    `gorm:"type:char(36);not null;index" json:"userId"`
+	Username  string    `gorm:"not null" json:"username"`
+	SessionID string    `gorm:"type:char(36)" json:"sessionId"`
+	TokenHash string    `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`
+	// AccessExpiresAt is the expiry of the access token the ticket was issued with, stream nya ikut
+	// ditutup pas lewat biar gk lebih lama dari token nya
+	AccessExpiresAt time.Time `gorm:"not null" json:"accessExpiresAt"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+func (t *StreamTicket) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
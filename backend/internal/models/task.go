@@ -1,9 +1,10 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
-	"time"
 )
 
 const (
@@ -13,23 +14,29 @@ const (
 )
 
 type Task struct {
-	ID          string `gorm:"type:char(36);primary_key" json:"id"`
-	Title       string `gorm:"not null" json:"title"`
-	Description string `json:"description"`
-	Status      string `gorm:"default:'not_started'" json:"status"`
-	Order       int    `gorm:"default:0" json:"order"`
-	UserID      string `gorm:"type:char(36);not null;index" json:"userId"`
-	User        User   `json:"user" gorm:"foreignKey:UserID"`
-
-	WorkspaceID *string    `gorm:"type:char(36);index" json:"workspaceId"`
+	ID              string `gorm:"type:char(36);primary_key" json:"id"`
+	Title           string `gorm:"not null" json:"title"`
+	Description     string `json:"description"`
+	DescriptionHTML string `gorm:"-" json:"descriptionHtml"`
+	Status          string `gorm:"default:'not_started'" json:"status"`
+	Order           int    `gorm:"default:0" json:"order"`
+	UserID          string `gorm:"type:char(36);not null;index" json:"userId"`
+	User            User   `json:"user" gorm:"foreignKey:UserID"`
+
+	WorkspaceID *string    `gorm:"type:char(36);index;uniqueIndex:idx_task_workspace_number" json:"workspaceId"`
 	Workspace   *Workspace `json:"workspace,omitempty" gorm:"foreignKey:WorkspaceID"`
 	AssigneeID  *string    `gorm:"type:char(36);index" json:"assigneeId"`
 	Assignee    *User      `json:"assignee,omitempty" gorm:"foreignKey:AssigneeID"`
+	DueDate     *time.Time `gorm:"index" json:"dueDate"`
+	Labels      StringList `gorm:"type:jsonb" json:"labels"`
+	Number      *int       `gorm:"uniqueIndex:idx_task_workspace_number" json:"number"`
+	Key         string     `gorm:"index" json:"key"` // e.g. WS-42, kosong buat personal task
 
-	Comments  []Comment      `json:"comments,omitempty"`
-	CreatedAt time.Time      `json:"createdAt"`
-	UpdatedAt time.Time      `json:"updatedAt"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	Comments  []Comment         `json:"comments,omitempty"`
+	Reactions []ReactionSummary `gorm:"-" json:"reactions"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt    `gorm:"index" json:"-"`
 }
 
 func (u *Task) BeforeCreate(tx *gorm.DB) error { // kalo disini fungsi before create itu buat bikin unique uuid
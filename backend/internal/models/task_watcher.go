@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskWatcher is someone who gets notified about a task without owning or being assigned to it
+type TaskWatcher struct {
+	ID        string    `gorm:"type:char(36);primary_key" json:"-"`
+	TaskID    string    `gorm:"type:char(36);not null;uniqueIndex:idx_task_watcher" json:"taskId"`
+	UserID    string    `gorm:"type:char(36);not null;uniqueIndex:idx_task_watcher;index" json:"userId"`
+	User      *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (w *TaskWatcher) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -8,16 +8,26 @@ import (
 )
 
 type User struct {
-	ID            string         `gorm:"type:char(36);primary_key" json:"id"`
-	Username      string         `gorm:"uniqueIndex;not null" json:"username"`
-	Email         string         `gorm:"uniqueIndex;not null" json:"email"`
-	Password      string         `gorm:"not null" json:"-"`
-	CreatedAt     time.Time      `json:"createdAt"`
-	UpdatedAt     time.Time      `json:"updatedAt"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
-	Tasks         []Task         `json:"tasks,omitempty"`
-	Plan          string         `gorm:"default:'free'" json:"plan"`
-	PlanExpiresAt *time.Time     `json:"planExpiresAt"`
+	ID              string         `gorm:"type:char(36);primary_key" json:"id"`
+	Username        string         `gorm:"uniqueIndex;not null" json:"username"`
+	Email           string         `gorm:"uniqueIndex;not null" json:"email"`
+	Password        string         `gorm:"not null" json:"-"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+	Tasks           []Task         `json:"tasks,omitempty"`
+	Plan            string         `gorm:"default:'free'" json:"plan"`
+	PlanExpiresAt   *time.Time     `json:"planExpiresAt"`
+	LastDigestAt    *time.Time     `json:"-"`
+	ReminderWindows MinuteList     `gorm:"type:jsonb" json:"-"` // nil = DefaultReminderWindows
+	IsBot           bool           `gorm:"not null;default:false" json:"isBot"`
+	EmailVerifiedAt *time.Time     `json:"emailVerifiedAt"` // nil = belum klik link verifikasi
+}
+
+// EmailVerified tells whether the user proved they own Email. Akun yg belum verified gk boleh
+// join workspace / checkout, dan gk pernah dicocokin lewat email (import, restore, inbound email)
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error { // kalo disini fungsi before create itu buat bikin unique uuid
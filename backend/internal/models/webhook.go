@@ -0,0 +1,93 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// StringList is a list of strings stored as jsonb
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*l = StringList{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("unsupported type for StringList")
+	}
+	return json.Unmarshal(raw, l)
+}
+
+// Webhook is an outgoing endpoint a workspace owner registered. Events kosong = semua event
+type Webhook struct {
+	ID          string     `gorm:"type:char(36);primary_key" json:"id"`
+	WorkspaceID string     `gorm:"type:char(36);not null;index" json:"workspaceId"`
+	CreatedByID string     `gorm:"type:char(36);not null" json:"createdById"`
+	URL         string     `gorm:"not null" json:"url"`
+	Secret      string     `gorm:"not null" json:"-"`
+	Events      StringList `gorm:"type:jsonb" json:"events"`
+	Active      bool       `gorm:"not null" json:"active"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// WebhookDelivery is one event sent (or being retried) to one webhook, doubles as the delivery log
+type WebhookDelivery struct {
+	ID             string     `gorm:"type:char(36);primary_key" json:"id"`
+	WebhookID      string     `gorm:"type:char(36);not null;index" json:"webhookId"`
+	EventID        string     `gorm:"not null" json:"eventId"`
+	EventType      string     `gorm:"not null" json:"eventType"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"not null;index" json:"status"`
+	Attempts       int        `gorm:"not null" json:"attempts"`
+	NextAttemptAt  *time.Time `gorm:"index" json:"nextAttemptAt"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt"`
+	ResponseStatus int        `json:"responseStatus"`
+	ResponseBody   string     `gorm:"type:text" json:"responseBody"`
+	Error          string     `json:"error"`
+	DurationMs     int64      `json:"durationMs"`
+	RedeliveryOfID *string    `gorm:"type:char(36)" json:"redeliveryOfId"`
+	CreatedAt      time.Time  `gorm:"index" json:"createdAt"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
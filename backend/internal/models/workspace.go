@@ -3,12 +3,15 @@ package models
 import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"strings"
 	"time"
 )
 
 const (
 	RoleOwner  = "owner"
 	RoleMember = "member"
+	// RoleBot is the identity of an integration (incoming webhook, git), can create tasks and move them but nothing else
+	RoleBot = "bot"
 )
 
 type Workspace struct {
@@ -19,6 +22,8 @@ type Workspace struct {
 	Owner         User              `json:"owner" gorm:"foreignKey:OwnerID"`
 	InviteCode    string            `gorm:"uniqueIndex;not null" json:"inviteCode"`
 	InviteExpires *time.Time        `json:"inviteExpiresAt"`
+	KeyPrefix     string            `json:"keyPrefix"`                   // task key prefix, e.g. "WS" -> WS-42
+	TaskCounter   int               `gorm:"not null;default:0" json:"-"` // last task number handed out
 	Members       []WorkspaceMember `json:"members,omitempty" gorm:"foreignKey:WorkspaceID"`
 	Tasks         []Task            `json:"tasks,omitempty" gorm:"foreignKey:WorkspaceID"`
 	CreatedAt     time.Time         `json:"ceatedAt"`
@@ -43,9 +48,43 @@ func (w *Workspace) BeforeCreate(tx *gorm.DB) error {
 	if w.InviteCode == "" {
 		w.InviteCode = GenerateInviteCode()
 	}
+	if w.KeyPrefix == "" {
+		w.KeyPrefix = GenerateKeyPrefix(w.Name)
+	}
 	return nil
 }
 
+// GenerateKeyPrefix makes a task key prefix from the workspace name: initials for multi word
+// names ("Marketing Team" -> "MT"), first 3 letters otherwise ("Backend" -> "BAC")
+func GenerateKeyPrefix(name string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		words = append(words, word)
+	}
+
+	prefix := ""
+	if len(words) > 1 {
+		for _, word := range words {
+			prefix += word[:1]
+		}
+	} else if len(words) == 1 {
+		prefix = words[0]
+		if len(prefix) > 3 {
+			prefix = prefix[:3]
+		}
+	}
+	if len(prefix) > 5 {
+		prefix = prefix[:5]
+	}
+	// key harus diawali huruf & minimal 2 char biar gampang di parse
+	if len(prefix) < 2 || prefix[0] < 'A' || prefix[0] > 'Z' {
+		return "WS"
+	}
+	return prefix
+}
+
 func GenerateInviteCode() string {
 	// Uses uuid but takes only first 8 chars
 	id := uuid.New().String()
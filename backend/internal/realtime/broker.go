@@ -0,0 +1,48 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+)
+
+// Broker fans events out to every API instance. Publish sends an event to all instances
+// (including this one) and Subscribe delivers everything published, blocking until ctx is done.
+type Broker interface {
+	Publish(ctx context.Context, event Event) error
+	Subscribe(ctx context.Context, handle func(Event)) error
+}
+
+// MemoryBroker only reaches the current process, cukup buat single instance / dev
+type MemoryBroker struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]func(Event)
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{handlers: map[int]func(Event){}}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, handle := range b.handlers {
+		handle(event)
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, handle func(Event)) error {
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handle
+	b.mu.Unlock()
+
+	<-ctx.Done()
+
+	b.mu.Lock()
+	delete(b.handlers, id)
+	b.mu.Unlock()
+	return ctx.Err()
+}
@@ -0,0 +1,67 @@
+package realtime
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventTaskCreated      = "task.created"
+	EventTaskUpdated      = "task.updated"
+	EventTaskAssigned     = "task.assigned"
+	EventTaskDeleted      = "task.deleted"
+	EventCommentCreated   = "comment.created"
+	EventCommentUpdated   = "comment.updated"
+	EventCommentDeleted   = "comment.deleted"
+	EventMemberJoined     = "member.joined"
+	EventMemberRemoved    = "member.removed"
+	EventWorkspaceUpdated = "workspace.updated"
+	EventWorkspaceDeleted = "workspace.deleted"
+)
+
+// Event is one change on a workspace board pushed to every subscribed client
+type Event struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	WorkspaceID string          `json:"workspaceId"`
+	ActorID     string          `json:"actorId"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	At          time.Time       `json:"at"`
+}
+
+// MemberData is the payload of member.joined / member.removed
+type MemberData struct {
+	UserID string `json:"userId"`
+}
+
+func NewEvent(eventType, workspaceID, actorID string, data interface{}) Event {
+	event := Event{
+		ID:          uuid.New().String(),
+		Type:        eventType,
+		WorkspaceID: workspaceID,
+		ActorID:     actorID,
+		At:          time.Now(),
+	}
+	if data != nil {
+		if raw, err := json.Marshal(data); err == nil {
+			event.Data = raw
+		}
+	}
+	return event
+}
+
+// Publisher is what services use to emit events, implemented by Hub
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Publishers fans an event out to several publishers, e.g. the hub and outgoing webhooks
+type Publishers []Publisher
+
+func (p Publishers) Publish(event Event) {
+	for _, publisher := range p {
+		publisher.Publish(event)
+	}
+}
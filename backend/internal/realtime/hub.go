@@ -0,0 +1,138 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	clientBufferSize = 64
+	// publishQueueSize is how many events may wait for the broker before Publish starts dropping them
+	publishQueueSize = 1024
+)
+
+// Client is one open SSE connection of a user, subscribed to a set of workspaces
+type Client struct {
+	UserID string
+	Events chan Event
+
+	mu sync.Mutex
+	// followMemberships means the client asked for "all my workspaces", so joining a new
+	// workspace while connected subscribes to it automatically
+	followMemberships bool
+	workspaces        map[string]bool
+}
+
+func (c *Client) wants(event Event) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if event.Type == EventMemberJoined && c.followMemberships && memberUserID(event) == c.UserID {
+		c.workspaces[event.WorkspaceID] = true
+	}
+	if !c.workspaces[event.WorkspaceID] {
+		return false
+	}
+	// Still deliver the removal itself, then stop streaming that workspace
+	if (event.Type == EventMemberRemoved && memberUserID(event) == c.UserID) || event.Type == EventWorkspaceDeleted {
+		delete(c.workspaces, event.WorkspaceID)
+	}
+	return true
+}
+
+func memberUserID(event Event) string {
+	var data MemberData
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return ""
+	}
+	return data.UserID
+}
+
+// Hub keeps the connected clients of this instance and routes broker events to them
+type Hub struct {
+	broker Broker
+	queue  chan Event
+
+	mu      sync.RWMutex
+	clients map[*Client]bool
+}
+
+func NewHub(broker Broker) *Hub {
+	return &Hub{broker: broker, queue: make(chan Event, publishQueueSize), clients: map[*Client]bool{}}
+}
+
+// Run listens on the broker and sends queued events to it until ctx is cancelled, jalanin di goroutine sendiri
+func (h *Hub) Run(ctx context.Context) {
+	go h.drain(ctx)
+	if err := h.broker.Subscribe(ctx, h.dispatch); err != nil && ctx.Err() == nil {
+		log.Printf("[Hub] broker subscription ended: %v", err)
+	}
+}
+
+// Publish only queues the event, request nya gk nungguin broker (NOTIFY ke postgres) selesai
+func (h *Hub) Publish(event Event) {
+	select {
+	case h.queue <- event:
+	default:
+		log.Printf("[Hub] publish queue full, dropping %s", event.Type)
+	}
+}
+
+// drain hands queued events to the broker one by one, so they keep the order they were published in
+func (h *Hub) drain(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-h.queue:
+			publishCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			if err := h.broker.Publish(publishCtx, event); err != nil {
+				log.Printf("[Hub] failed to publish %s: %v", event.Type, err)
+			}
+			cancel()
+		}
+	}
+}
+
+func (h *Hub) Register(userID string, workspaceIDs []string, followMemberships bool) *Client {
+	client := &Client{
+		UserID:            userID,
+		Events:            make(chan Event, clientBufferSize),
+		followMemberships: followMemberships,
+		workspaces:        map[string]bool{},
+	}
+	for _, id := range workspaceIDs {
+		client.workspaces[id] = true
+	}
+
+	h.mu.Lock()
+	h.clients[client] = true
+	h.mu.Unlock()
+	return client
+}
+
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+}
+
+func (h *Hub) dispatch(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if !client.wants(event) {
+			continue
+		}
+		select {
+		case client.Events <- event:
+		default:
+			// client lambat, event di skip daripada nge-block semua orang
+			log.Printf("[Hub] dropping %s for slow client of user %s", event.Type, client.UserID)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingBroker holds every Publish until release is closed, kaya NOTIFY ke postgres yg lagi lemot
+type blockingBroker struct {
+	*MemoryBroker
+	release chan struct{}
+}
+
+func (b *blockingBroker) Publish(ctx context.Context, event Event) error {
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return b.MemoryBroker.Publish(ctx, event)
+}
+
+func TestHubPublishDoesNotWaitForBroker(t *testing.T) {
+	broker := &blockingBroker{MemoryBroker: NewMemoryBroker(), release: make(chan struct{})}
+	hub := NewHub(broker)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+	waitSubscribed(t, broker.MemoryBroker)
+
+	client := hub.Register("u1", []string{"ws1"}, false)
+	defer hub.Unregister(client)
+
+	published := make(chan struct{})
+	go func() {
+		hub.Publish(NewEvent(EventTaskCreated, "ws1", "u2", nil))
+		hub.Publish(NewEvent(EventTaskUpdated, "ws1", "u2", nil))
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on the broker")
+	}
+
+	close(broker.release)
+	for _, want := range []string{EventTaskCreated, EventTaskUpdated} {
+		select {
+		case event := <-client.Events:
+			if event.Type != want {
+				t.Errorf("event = %s, want %s", event.Type, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s never reached the client", want)
+		}
+	}
+}
+
+func waitSubscribed(t *testing.T, broker *MemoryBroker) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		broker.mu.RLock()
+		n := len(broker.handlers)
+		broker.mu.RUnlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("hub never subscribed to the broker")
+}
@@ -0,0 +1,88 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	notifyChannel = "minitask_events"
+	// postgres NOTIFY payload max 8000 bytes
+	maxNotifyPayload = 7900
+)
+
+// PostgresBroker shares events between API instances with LISTEN/NOTIFY
+type PostgresBroker struct {
+	db  *gorm.DB
+	dsn string
+}
+
+func NewPostgresBroker(db *gorm.DB, dsn string) *PostgresBroker {
+	return &PostgresBroker{db: db, dsn: dsn}
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// Too big for NOTIFY: drop the data, clients refetch the entity by ID
+	if len(payload) > maxNotifyPayload {
+		event.Data = nil
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", notifyChannel, string(payload)).Error
+}
+
+func (b *PostgresBroker) Subscribe(ctx context.Context, handle func(Event)) error {
+	backoff := time.Second
+	for {
+		err := b.listen(ctx, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("[PostgresBroker] listener stopped: %v, reconnecting in %s", err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (b *PostgresBroker) listen(ctx context.Context, handle func(Event)) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Printf("[PostgresBroker] dropping malformed event: %v", err)
+			continue
+		}
+		handle(event)
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"minitask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ActivityRepository interface {
+	Create(activity *models.Activity) error
+	FindByTaskID(taskID string, offset, limit int) ([]models.Activity, int64, error)
+	FindByWorkspaceID(workspaceID string, offset, limit int) ([]models.Activity, int64, error)
+	FindByActorID(actorID string, offset, limit int) ([]models.Activity, int64, error)
+}
+
+type activityRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRepository(db *gorm.DB) ActivityRepository {
+	return &activityRepository{db: db}
+}
+
+func (r *activityRepository) Create(activity *models.Activity) error {
+	return r.db.Create(activity).Error
+}
+
+func (r *activityRepository) FindByTaskID(taskID string, offset, limit int) ([]models.Activity, int64, error) {
+	return r.findPage(whereScope("task_id = ?", taskID), offset, limit)
+}
+
+func (r *activityRepository) FindByWorkspaceID(workspaceID string, offset, limit int) ([]models.Activity, int64, error) {
+	return r.findPage(whereScope("workspace_id = ?", workspaceID), offset, limit)
+}
+
+func (r *activityRepository) FindByActorID(actorID string, offset, limit int) ([]models.Activity, int64, error) {
+	return r.findPage(whereScope("actor_id = ?", actorID), offset, limit)
+}
+
+func whereScope(condition string, args ...interface{}) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(condition, args...)
+	}
+}
+
+func (r *activityRepository) findPage(scope func(db *gorm.DB) *gorm.DB, offset, limit int) ([]models.Activity, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.Activity{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var activities []models.Activity
+	err := r.db.Scopes(scope).
+		Preload("Actor").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&activities).Error
+	return activities, total, err
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"minitask/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	FindByID(id string) (*models.Attachment, error)
+	FindByTaskID(taskID string) ([]models.Attachment, error)
+	FindByWorkspaceID(workspaceID string) ([]models.Attachment, error)
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(attachment *models.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *attachmentRepository) FindByID(id string) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.First(&attachment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) FindByTaskID(taskID string) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.Where("task_id = ?", taskID).Order("created_at ASC").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) FindByWorkspaceID(workspaceID string) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.
+		Joins("JOIN tasks ON tasks.id = attachments.task_id AND tasks.deleted_at IS NULL").
+		Where("tasks.workspace_id = ?", workspaceID).
+		Order("attachments.created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
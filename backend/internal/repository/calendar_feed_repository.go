@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CalendarFeedRepository interface {
+	Create(feed *models.CalendarFeed) error
+	FindByID(id, userID string) (*models.CalendarFeed, error)
+	FindByScope(userID string, workspaceID *string) (*models.CalendarFeed, error)
+	FindByTokenHash(hash string) (*models.CalendarFeed, error)
+	FindAllByUserID(userID string) ([]models.CalendarFeed, error)
+	Update(feed *models.CalendarFeed) error
+	TouchLastAccessed(id string, at time.Time) error
+	Delete(id string) error
+}
+
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+func (r *calendarFeedRepository) Create(feed *models.CalendarFeed) error {
+	return r.db.Create(feed).Error
+}
+
+func (r *calendarFeedRepository) FindByID(id, userID string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.First(&feed, "id = ? AND user_id = ?", id, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// FindByScope finds the personal feed (workspaceID nil) or the one for a workspace
+func (r *calendarFeedRepository) FindByScope(userID string, workspaceID *string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	query := r.db.Where("user_id = ?", userID)
+	if workspaceID == nil {
+		query = query.Where("workspace_id IS NULL")
+	} else {
+		query = query.Where("workspace_id = ?", *workspaceID)
+	}
+	if err := query.First(&feed).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *calendarFeedRepository) FindByTokenHash(hash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.First(&feed, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *calendarFeedRepository) FindAllByUserID(userID string) ([]models.CalendarFeed, error) {
+	var feeds []models.CalendarFeed
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&feeds).Error
+	return feeds, err
+}
+
+func (r *calendarFeedRepository) Update(feed *models.CalendarFeed) error {
+	return r.db.Save(feed).Error
+}
+
+func (r *calendarFeedRepository) TouchLastAccessed(id string, at time.Time) error {
+	return r.db.Model(&models.CalendarFeed{}).Where("id = ?", id).Update("last_accessed_at", at).Error
+}
+
+func (r *calendarFeedRepository) Delete(id string) error {
+	return r.db.Delete(&models.CalendarFeed{}, "id = ?", id).Error
+}
@@ -12,7 +12,10 @@ type CommentRepository interface {
 	FindByIDAndUserID(id, userID string) (*models.Comment, error)
 	FindAllByTaskID(taskID string) ([]models.Comment, error)
 	Update(comment *models.Comment) error
+	UpdateWithRevision(comment *models.Comment, revision *models.CommentRevision) error
+	FindRevisions(commentID string) ([]models.CommentRevision, error)
 	Delete(id, userID string) error
+	DeleteByID(id string) error
 }
 
 type commentRepository struct {
@@ -65,10 +68,41 @@ func (r *commentRepository) Update(comment *models.Comment) error {
 	return r.db.Save(comment).Error
 }
 
+// UpdateWithRevision saves the edited comment and the snapshot of its previous content atomically
+func (r *commentRepository) UpdateWithRevision(comment *models.Comment, revision *models.CommentRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		return tx.Save(comment).Error
+	})
+}
+
+func (r *commentRepository) FindRevisions(commentID string) ([]models.CommentRevision, error) {
+	var revisions []models.CommentRevision
+	err := r.db.Preload("EditedBy").Where("comment_id = ?", commentID).Order("created_at ASC").Find(&revisions).Error
+	return revisions, err
+}
+
 func (r *commentRepository) Delete(id, userID string) error {
-	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Comment{})
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return result.Error
+	return r.deleteWithReactions(id, "id = ? AND user_id = ?", id, userID)
+}
+
+func (r *commentRepository) DeleteByID(id string) error {
+	return r.deleteWithReactions(id, "id = ?", id)
+}
+
+// deleteWithReactions removes the comment and its reactions together, reaction gk punya FK ke
+// comment jadi harus dibersihin manual biar gk numpuk
+func (r *commentRepository) deleteWithReactions(id string, query string, args ...interface{}) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where(query, args...).Delete(&models.Comment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("target_type = ? AND target_id = ?", models.ReactionTargetComment, id).Delete(&models.Reaction{}).Error
+	})
 }
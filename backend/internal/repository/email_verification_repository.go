@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepository interface {
+	Create(token *models.EmailVerificationToken) error
+	FindByTokenHash(hash string) (*models.EmailVerificationToken, error)
+	FindLatestByUserID(userID string) (*models.EmailVerificationToken, error)
+	CountSince(userID string, since time.Time) (int64, error)
+	MarkUsed(id string, at time.Time) (bool, error)
+	DeleteExpired(before time.Time) error
+}
+
+type emailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+func (r *emailVerificationRepository) Create(token *models.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *emailVerificationRepository) FindByTokenHash(hash string) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	err := r.db.First(&token, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *emailVerificationRepository) FindLatestByUserID(userID string) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CountSince counts links sent to the user since a point in time, buat batas resend per hari
+func (r *emailVerificationRepository) CountSince(userID string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *emailVerificationRepository) MarkUsed(id string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpired keeps recent rows a day longer than the link itself, CountSince masih butuh
+func (r *emailVerificationRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before.Add(-24*time.Hour)).Delete(&models.EmailVerificationToken{}).Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GitIntegrationRepository interface {
+	Create(integration *models.GitIntegration) error
+	FindByWorkspaceID(workspaceID string) (*models.GitIntegration, error)
+	Update(integration *models.GitIntegration) error
+	TouchLastEvent(id string, at time.Time) error
+	Delete(id string) error
+
+	UpsertLink(link *models.TaskLink) error
+	FindLinksByTaskID(taskID string) ([]models.TaskLink, error)
+}
+
+type gitIntegrationRepository struct {
+	db *gorm.DB
+}
+
+func NewGitIntegrationRepository(db *gorm.DB) GitIntegrationRepository {
+	return &gitIntegrationRepository{db: db}
+}
+
+func (r *gitIntegrationRepository) Create(integration *models.GitIntegration) error {
+	return r.db.Create(integration).Error
+}
+
+func (r *gitIntegrationRepository) FindByWorkspaceID(workspaceID string) (*models.GitIntegration, error) {
+	var integration models.GitIntegration
+	err := r.db.First(&integration, "workspace_id = ?", workspaceID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &integration, nil
+}
+
+func (r *gitIntegrationRepository) Update(integration *models.GitIntegration) error {
+	return r.db.Save(integration).Error
+}
+
+func (r *gitIntegrationRepository) TouchLastEvent(id string, at time.Time) error {
+	return r.db.Model(&models.GitIntegration{}).Where("id = ?", id).Update("last_event_at", at).Error
+}
+
+// Delete keeps existing task links, commit yg udah ke-link tetep keliatan di task
+func (r *gitIntegrationRepository) Delete(id string) error {
+	return r.db.Delete(&models.GitIntegration{}, "id = ?", id).Error
+}
+
+// UpsertLink refreshes title/state when the same commit or PR is delivered again (PR edited, merged, ...)
+func (r *gitIntegrationRepository) UpsertLink(link *models.TaskLink) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "task_id"}, {Name: "provider"}, {Name: "repository"}, {Name: "type"}, {Name: "external_id"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"url", "title", "author", "state", "closes", "updated_at"}),
+	}).Create(link).Error
+}
+
+func (r *gitIntegrationRepository) FindLinksByTaskID(taskID string) ([]models.TaskLink, error) {
+	var links []models.TaskLink
+	err := r.db.Where("task_id = ?", taskID).Order("created_at DESC").Find(&links).Error
+	return links, err
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"minitask/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InboundMailboxRepository interface {
+	Create(mailbox *models.InboundMailbox) error
+	FindByWorkspaceID(workspaceID string) (*models.InboundMailbox, error)
+	FindByLocalPart(localPart string) (*models.InboundMailbox, error)
+	Delete(id string) error
+
+	ClaimEmail(email *models.InboundEmail) (bool, error)
+	UpdateEmail(email *models.InboundEmail) error
+	DeleteEmail(id string) error
+	FindEmails(workspaceID string, offset, limit int) ([]models.InboundEmail, int64, error)
+}
+
+type inboundMailboxRepository struct {
+	db *gorm.DB
+}
+
+func NewInboundMailboxRepository(db *gorm.DB) InboundMailboxRepository {
+	return &inboundMailboxRepository{db: db}
+}
+
+func (r *inboundMailboxRepository) Create(mailbox *models.InboundMailbox) error {
+	return r.db.Create(mailbox).Error
+}
+
+func (r *inboundMailboxRepository) FindByWorkspaceID(workspaceID string) (*models.InboundMailbox, error) {
+	var mailbox models.InboundMailbox
+	err := r.db.First(&mailbox, "workspace_id = ?", workspaceID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mailbox, nil
+}
+
+func (r *inboundMailboxRepository) FindByLocalPart(localPart string) (*models.InboundMailbox, error) {
+	var mailbox models.InboundMailbox
+	err := r.db.First(&mailbox, "local_part = ?", localPart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mailbox, nil
+}
+
+// Delete keeps the email log, task yg udah dibikin dari email tetep bisa ditelusuri
+func (r *inboundMailboxRepository) Delete(id string) error {
+	return r.db.Delete(&models.InboundMailbox{}, "id = ?", id).Error
+}
+
+// ClaimEmail inserts the log row before the message is processed. False = message id nya udah
+// pernah masuk (atau lagi diproses request lain), unique index nya yg jagain
+func (r *inboundMailboxRepository) ClaimEmail(email *models.InboundEmail) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(email)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *inboundMailboxRepository) UpdateEmail(email *models.InboundEmail) error {
+	return r.db.Save(email).Error
+}
+
+func (r *inboundMailboxRepository) DeleteEmail(id string) error {
+	return r.db.Delete(&models.InboundEmail{}, "id = ?", id).Error
+}
+
+func (r *inboundMailboxRepository) FindEmails(workspaceID string, offset, limit int) ([]models.InboundEmail, int64, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("workspace_id = ?", workspaceID)
+	}
+
+	var total int64
+	if err := r.db.Model(&models.InboundEmail{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var emails []models.InboundEmail
+	err := r.db.Scopes(scope).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&emails).Error
+	return emails, total, err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IncomingWebhookRepository interface {
+	Create(webhook *models.IncomingWebhook) error
+	FindByID(id, workspaceID string) (*models.IncomingWebhook, error)
+	FindByTokenHash(hash string) (*models.IncomingWebhook, error)
+	FindAllByWorkspaceID(workspaceID string) ([]models.IncomingWebhook, error)
+	UpdateToken(id, hash, prefix string) error
+	TouchLastUsed(id string, at time.Time) error
+	Delete(id string) error
+
+	CreateIntegrationTask(record *models.IntegrationTask) error
+	FindIntegrationTasks(webhookID string, offset, limit int) ([]models.IntegrationTask, int64, error)
+}
+
+type incomingWebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewIncomingWebhookRepository(db *gorm.DB) IncomingWebhookRepository {
+	return &incomingWebhookRepository{db: db}
+}
+
+func (r *incomingWebhookRepository) Create(webhook *models.IncomingWebhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *incomingWebhookRepository) FindByID(id, workspaceID string) (*models.IncomingWebhook, error) {
+	var webhook models.IncomingWebhook
+	err := r.db.Preload("BotUser").First(&webhook, "id = ? AND workspace_id = ?", id, workspaceID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *incomingWebhookRepository) FindByTokenHash(hash string) (*models.IncomingWebhook, error) {
+	var webhook models.IncomingWebhook
+	err := r.db.First(&webhook, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *incomingWebhookRepository) FindAllByWorkspaceID(workspaceID string) ([]models.IncomingWebhook, error) {
+	var webhooks []models.IncomingWebhook
+	err := r.db.Preload("BotUser").Where("workspace_id = ?", workspaceID).Order("created_at ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *incomingWebhookRepository) UpdateToken(id, hash, prefix string) error {
+	return r.db.Model(&models.IncomingWebhook{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"token_hash": hash, "token_prefix": prefix}).Error
+}
+
+func (r *incomingWebhookRepository) TouchLastUsed(id string, at time.Time) error {
+	return r.db.Model(&models.IncomingWebhook{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// Delete keeps the audit rows, biar tetep keliatan task mana yg dulu dibikin integrasi ini
+func (r *incomingWebhookRepository) Delete(id string) error {
+	return r.db.Delete(&models.IncomingWebhook{}, "id = ?", id).Error
+}
+
+func (r *incomingWebhookRepository) CreateIntegrationTask(record *models.IntegrationTask) error {
+	return r.db.Create(record).Error
+}
+
+func (r *incomingWebhookRepository) FindIntegrationTasks(webhookID string, offset, limit int) ([]models.IntegrationTask, int64, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("incoming_webhook_id = ?", webhookID)
+	}
+
+	var total int64
+	if err := r.db.Model(&models.IntegrationTask{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var records []models.IntegrationTask
+	err := r.db.Scopes(scope).
+		Preload("Task").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&records).Error
+	return records, total, err
+}
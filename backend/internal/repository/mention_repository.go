@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"minitask/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MentionRepository interface {
+	Create(mention *models.Mention) (bool, error)
+	FindAllBySource(sourceType, taskID string, commentID *string) ([]models.Mention, error)
+	FindAllByMentionedUserID(userID string) ([]models.Mention, error)
+	Delete(id string) error
+	DeleteBySource(sourceType, taskID string, commentID *string) error
+	RemoveDuplicates() error
+}
+
+type mentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) MentionRepository {
+	return &mentionRepository{db: db}
+}
+
+// sourceScope narrows a query to the mentions of one comment or one task description
+func sourceScope(sourceType, taskID string, commentID *string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if sourceType == models.MentionSourceComment && commentID != nil {
+			return db.Where("source_type = ? AND comment_id = ?", sourceType, *commentID)
+		}
+		return db.Where("source_type = ? AND task_id = ? AND comment_id IS NULL", sourceType, taskID)
+	}
+}
+
+// Create reports whether the mention is new, false kalo sync lain udah nyimpen yg sama duluan
+func (r *mentionRepository) Create(mention *models.Mention) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(mention)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *mentionRepository) FindAllBySource(sourceType, taskID string, commentID *string) ([]models.Mention, error) {
+	var mentions []models.Mention
+	err := r.db.Scopes(sourceScope(sourceType, taskID, commentID)).Find(&mentions).Error
+	return mentions, err
+}
+
+func (r *mentionRepository) FindAllByMentionedUserID(userID string) ([]models.Mention, error) {
+	var mentions []models.Mention
+	err := r.db.
+		Preload("Author").
+		Preload("Task").
+		Preload("Comment").
+		Joins("JOIN tasks t ON t.id = mentions.task_id AND t.deleted_at IS NULL").
+		Scopes(currentMemberTasks("t", userID)).
+		Where("mentions.mentioned_user_id = ?", userID).
+		Order("mentions.created_at DESC").
+		Find(&mentions).Error
+	return mentions, err
+}
+
+func (r *mentionRepository) Delete(id string) error {
+	return r.db.Delete(&models.Mention{}, "id = ?", id).Error
+}
+
+func (r *mentionRepository) DeleteBySource(sourceType, taskID string, commentID *string) error {
+	return r.db.Scopes(sourceScope(sourceType, taskID, commentID)).Delete(&models.Mention{}).Error
+}
+
+// RemoveDuplicates soft deletes all but the oldest copy of a mention, has to run before the
+// unique indexes can be created on a table that already has doubles
+func (r *mentionRepository) RemoveDuplicates() error {
+	return r.db.Exec(`
+		UPDATE mentions SET deleted_at = NOW()
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (
+					PARTITION BY source_type, task_id, comment_id, mentioned_user_id ORDER BY created_at, id
+				) AS n
+				FROM mentions WHERE deleted_at IS NULL
+			) d WHERE d.n > 1
+		)`).Error
+}
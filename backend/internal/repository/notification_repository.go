@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	Create(notification *models.Notification) (bool, error)
+	FindByID(id, userID string) (*models.Notification, error)
+	FindByUserID(userID string, unreadOnly bool, offset, limit int) ([]models.Notification, int64, error)
+	CountUnread(userID string) (int64, error)
+	SetReadAt(id, userID string, readAt *time.Time) error
+	MarkAllRead(userID string) error
+
+	FindPreferences(userID string) ([]models.NotificationPreference, error)
+	FindPreference(userID, notificationType string) (*models.NotificationPreference, error)
+	UpsertPreference(pref *models.NotificationPreference) error
+	RemoveDuplicates() error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Create returns false kalo notif dgn dedupe key yg sama udah ada, unique index nya yg jagain
+// biar dua job/request barengan gk bikin notif dobel
+func (r *notificationRepository) Create(notification *models.Notification) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, r.db.Preload("Actor").First(notification, "id = ?", notification.ID).Error
+}
+
+func (r *notificationRepository) FindByID(id, userID string) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.First(&notification, "id = ? AND user_id = ?", id, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepository) FindByUserID(userID string, unreadOnly bool, offset, limit int) ([]models.Notification, int64, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("user_id = ? AND hidden = false", userID)
+		if unreadOnly {
+			db = db.Where("read_at IS NULL")
+		}
+		return db
+	}
+
+	var total int64
+	if err := r.db.Model(&models.Notification{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []models.Notification
+	err := r.db.Scopes(scope).
+		Preload("Actor").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *notificationRepository) CountUnread(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL AND hidden = false", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) SetReadAt(id, userID string, readAt *time.Time) error {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", readAt)
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+func (r *notificationRepository) MarkAllRead(userID string) error {
+	return r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+
+func (r *notificationRepository) FindPreferences(userID string) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+func (r *notificationRepository) FindPreference(userID, notificationType string) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := r.db.Where("user_id = ? AND type = ?", userID, notificationType).First(&pref).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (r *notificationRepository) UpsertPreference(pref *models.NotificationPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app", "email", "updated_at"}),
+	}).Create(pref).Error
+}
+
+// RemoveDuplicates keeps the oldest notification per dedupe key, has to run before the unique
+// index can be created on a table that already has doubles
+func (r *notificationRepository) RemoveDuplicates() error {
+	return r.db.Exec(`
+		DELETE FROM notifications
+		WHERE dedupe_key IS NOT NULL AND id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, dedupe_key ORDER BY created_at, id) AS n
+				FROM notifications WHERE dedupe_key IS NOT NULL
+			) d WHERE d.n > 1
+		)`).Error
+}
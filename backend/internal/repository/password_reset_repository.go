@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	FindByTokenHash(hash string) (*models.PasswordResetToken, error)
+	FindLatestByUserID(userID string) (*models.PasswordResetToken, error)
+	MarkUsed(id string, at time.Time) (bool, error)
+	InvalidateForUser(userID string, at time.Time) error
+	DeleteExpired(before time.Time) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+func (r *passwordResetRepository) Create(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *passwordResetRepository) FindByTokenHash(hash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.First(&token, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *passwordResetRepository) FindLatestByUserID(userID string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed returns false kalo token nya udah kepake duluan (klik dua kali / dua tab)
+func (r *passwordResetRepository) MarkUsed(id string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// InvalidateForUser burns every open link of the user, abis password diganti link lama gk boleh kepake
+func (r *passwordResetRepository) InvalidateForUser(userID string, at time.Time) error {
+	return r.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+}
+
+func (r *passwordResetRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.PasswordResetToken{}).Error
+}
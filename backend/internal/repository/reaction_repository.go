@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"minitask/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionRepository interface {
+	Create(reaction *models.Reaction) error
+	Remove(targetType, targetID, userID, emoji string) (bool, error)
+	SummarizeByTargets(targetType string, targetIDs []string, viewerID string) (map[string][]models.ReactionSummary, error)
+}
+
+type reactionRepository struct {
+	db *gorm.DB
+}
+
+func NewReactionRepository(db *gorm.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// Create is idempotent, reaction yg udah ada (double click) bukan error
+func (r *reactionRepository) Create(reaction *models.Reaction) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error
+}
+
+// Remove reports whether there was a reaction to delete
+func (r *reactionRepository) Remove(targetType, targetID, userID, emoji string) (bool, error) {
+	result := r.db.
+		Where("target_type = ? AND target_id = ? AND user_id = ? AND emoji = ?", targetType, targetID, userID, emoji).
+		Delete(&models.Reaction{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// SummarizeByTargets counts reactions per emoji for every target in one query, keyed by target ID
+func (r *reactionRepository) SummarizeByTargets(targetType string, targetIDs []string, viewerID string) (map[string][]models.ReactionSummary, error) {
+	summaries := map[string][]models.ReactionSummary{}
+	if len(targetIDs) == 0 {
+		return summaries, nil
+	}
+
+	var rows []struct {
+		TargetID    string
+		Emoji       string
+		Count       int
+		ReactedByMe bool
+	}
+	err := r.db.Model(&models.Reaction{}).
+		Select("target_id, emoji, COUNT(*) AS count, BOOL_OR(user_id = ?) AS reacted_by_me", viewerID).
+		Where("target_type = ? AND target_id IN ?", targetType, targetIDs).
+		Group("target_id, emoji").
+		Order("MIN(created_at) ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		summaries[row.TargetID] = append(summaries[row.TargetID], models.ReactionSummary{
+			Emoji:       row.Emoji,
+			Count:       row.Count,
+			ReactedByMe: row.ReactedByMe,
+		})
+	}
+	return summaries, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReminderRepository interface {
+	Create(reminder *models.TaskReminder) error
+	FindByID(id, userID string) (*models.TaskReminder, error)
+	FindByTaskAndUser(taskID, userID string) ([]models.TaskReminder, error)
+	FindRelativeByTaskID(taskID string) ([]models.TaskReminder, error)
+	FindDue(now time.Time) ([]models.TaskReminder, error)
+	Update(reminder *models.TaskReminder) error
+	MarkSent(id string, at time.Time) error
+	Delete(id string) error
+}
+
+type reminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &reminderRepository{db: db}
+}
+
+func (r *reminderRepository) Create(reminder *models.TaskReminder) error {
+	return r.db.Create(reminder).Error
+}
+
+func (r *reminderRepository) FindByID(id, userID string) (*models.TaskReminder, error) {
+	var reminder models.TaskReminder
+	err := r.db.First(&reminder, "id = ? AND user_id = ?", id, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+func (r *reminderRepository) FindByTaskAndUser(taskID, userID string) ([]models.TaskReminder, error) {
+	var reminders []models.TaskReminder
+	err := r.db.
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("remind_at ASC NULLS LAST").
+		Find(&reminders).Error
+	return reminders, err
+}
+
+func (r *reminderRepository) FindRelativeByTaskID(taskID string) ([]models.TaskReminder, error) {
+	var reminders []models.TaskReminder
+	err := r.db.Where("task_id = ? AND offset_minutes IS NOT NULL", taskID).Find(&reminders).Error
+	return reminders, err
+}
+
+// FindDue returns reminders that should have gone off by now and haven't
+func (r *reminderRepository) FindDue(now time.Time) ([]models.TaskReminder, error) {
+	var reminders []models.TaskReminder
+	err := r.db.
+		Where("remind_at <= ? AND sent_at IS NULL", now).
+		Order("remind_at ASC").
+		Find(&reminders).Error
+	return reminders, err
+}
+
+func (r *reminderRepository) Update(reminder *models.TaskReminder) error {
+	return r.db.Save(reminder).Error
+}
+
+func (r *reminderRepository) MarkSent(id string, at time.Time) error {
+	return r.db.Model(&models.TaskReminder{}).Where("id = ?", id).Update("sent_at", at).Error
+}
+
+func (r *reminderRepository) Delete(id string) error {
+	return r.db.Delete(&models.TaskReminder{}, "id = ?", id).Error
+}
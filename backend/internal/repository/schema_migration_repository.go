@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SchemaMigrationRepository interface {
+	Queue(name string) error
+	FindPending() ([]models.SchemaMigration, error)
+	MarkApplied(name string, at time.Time) error
+}
+
+type schemaMigrationRepository struct {
+	db *gorm.DB
+}
+
+func NewSchemaMigrationRepository(db *gorm.DB) SchemaMigrationRepository {
+	return &schemaMigrationRepository{db: db}
+}
+
+// Queue records a step to run, yg udah ke-queue (restart sebelum AutoMigrate kelar) gk diubah
+func (r *schemaMigrationRepository) Queue(name string) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.SchemaMigration{Name: name}).Error
+}
+
+func (r *schemaMigrationRepository) FindPending() ([]models.SchemaMigration, error) {
+	var migrations []models.SchemaMigration
+	err := r.db.Where("applied_at IS NULL").Order("created_at ASC").Find(&migrations).Error
+	return migrations, err
+}
+
+func (r *schemaMigrationRepository) MarkApplied(name string, at time.Time) error {
+	return r.db.Model(&models.SchemaMigration{}).Where("name = ?", name).Update("applied_at", at).Error
+}
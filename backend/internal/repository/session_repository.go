@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	FindByID(id string) (*models.Session, error)
+	FindActiveByUserID(userID string, now time.Time) ([]models.Session, error)
+	Touch(id, ip string, lastSeenAt time.Time, expiresAt *time.Time) error
+	Revoke(id, userID string, at time.Time) error
+	RevokeAllForUser(userID, exceptID string, at time.Time) error
+	DeleteExpired(before time.Time) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) FindByID(id string) (*models.Session, error) {
+	var session models.Session
+	err := r.db.First(&session, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) FindActiveByUserID(userID string, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Touch updates last seen (and ip), expiresAt cuma diisi pas refresh
+func (r *sessionRepository) Touch(id, ip string, lastSeenAt time.Time, expiresAt *time.Time) error {
+	updates := map[string]interface{}{"last_seen_at": lastSeenAt}
+	if ip != "" {
+		updates["ip"] = ip
+	}
+	if expiresAt != nil {
+		updates["expires_at"] = *expiresAt
+	}
+	return r.db.Model(&models.Session{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Revoke kills the session and every refresh token in it. userID kosong = gk dicek (reuse detection)
+func (r *sessionRepository) Revoke(id, userID string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Session{}).Where("id = ? AND revoked_at IS NULL", id)
+		if userID != "" {
+			query = query.Where("user_id = ?", userID)
+		}
+		result := query.Update("revoked_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", at).Error
+	})
+}
+
+// RevokeAllForUser signs the user out everywhere, kecuali exceptID (sesi yg lagi dipake) kalo diisi
+func (r *sessionRepository) RevokeAllForUser(userID, exceptID string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		sessions := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		tokens := tx.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if exceptID != "" {
+			sessions = sessions.Where("id <> ?", exceptID)
+			tokens = tokens.Where("family_id <> ?", exceptID)
+		}
+		if err := sessions.Update("revoked_at", at).Error; err != nil {
+			return err
+		}
+		return tokens.Update("revoked_at", at).Error
+	})
+}
+
+func (r *sessionRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.Session{}).Error
+}
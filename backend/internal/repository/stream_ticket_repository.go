@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StreamTicketRepository interface {
+	Create(ticket *models.StreamTicket) error
+	Consume(hash string, now time.Time) (*models.StreamTicket, error)
+	DeleteExpired(before time.Time) error
+}
+
+type streamTicketRepository struct {
+	db *gorm.DB
+}
+
+func NewStreamTicketRepository(db *gorm.DB) StreamTicketRepository {
+	return &streamTicketRepository{db: db}
+}
+
+func (r *streamTicketRepository) Create(ticket *models.StreamTicket) error {
+	return r.db.Create(ticket).Error
+}
+
+// Consume deletes the ticket and returns it in one statement, jadi dua koneksi yg rebutan
+// ticket yg sama cuma satu yg dapet
+func (r *streamTicketRepository) Consume(hash string, now time.Time) (*models.StreamTicket, error) {
+	var tickets []models.StreamTicket
+	err := r.db.Clauses(clause.Returning{}).
+		Where("token_hash = ? AND expires_at > ?", hash, now).
+		Delete(&tickets).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &tickets[0], nil
+}
+
+func (r *streamTicketRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.StreamTicket{}).Error
+}
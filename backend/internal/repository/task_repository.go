@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"fmt"
 	"gorm.io/gorm"
 	"minitask/internal/models"
+	"time"
 )
 
 type TaskRepository interface {
 	Create(task *models.Task) error
+	CreateAll(tasks []*models.Task) error
 	FindByID(id string) (*models.Task, error)
 	FindByIDAndUserID(id, userID string) (*models.Task, error)
 	FindAllByUserID(userID string) ([]models.Task, error)
@@ -19,6 +22,16 @@ type TaskRepository interface {
 
 	FindAllByWorkspaceID(workspaceID string) ([]models.Task, error)
 	FindByWorkspaceAndTaskID(workspaceID, taskID string) (*models.Task, error)
+	FindByWorkspaceAndKey(workspaceID, key string) (*models.Task, error)
+	FindByKeyForMember(key, userID string, limit int) ([]models.Task, error)
+
+	StreamByUserID(userID string, batchSize int, fn func([]models.Task) error) error
+	StreamByWorkspaceID(workspaceID string, batchSize int, fn func([]models.Task) error) error
+
+	FindDueBetween(from, to time.Time) ([]models.Task, error)
+	FindOpenForUser(userID string) ([]models.Task, error)
+	FindWithDueDateForUser(userID string, from time.Time, limit int) ([]models.Task, error)
+	FindWithDueDateForWorkspace(workspaceID string, from time.Time, limit int) ([]models.Task, error)
 }
 
 type taskRepository struct {
@@ -29,13 +42,53 @@ func NewTaskRepository(db *gorm.DB) TaskRepository {
 	return &taskRepository{db: db}
 }
 
+// Create also hands out the next task number for workspace tasks. The counter is bumped with
+// a single UPDATE .. RETURNING inside the same transaction, jadi dua request barengan gk bakal
+// dapet nomor yg sama
 func (r *taskRepository) Create(task *models.Task) error {
-	err := r.db.Create(task).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return createTask(tx, task)
+	})
 	if err != nil {
 		return err
 	}
+	return r.reload(task)
+}
+
+// CreateAll inserts every task in one transaction, satu gagal = gk ada yg kesimpen.
+// Beda sama Create, relasi nya gk di-load ulang (bisa ribuan task)
+func (r *taskRepository) CreateAll(tasks []*models.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, task := range tasks {
+			if err := createTask(tx, task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func createTask(tx *gorm.DB, task *models.Task) error {
+	if task.WorkspaceID != nil && task.Number == nil {
+		var number int
+		var prefix string
+		err := tx.Raw(
+			"UPDATE workspaces SET task_counter = task_counter + 1 WHERE id = ? RETURNING task_counter, key_prefix",
+			*task.WorkspaceID,
+		).Row().Scan(&number, &prefix)
+		if err != nil {
+			return err
+		}
+		task.Number = &number
+		task.Key = fmt.Sprintf("%s-%d", prefix, number)
+	}
+	return tx.Create(task).Error
+}
+
+// reload fills User & Assignee of a freshly created task
+func (r *taskRepository) reload(task *models.Task) error {
 	var createdTask models.Task
-	err = r.db.Preload("User").Preload("Assignee").First(&createdTask, "id = ?", task.ID).Error
+	err := r.db.Preload("User").Preload("Assignee").First(&createdTask, "id = ?", task.ID).Error
 	if err != nil {
 		return err
 	}
@@ -124,6 +177,29 @@ func (r *taskRepository) FindAllByWorkspaceID(workspaceID string) ([]models.Task
 	return tasks, err
 }
 
+// StreamByUserID walks the user's personal tasks in batches, buat export yg gk boleh load semua sekaligus
+func (r *taskRepository) StreamByUserID(userID string, batchSize int, fn func([]models.Task) error) error {
+	return r.stream(r.db.Where("user_id = ? AND workspace_id IS NULL", userID), batchSize, fn)
+}
+
+func (r *taskRepository) StreamByWorkspaceID(workspaceID string, batchSize int, fn func([]models.Task) error) error {
+	return r.stream(r.db.Where("workspace_id = ?", workspaceID), batchSize, fn)
+}
+
+// stream uses FindInBatches (keyset on id), comments & users di-preload per batch
+func (r *taskRepository) stream(query *gorm.DB, batchSize int, fn func([]models.Task) error) error {
+	var tasks []models.Task
+	return query.
+		Preload("User").
+		Preload("Assignee").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("User").Order("created_at ASC")
+		}).
+		FindInBatches(&tasks, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(tasks)
+		}).Error
+}
+
 func (r *taskRepository) FindByWorkspaceAndTaskID(workspaceID, taskID string) (*models.Task, error) {
 	var task models.Task
 	err := r.db.
@@ -138,3 +214,95 @@ func (r *taskRepository) FindByWorkspaceAndTaskID(workspaceID, taskID string) (*
 	}
 	return &task, nil
 }
+
+func (r *taskRepository) FindByWorkspaceAndKey(workspaceID, key string) (*models.Task, error) {
+	var task models.Task
+	err := r.db.
+		Preload("User").
+		Preload("Assignee").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("User")
+		}).
+		First(&task, "workspace_id = ? AND key = ?", workspaceID, key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindByKeyForMember looks a task key up across the workspaces the user is currently a member of.
+// Prefix cuma unik per workspace, jadi bisa aja dapet lebih dari satu
+func (r *taskRepository) FindByKeyForMember(key, userID string, limit int) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.
+		Preload("User").
+		Preload("Assignee").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("User")
+		}).
+		Joins("JOIN workspace_members wm ON wm.workspace_id = tasks.workspace_id AND wm.deleted_at IS NULL").
+		Where("tasks.key = ? AND wm.user_id = ?", key, userID).
+		Limit(limit).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// currentMemberTasks keeps personal tasks and tasks of workspaces the user is still a member of,
+// member yg udah di-remove gk boleh liat isi workspace itu lagi
+func currentMemberTasks(alias, userID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("("+alias+".workspace_id IS NULL OR EXISTS (SELECT 1 FROM workspace_members wm"+
+			" WHERE wm.workspace_id = "+alias+".workspace_id AND wm.user_id = ? AND wm.deleted_at IS NULL))", userID)
+	}
+}
+
+// FindDueBetween returns unfinished tasks whose due date falls in [from, to)
+func (r *taskRepository) FindDueBetween(from, to time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.
+		Where("due_date >= ? AND due_date < ? AND status <> ?", from, to, models.StatusDone).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// FindOpenForUser returns unfinished personal tasks plus workspace tasks assigned to the user
+// in workspaces they're still a member of
+func (r *taskRepository) FindOpenForUser(userID string) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.
+		Preload("Workspace").
+		Where("status <> ?", models.StatusDone).
+		Where("(user_id = ? AND workspace_id IS NULL) OR assignee_id = ?", userID, userID).
+		Scopes(currentMemberTasks("tasks", userID)).
+		Order("due_date ASC NULLS LAST").
+		Order("created_at ASC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// FindWithDueDateForUser is the personal calendar feed: own personal tasks + workspace tasks assigned to
+// the user, cuma dari workspace yg dia masih member (feed nya tetep ke-subscribe abis di-remove)
+func (r *taskRepository) FindWithDueDateForUser(userID string, from time.Time, limit int) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.
+		Preload("Assignee").
+		Where("due_date IS NOT NULL AND due_date >= ?", from).
+		Where("(user_id = ? AND workspace_id IS NULL) OR assignee_id = ?", userID, userID).
+		Scopes(currentMemberTasks("tasks", userID)).
+		Order("due_date ASC").
+		Limit(limit).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepository) FindWithDueDateForWorkspace(workspaceID string, from time.Time, limit int) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.
+		Preload("Assignee").
+		Where("due_date IS NOT NULL AND due_date >= ?", from).
+		Where("workspace_id = ?", workspaceID).
+		Order("due_date ASC").
+		Limit(limit).
+		Find(&tasks).Error
+	return tasks, err
+}
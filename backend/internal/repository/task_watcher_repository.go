@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TaskWatcherRepository interface {
+	Add(taskID, userID string) error
+	Remove(taskID, userID string) error
+	RemoveFromWorkspace(workspaceID, userID string) error
+	FindByTaskID(taskID string) ([]models.TaskWatcher, error)
+	FindUserIDsByTaskID(taskID string) ([]string, error)
+	IsWatching(taskID, userID string) (bool, error)
+	Backfill(before time.Time) error
+}
+
+type taskWatcherRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskWatcherRepository(db *gorm.DB) TaskWatcherRepository {
+	return &taskWatcherRepository{db: db}
+}
+
+// Add is idempotent, watching twice is not an error
+func (r *taskWatcherRepository) Add(taskID, userID string) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.TaskWatcher{TaskID: taskID, UserID: userID}).Error
+}
+
+func (r *taskWatcherRepository) Remove(taskID, userID string) error {
+	return r.db.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&models.TaskWatcher{}).Error
+}
+
+// RemoveFromWorkspace drops every watch a user has on tasks in a workspace, dipake pas member di remove
+func (r *taskWatcherRepository) RemoveFromWorkspace(workspaceID, userID string) error {
+	return r.db.
+		Where("user_id = ? AND task_id IN (?)", userID,
+			r.db.Model(&models.Task{}).Select("id").Where("workspace_id = ?", workspaceID)).
+		Delete(&models.TaskWatcher{}).Error
+}
+
+func (r *taskWatcherRepository) FindByTaskID(taskID string) ([]models.TaskWatcher, error) {
+	var watchers []models.TaskWatcher
+	err := r.db.Preload("User").Where("task_id = ?", taskID).Order("created_at ASC").Find(&watchers).Error
+	return watchers, err
+}
+
+func (r *taskWatcherRepository) FindUserIDsByTaskID(taskID string) ([]string, error) {
+	var userIDs []string
+	err := r.db.Model(&models.TaskWatcher{}).Where("task_id = ?", taskID).Order("created_at ASC").Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+func (r *taskWatcherRepository) IsWatching(taskID, userID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.TaskWatcher{}).Where("task_id = ? AND user_id = ?", taskID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// Backfill makes creators and assignees of tasks from before watchers existed watchers. Task yg lebih
+// baru dari before gk disentuh, jadi yg udah unwatch gk balik lagi kalo ini dijalanin ulang
+func (r *taskWatcherRepository) Backfill(before time.Time) error {
+	return r.db.Exec(`
+		INSERT INTO task_watchers (id, task_id, user_id, created_at)
+		SELECT gen_random_uuid()::text, t.id, u.user_id, NOW()
+		FROM tasks t
+		CROSS JOIN LATERAL (VALUES (t.user_id), (t.assignee_id)) AS u(user_id)
+		WHERE t.deleted_at IS NULL AND t.created_at < ? AND u.user_id IS NOT NULL
+		ON CONFLICT DO NOTHING`, before).Error
+}
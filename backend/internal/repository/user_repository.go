@@ -2,6 +2,7 @@ package repository
 
 import (
 	"minitask/internal/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,6 +15,11 @@ type UserRepository interface {
 	FindByUsername(username string) (*models.User, error)
 	Update(user *models.User) error
 	Delete(id string) error
+	FindDigestRecipients(sentBefore time.Time) ([]models.User, error)
+	SetLastDigestAt(id string, at time.Time) error
+	SetEmailVerifiedAt(id string, at time.Time) error
+	SetPassword(id, hash string) error
+	BackfillEmailVerified(before time.Time) error
 }
 
 type userRepository struct {
@@ -62,3 +68,33 @@ func (r *userRepository) Update(user *models.User) error {
 func (r *userRepository) Delete(id string) error {
 	return r.db.Delete(&models.User{}, "id = ?", id).Error
 }
+
+// FindDigestRecipients returns users who opted into the daily digest and haven't got one since sentBefore
+func (r *userRepository) FindDigestRecipients(sentBefore time.Time) ([]models.User, error) {
+	var users []models.User
+	err := r.db.
+		Joins("JOIN notification_preferences np ON np.user_id = users.id AND np.type = ? AND np.email = true", models.NotificationDailyDigest).
+		Where("users.last_digest_at IS NULL OR users.last_digest_at < ?", sentBefore).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) SetLastDigestAt(id string, at time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("last_digest_at", at).Error
+}
+
+func (r *userRepository) SetEmailVerifiedAt(id string, at time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("email_verified_at", at).Error
+}
+
+func (r *userRepository) SetPassword(id, hash string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("password", hash).Error
+}
+
+// BackfillEmailVerified marks accounts from before email verification existed as verified. Cuma yg
+// dibikin sebelum before, jadi dijalanin ulang pun akun baru yg belum verifikasi gk ikut ke-mark
+func (r *userRepository) BackfillEmailVerified(before time.Time) error {
+	return r.db.Model(&models.User{}).
+		Where("email_verified_at IS NULL AND created_at < ?", before).
+		Update("email_verified_at", gorm.Expr("created_at")).Error
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"minitask/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepository interface {
+	Create(webhook *models.Webhook) error
+	FindByID(id, workspaceID string) (*models.Webhook, error)
+	FindAllByWorkspaceID(workspaceID string) ([]models.Webhook, error)
+	FindActiveByWorkspaceID(workspaceID string) ([]models.Webhook, error)
+	Update(webhook *models.Webhook) error
+	Delete(id string) error
+	DeleteByWorkspaceID(workspaceID string) error
+
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	FindDelivery(id, webhookID string) (*models.WebhookDelivery, error)
+	FindDeliveries(webhookID string, offset, limit int) ([]models.WebhookDelivery, int64, error)
+	FindRetryableDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error)
+	ClaimDelivery(id string, now, leaseUntil time.Time) (bool, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(webhook *models.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *webhookRepository) FindByID(id, workspaceID string) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := r.db.First(&webhook, "id = ? AND workspace_id = ?", id, workspaceID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) FindAllByWorkspaceID(workspaceID string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.Where("workspace_id = ?", workspaceID).Order("created_at ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) FindActiveByWorkspaceID(workspaceID string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.Where("workspace_id = ? AND active = true", workspaceID).Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) Update(webhook *models.Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+// Delete removes a webhook together with its delivery log
+func (r *webhookRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Webhook{}, "id = ?", id).Error
+	})
+}
+
+func (r *webhookRepository) DeleteByWorkspaceID(workspaceID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		webhookIDs := tx.Model(&models.Webhook{}).Select("id").Where("workspace_id = ?", workspaceID)
+		if err := tx.Where("webhook_id IN (?)", webhookIDs).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("workspace_id = ?", workspaceID).Delete(&models.Webhook{}).Error
+	})
+}
+
+func (r *webhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *webhookRepository) FindDelivery(id, webhookID string) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.First(&delivery, "id = ? AND webhook_id = ?", id, webhookID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) FindDeliveries(webhookID string, offset, limit int) ([]models.WebhookDelivery, int64, error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("webhook_id = ?", webhookID)
+	}
+
+	var total int64
+	if err := r.db.Model(&models.WebhookDelivery{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := r.db.Scopes(scope).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// FindRetryableDeliveries returns pending deliveries whose next attempt is due
+func (r *webhookRepository) FindRetryableDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDelivery pushes next_attempt_at forward so only one sender attempts a delivery at a time.
+// False = someone else already took it
+func (r *webhookRepository) ClaimDelivery(id string, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, models.DeliveryPending, now).
+		Update("next_attempt_at", leaseUntil)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
@@ -22,6 +22,9 @@ type WorkspaceRepository interface {
 
 	FindByInviteCode(code string) (*models.Workspace, error)
 	UpdateInviteCode(id, newCode string) error
+	UpdateKeyPrefix(id, prefix string) error
+	BackfillKeyPrefixes() error
+	BackfillTaskNumbers() error
 }
 
 type workspaceRepository struct {
@@ -78,8 +81,9 @@ func (r *workspaceRepository) FindByMemberUserID(userID string) ([]models.Worksp
 	return workspaces, err
 }
 
+// Update never writes the task counter/prefix, counter nya cuma boleh naik lewat taskRepo.Create
 func (r *workspaceRepository) Update(workspace *models.Workspace) error {
-	return r.db.Save(workspace).Error
+	return r.db.Omit("TaskCounter", "KeyPrefix").Save(workspace).Error
 }
 
 func (r *workspaceRepository) Delete(id, ownerID string) error {
@@ -147,3 +151,61 @@ func (r *workspaceRepository) FindByInviteCode(code string) (*models.Workspace,
 func (r *workspaceRepository) UpdateInviteCode(id, newCode string) error {
 	return r.db.Model(&models.Workspace{}).Where("id = ?", id).Update("invite_code", newCode).Error
 }
+
+// BackfillKeyPrefixes gives workspaces made before task keys existed a prefix
+func (r *workspaceRepository) BackfillKeyPrefixes() error {
+	var workspaces []models.Workspace
+	if err := r.db.Where("key_prefix IS NULL OR key_prefix = ''").Find(&workspaces).Error; err != nil {
+		return err
+	}
+	for _, workspace := range workspaces {
+		err := r.db.Model(&models.Workspace{}).
+			Where("id = ?", workspace.ID).
+			Update("key_prefix", models.GenerateKeyPrefix(workspace.Name)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateKeyPrefix renames the prefix and rewrites the keys of every task in the workspace
+func (r *workspaceRepository) UpdateKeyPrefix(id, prefix string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Workspace{}).Where("id = ?", id).Update("key_prefix", prefix).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(
+			"UPDATE tasks SET key = ? || '-' || number WHERE workspace_id = ? AND number IS NOT NULL",
+			prefix, id,
+		).Error
+	})
+}
+
+// BackfillTaskNumbers numbers workspace tasks made before task keys existed, oldest first,
+// continuing after whatever the counter already handed out. Workspace nya di-lock dulu
+// biar gk tabrakan sama task yang lagi dibikin
+func (r *workspaceRepository) BackfillTaskNumbers() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`SELECT id FROM workspaces WHERE id IN (
+			SELECT workspace_id FROM tasks WHERE workspace_id IS NOT NULL AND number IS NULL
+		) FOR UPDATE`).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(`UPDATE tasks t
+			SET number = w.task_counter + n.rn, key = w.key_prefix || '-' || (w.task_counter + n.rn)
+			FROM (
+				SELECT id, workspace_id, ROW_NUMBER() OVER (PARTITION BY workspace_id ORDER BY created_at, id) AS rn
+				FROM tasks WHERE workspace_id IS NOT NULL AND number IS NULL
+			) n, workspaces w
+			WHERE t.id = n.id AND w.id = n.workspace_id`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE workspaces w SET task_counter = m.max_number
+			FROM (SELECT workspace_id, MAX(number) AS max_number FROM tasks WHERE workspace_id IS NOT NULL GROUP BY workspace_id) m
+			WHERE w.id = m.workspace_id AND w.task_counter < m.max_number`).Error
+	})
+}
@@ -3,17 +3,37 @@ package router
 import (
 	"minitask/internal/handler"
 	"minitask/internal/middleware"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
 )
 
 type Router struct {
-	authHandler      *handler.AuthHandler
-	taskHandler      *handler.TaskHandler
-	commentHandler   *handler.CommentHandler
-	workspaceHandler *handler.WorkspaceHandler
-	paymentHandler   *handler.PaymentHandler
+	authHandler            *handler.AuthHandler
+	taskHandler            *handler.TaskHandler
+	commentHandler         *handler.CommentHandler
+	workspaceHandler       *handler.WorkspaceHandler
+	paymentHandler         *handler.PaymentHandler
+	mentionHandler         *handler.MentionHandler
+	reactionHandler        *handler.ReactionHandler
+	activityHandler        *handler.ActivityHandler
+	realtimeHandler        *handler.RealtimeHandler
+	notificationHandler    *handler.NotificationHandler
+	watcherHandler         *handler.WatcherHandler
+	reminderHandler        *handler.ReminderHandler
+	webhookHandler         *handler.WebhookHandler
+	incomingWebhookHandler *handler.IncomingWebhookHandler
+	gitHandler             *handler.GitHandler
+	attachmentHandler      *handler.AttachmentHandler
+	inboundEmailHandler    *handler.InboundEmailHandler
+	exportHandler          *handler.ExportHandler
+	importHandler          *handler.ImportHandler
+	calendarHandler        *handler.CalendarHandler
+	backupHandler          *handler.BackupHandler
+	accountHandler         *handler.AccountHandler
+	sessionHandler         *handler.SessionHandler
 }
 
 func NewRouter(
@@ -22,13 +42,49 @@ func NewRouter(
 	commentHandler *handler.CommentHandler,
 	workspaceHandler *handler.WorkspaceHandler,
 	paymentHandler *handler.PaymentHandler,
+	mentionHandler *handler.MentionHandler,
+	reactionHandler *handler.ReactionHandler,
+	activityHandler *handler.ActivityHandler,
+	realtimeHandler *handler.RealtimeHandler,
+	notificationHandler *handler.NotificationHandler,
+	watcherHandler *handler.WatcherHandler,
+	reminderHandler *handler.ReminderHandler,
+	webhookHandler *handler.WebhookHandler,
+	incomingWebhookHandler *handler.IncomingWebhookHandler,
+	gitHandler *handler.GitHandler,
+	attachmentHandler *handler.AttachmentHandler,
+	inboundEmailHandler *handler.InboundEmailHandler,
+	exportHandler *handler.ExportHandler,
+	importHandler *handler.ImportHandler,
+	calendarHandler *handler.CalendarHandler,
+	backupHandler *handler.BackupHandler,
+	accountHandler *handler.AccountHandler,
+	sessionHandler *handler.SessionHandler,
 ) *Router {
 	return &Router{
-		authHandler:      authHandler,
-		taskHandler:      taskHandler,
-		commentHandler:   commentHandler,
-		workspaceHandler: workspaceHandler,
-		paymentHandler:   paymentHandler,
+		authHandler:            authHandler,
+		taskHandler:            taskHandler,
+		commentHandler:         commentHandler,
+		workspaceHandler:       workspaceHandler,
+		paymentHandler:         paymentHandler,
+		mentionHandler:         mentionHandler,
+		reactionHandler:        reactionHandler,
+		activityHandler:        activityHandler,
+		realtimeHandler:        realtimeHandler,
+		notificationHandler:    notificationHandler,
+		watcherHandler:         watcherHandler,
+		reminderHandler:        reminderHandler,
+		webhookHandler:         webhookHandler,
+		incomingWebhookHandler: incomingWebhookHandler,
+		gitHandler:             gitHandler,
+		attachmentHandler:      attachmentHandler,
+		inboundEmailHandler:    inboundEmailHandler,
+		exportHandler:          exportHandler,
+		importHandler:          importHandler,
+		calendarHandler:        calendarHandler,
+		backupHandler:          backupHandler,
+		accountHandler:         accountHandler,
+		sessionHandler:         sessionHandler,
 	}
 }
 
@@ -46,14 +102,55 @@ func (r *Router) Setup(e *echo.Echo) {
 	auth := api.Group("/auth")
 	auth.POST("/register", r.authHandler.Register)
 	auth.POST("/login", r.authHandler.Login)
+	auth.POST("/refresh", r.authHandler.Refresh)
+	auth.POST("/logout", r.authHandler.Logout)
+	auth.POST("/forgot-password", r.authHandler.ForgotPassword)
+	auth.POST("/reset-password", r.authHandler.ResetPassword)
+	auth.POST("/verify-email", r.authHandler.VerifyEmail)
 
 	//  NO JWT middleware, Midtrans calls this directly
 	api.POST("/payments/webhook", r.paymentHandler.Webhook)
 
+	// Incoming webhook, NO JWT, token di url. Rate limit per token: 30/menit, burst 10
+	incomingLimiter := echoMiddleware.RateLimiterWithConfig(echoMiddleware.RateLimiterConfig{
+		Store: echoMiddleware.NewRateLimiterMemoryStoreWithConfig(echoMiddleware.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Limit(0.5),
+			Burst:     10,
+			ExpiresIn: 5 * time.Minute,
+		}),
+		IdentifierExtractor: func(c echo.Context) (string, error) {
+			return c.Param("token"), nil
+		},
+	})
+	api.POST("/hooks/incoming/:token", r.incomingWebhookHandler.Receive, incomingLimiter)
+
+	// GitHub/GitLab push & PR webhook, NO JWT, diverifikasi pake secret integrasi
+	api.POST("/hooks/git/:workspaceId", r.gitHandler.Receive)
+
+	// Raw email dari mail server, NO JWT, pake X-Inbound-Secret
+	api.POST("/hooks/email", r.inboundEmailHandler.Receive)
+
+	// ICS feed, NO JWT, app kalender gk bisa kirim header jadi token di url
+	api.GET("/calendar/:token", r.calendarHandler.Feed)
+
+	// SSE, EventSource gk bisa kirim header jadi pake ticket sekali pakai dari POST /events/ticket
+	api.GET("/events", r.realtimeHandler.Stream, middleware.JWTStreamMiddleware)
+	api.POST("/events/ticket", r.realtimeHandler.CreateTicket, middleware.JWTMiddleware)
+
 	protected := api.Group("")
 	protected.Use(middleware.JWTMiddleware)
 
 	protected.GET("/profile", r.authHandler.GetProfile)
+	protected.GET("/account/export", r.accountHandler.Export)
+	protected.DELETE("/account", r.accountHandler.Delete)
+	protected.PUT("/account/password", r.authHandler.ChangePassword)
+	protected.POST("/account/verify-email/resend", r.authHandler.ResendVerification)
+
+	sessions := protected.Group("/sessions")
+	sessions.GET("", r.sessionHandler.GetAll)
+	sessions.DELETE("", r.sessionHandler.RevokeOthers) // sign out everywhere else
+	sessions.DELETE("/:id", r.sessionHandler.Revoke)
+	protected.GET("/activity", r.activityHandler.GetMine)
 
 	// Payment routes (protected)
 	payments := protected.Group("/payments")
@@ -65,31 +162,106 @@ func (r *Router) Setup(e *echo.Echo) {
 	tasks.POST("", r.taskHandler.Create)
 	tasks.GET("", r.taskHandler.GetAll)
 	tasks.GET("/stats", r.taskHandler.GetStats)
+	tasks.GET("/export", r.exportHandler.ExportPersonal)
+	tasks.POST("/import/preview", r.importHandler.Preview)
+	tasks.POST("/import", r.importHandler.ImportPersonal)
 	tasks.GET("/:id", r.taskHandler.GetByID)
 	tasks.PUT("/:id", r.taskHandler.Update)
 	tasks.DELETE("/:id", r.taskHandler.Delete)
 	tasks.PUT("/order", r.taskHandler.UpdateOrder)
+	tasks.POST("/:id/reactions", r.reactionHandler.ToggleOnTask)
+	tasks.GET("/:id/activity", r.activityHandler.GetForTask)
+	tasks.GET("/:id/watchers", r.watcherHandler.GetByTaskID)
+	tasks.POST("/:id/watch", r.watcherHandler.Watch)
+	tasks.DELETE("/:id/watch", r.watcherHandler.Unwatch)
+	tasks.GET("/:id/reminders", r.reminderHandler.GetForTask)
+	tasks.POST("/:id/reminders", r.reminderHandler.Create)
+	tasks.GET("/:id/links", r.gitHandler.GetTaskLinks)
+	tasks.GET("/:id/attachments", r.attachmentHandler.GetForTask)
+	tasks.POST("/:id/attachments", r.attachmentHandler.Upload)
+
+	protected.GET("/attachments/:id/download", r.attachmentHandler.Download)
+
+	reminders := protected.Group("/reminders")
+	reminders.GET("/settings", r.reminderHandler.GetSettings)
+	reminders.PUT("/settings", r.reminderHandler.UpdateSettings)
+	reminders.POST("/:id/snooze", r.reminderHandler.Snooze)
+	reminders.DELETE("/:id", r.reminderHandler.Delete)
+
+	calendar := protected.Group("/calendar-feeds")
+	calendar.GET("", r.calendarHandler.GetFeeds)
+	calendar.POST("", r.calendarHandler.CreateFeed)
+	calendar.PUT("/:id", r.calendarHandler.UpdateFeed)
+	calendar.POST("/:id/regenerate", r.calendarHandler.RegenerateToken)
+	calendar.DELETE("/:id", r.calendarHandler.DeleteFeed)
 
 	comments := protected.Group("/comments")
 	comments.POST("", r.commentHandler.Create)
 	comments.GET("/task/:taskId", r.commentHandler.GetByTaskID)
 	comments.GET("/:id", r.commentHandler.GetByID)
+	comments.GET("/:id/revisions", r.commentHandler.GetRevisions)
 	comments.PUT("/:id", r.commentHandler.Update)
 	comments.DELETE("/:id", r.commentHandler.Delete)
+	comments.POST("/:id/reactions", r.reactionHandler.ToggleOnComment)
+
+	protected.GET("/mentions", r.mentionHandler.GetMine)
+
+	notifications := protected.Group("/notifications")
+	notifications.GET("", r.notificationHandler.GetAll)
+	notifications.GET("/unread-count", r.notificationHandler.UnreadCount)
+	notifications.PUT("/read-all", r.notificationHandler.MarkAllRead)
+	notifications.GET("/preferences", r.notificationHandler.GetPreferences)
+	notifications.PUT("/preferences", r.notificationHandler.UpdatePreferences)
+	notifications.PUT("/:id/read", r.notificationHandler.MarkRead)
+	notifications.PUT("/:id/unread", r.notificationHandler.MarkUnread)
+	notifications.POST("/:id/snooze", r.reminderHandler.SnoozeNotification)
 
 	workspaces := protected.Group("/workspaces")
 	workspaces.POST("", r.workspaceHandler.Create)
 	workspaces.GET("", r.workspaceHandler.GetAll)
 	workspaces.POST("/join", r.workspaceHandler.Join)
+	workspaces.POST("/import", r.importHandler.ImportBoard)
+	workspaces.POST("/restore", r.backupHandler.Restore)
 
 	workspaces.GET("/:id", r.workspaceHandler.GetByID)
 	workspaces.PUT("/:id", r.workspaceHandler.Update)
 	workspaces.DELETE("/:id", r.workspaceHandler.Delete)
 
 	workspaces.POST("/:id/invite/refresh", r.workspaceHandler.RefreshInviteCode)
+	workspaces.POST("/:id/invite/email", r.workspaceHandler.InviteByEmail)
 	workspaces.DELETE("/:id/members/:userId", r.workspaceHandler.RemoveMember)
+	workspaces.GET("/:id/activity", r.activityHandler.GetForWorkspace)
+
+	workspaces.GET("/:id/webhooks", r.webhookHandler.GetAll)
+	workspaces.POST("/:id/webhooks", r.webhookHandler.Create)
+	workspaces.PUT("/:id/webhooks/:webhookId", r.webhookHandler.Update)
+	workspaces.DELETE("/:id/webhooks/:webhookId", r.webhookHandler.Delete)
+	workspaces.GET("/:id/webhooks/:webhookId/deliveries", r.webhookHandler.GetDeliveries)
+	workspaces.POST("/:id/webhooks/:webhookId/deliveries/:deliveryId/redeliver", r.webhookHandler.Redeliver)
+
+	workspaces.GET("/:id/incoming-webhooks", r.incomingWebhookHandler.GetAll)
+	workspaces.POST("/:id/incoming-webhooks", r.incomingWebhookHandler.Create)
+	workspaces.POST("/:id/incoming-webhooks/:webhookId/rotate", r.incomingWebhookHandler.RotateToken)
+	workspaces.DELETE("/:id/incoming-webhooks/:webhookId", r.incomingWebhookHandler.Delete)
+	workspaces.GET("/:id/incoming-webhooks/:webhookId/tasks", r.incomingWebhookHandler.GetTasks)
+
+	workspaces.GET("/:id/git", r.gitHandler.Get)
+	workspaces.POST("/:id/git", r.gitHandler.Enable)
+	workspaces.PUT("/:id/git", r.gitHandler.Update)
+	workspaces.DELETE("/:id/git", r.gitHandler.Disable)
+	workspaces.POST("/:id/git/rotate", r.gitHandler.RotateSecret)
+
+	workspaces.GET("/:id/inbound-email", r.inboundEmailHandler.Get)
+	workspaces.POST("/:id/inbound-email", r.inboundEmailHandler.Enable)
+	workspaces.POST("/:id/inbound-email/rotate", r.inboundEmailHandler.RotateAddress)
+	workspaces.DELETE("/:id/inbound-email", r.inboundEmailHandler.Disable)
+	workspaces.GET("/:id/inbound-email/messages", r.inboundEmailHandler.GetEmails)
 
 	workspaces.GET("/:id/tasks", r.workspaceHandler.GetTasks)
+	workspaces.GET("/:id/tasks/export", r.exportHandler.ExportWorkspace)
+	workspaces.GET("/:id/backup", r.backupHandler.Backup)
+	workspaces.POST("/:id/tasks/import/preview", r.importHandler.Preview)
+	workspaces.POST("/:id/tasks/import", r.importHandler.ImportWorkspace)
 	workspaces.GET("/:id/tasks/:taskId", r.workspaceHandler.GetTask)
 	workspaces.POST("/:id/tasks", r.workspaceHandler.CreateTask)
 	workspaces.PUT("/:id/tasks/:taskId/assign", r.workspaceHandler.AssignTask)
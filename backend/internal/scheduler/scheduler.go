@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// lockKey is the postgres advisory lock that decides which instance runs the jobs ("minitask" in hex)
+const lockKey int64 = 0x6d696e697461736b
+
+// tick is how often the scheduler wakes up, jobs can't run more often than this
+const tick = time.Minute
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func() error
+	lastRun  time.Time
+}
+
+// Scheduler runs background jobs inside the API process. Kalo jalan lebih dari 1 instance,
+// cuma yg pegang advisory lock (leader) yg jalanin job, sisanya standby sampe leader mati
+type Scheduler struct {
+	db   *sql.DB
+	jobs []*job
+	conn *sql.Conn // koneksi yg pegang lock, lock ilang kalo koneksi ini putus
+}
+
+func New(db *sql.DB) *Scheduler {
+	return &Scheduler{db: db}
+}
+
+// Every registers a job. Must be called before Run
+func (s *Scheduler) Every(name string, interval time.Duration, run func() error) {
+	s.jobs = append(s.jobs, &job{name: name, interval: interval, run: run})
+}
+
+// Run blocks until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	defer s.release()
+
+	for {
+		if s.isLeader(ctx) {
+			s.runDue(time.Now())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runDue(now time.Time) {
+	for _, j := range s.jobs {
+		if !j.lastRun.IsZero() && now.Sub(j.lastRun) < j.interval {
+			continue
+		}
+		j.lastRun = now
+		if err := j.run(); err != nil {
+			log.Printf("[Scheduler] %s failed: %v", j.name, err)
+		}
+	}
+}
+
+// isLeader keeps or tries to take the advisory lock. The lock belongs to the db session, so it is
+// held on one dedicated connection and re-checked every tick
+func (s *Scheduler) isLeader(ctx context.Context) bool {
+	if s.conn != nil {
+		if err := s.conn.PingContext(ctx); err == nil {
+			return true
+		}
+		log.Printf("[Scheduler] lost leader connection, trying to take over again")
+		s.release()
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return false
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&locked); err != nil || !locked {
+		conn.Close()
+		return false
+	}
+
+	log.Printf("[Scheduler] this instance is now running background jobs")
+	s.conn = conn
+	// job yg kelewat pas bukan leader langsung jalan
+	for _, j := range s.jobs {
+		j.lastRun = time.Time{}
+	}
+	return true
+}
+
+func (s *Scheduler) release() {
+	if s.conn == nil {
+		return
+	}
+	// unlock errors don't matter, closing the session drops the lock anyway
+	s.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+	s.conn.Close()
+	s.conn = nil
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"minitask/internal/models"
+	"minitask/internal/realtime"
+	"minitask/internal/repository"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	OwnedWorkspaceTransfer = "transfer"
+	OwnedWorkspaceDelete   = "delete"
+)
+
+type AccountService struct {
+	db               *gorm.DB
+	userRepo         repository.UserRepository
+	workspaceRepo    repository.WorkspaceRepository
+	workspaceService *WorkspaceService
+	publisher        realtime.Publisher
+}
+
+func NewAccountService(
+	db *gorm.DB,
+	userRepo repository.UserRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	workspaceService *WorkspaceService,
+	publisher realtime.Publisher,
+) *AccountService {
+	return &AccountService{
+		db:               db,
+		userRepo:         userRepo,
+		workspaceRepo:    workspaceRepo,
+		workspaceService: workspaceService,
+		publisher:        publisher,
+	}
+}
+
+// AccountExport is everything we keep about one user, buat self-service download
+type AccountExport struct {
+	ExportedAt  time.Time           `json:"exportedAt"`
+	Profile     AccountProfile      `json:"profile"`
+	Tasks       []AccountTask       `json:"tasks"`
+	Comments    []AccountComment    `json:"comments"`
+	Memberships []AccountMembership `json:"memberships"`
+	Orders      []AccountOrder      `json:"orders"`
+}
+
+type AccountProfile struct {
+	ID            string     `json:"id"`
+	Username      string     `json:"username"`
+	Email         string     `json:"email"`
+	Plan          string     `json:"plan"`
+	PlanExpiresAt *time.Time `json:"planExpiresAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// AccountTask is a task the user created, personal atau di workspace
+type AccountTask struct {
+	TaskExport
+	WorkspaceID *string `json:"workspaceId"`
+}
+
+type AccountComment struct {
+	ID          string     `json:"id"`
+	TaskID      string     `json:"taskId"`
+	TaskTitle   string     `json:"taskTitle"`
+	WorkspaceID *string    `json:"workspaceId"`
+	Content     string     `json:"content"`
+	EditedAt    *time.Time `json:"editedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+type AccountMembership struct {
+	WorkspaceID   string    `json:"workspaceId"`
+	WorkspaceName string    `json:"workspaceName"`
+	Role          string    `json:"role"`
+	JoinedAt      time.Time `json:"joinedAt"`
+}
+
+type AccountOrder struct {
+	OrderID   string    `json:"orderId"`
+	Plan      string    `json:"plan"`
+	Amount    int       `json:"amount"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	// Workspaces decides what happens to each workspace the user owns, keyed by workspace id.
+	// Workspace yg gk ada member lain boleh gk disebut, otomatis dihapus
+	Workspaces map[string]OwnedWorkspaceAction `json:"workspaces"`
+}
+
+type OwnedWorkspaceAction struct {
+	Action     string `json:"action"` // transfer | delete
+	NewOwnerID string `json:"newOwnerId"`
+}
+
+type OwnedWorkspace struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Members int    `json:"members"`
+}
+
+// OwnedWorkspacesError lists the workspaces that still need a transfer/delete decision
+type OwnedWorkspacesError struct {
+	Workspaces []OwnedWorkspace `json:"workspaces"`
+}
+
+func (e *OwnedWorkspacesError) Error() string {
+	return "choose whether to transfer or delete the workspaces you own"
+}
+
+func (s *AccountService) Export(userID string) (*AccountExport, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	export := &AccountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: AccountProfile{
+			ID:            user.ID,
+			Username:      user.Username,
+			Email:         user.Email,
+			Plan:          user.Plan,
+			PlanExpiresAt: user.PlanExpiresAt,
+			CreatedAt:     user.CreatedAt,
+		},
+		Tasks:       []AccountTask{},
+		Comments:    []AccountComment{},
+		Memberships: []AccountMembership{},
+		Orders:      []AccountOrder{},
+	}
+
+	var tasks []models.Task
+	err = s.db.
+		Preload("User").
+		Preload("Assignee").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("User").Order("created_at ASC")
+		}).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, errors.New("failed to export tasks")
+	}
+	for i := range tasks {
+		export.Tasks = append(export.Tasks, AccountTask{TaskExport: *toTaskExport(&tasks[i]), WorkspaceID: tasks[i].WorkspaceID})
+	}
+
+	var comments []models.Comment
+	if err := s.db.Preload("Task").Where("user_id = ?", userID).Order("created_at ASC").Find(&comments).Error; err != nil {
+		return nil, errors.New("failed to export comments")
+	}
+	for _, comment := range comments {
+		export.Comments = append(export.Comments, AccountComment{
+			ID:          comment.ID,
+			TaskID:      comment.TaskID,
+			TaskTitle:   comment.Task.Title,
+			WorkspaceID: comment.Task.WorkspaceID,
+			Content:     comment.Content,
+			EditedAt:    comment.EditedAt,
+			CreatedAt:   comment.CreatedAt,
+		})
+	}
+
+	workspaces, err := s.workspaceRepo.FindByMemberUserID(userID)
+	if err != nil {
+		return nil, errors.New("failed to export memberships")
+	}
+	for _, workspace := range workspaces {
+		member, err := s.workspaceRepo.FindMember(workspace.ID, userID)
+		if err != nil {
+			continue
+		}
+		export.Memberships = append(export.Memberships, AccountMembership{
+			WorkspaceID:   workspace.ID,
+			WorkspaceName: workspace.Name,
+			Role:          member.Role,
+			JoinedAt:      member.JoinedAt,
+		})
+	}
+
+	var orders []models.PendingOrder
+	if err := s.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&orders).Error; err != nil {
+		return nil, errors.New("failed to export orders")
+	}
+	for _, order := range orders {
+		export.Orders = append(export.Orders, AccountOrder{
+			OrderID:   order.OrderID,
+			Plan:      order.Plan,
+			Amount:    order.Amount,
+			Status:    order.Status,
+			CreatedAt: order.CreatedAt,
+		})
+	}
+	return export, nil
+}
+
+// Delete removes the account for good. Workspace yg dia punya di-transfer atau dihapus, comment nya
+// tetep ada tapi penulisnya dianonimkan, order pending dibatalin, dan token lama langsung gk berlaku
+// (user nya udah gk ketemu di token validator)
+func (s *AccountService) Delete(userID string, req *DeleteAccountRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user.IsBot {
+		return errors.New("user not found")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return errors.New("password is incorrect")
+	}
+
+	owned, err := s.workspaceRepo.FindByOwnerID(userID)
+	if err != nil {
+		return errors.New("failed to load workspaces")
+	}
+	transfers := map[string]string{} // workspace id -> new owner
+	var deletes []models.Workspace
+	pending := &OwnedWorkspacesError{}
+	for _, workspace := range owned {
+		members, err := s.workspaceRepo.FindAllMembers(workspace.ID)
+		if err != nil {
+			return errors.New("failed to load workspaces")
+		}
+		humans := map[string]bool{}
+		for _, member := range members {
+			if member.UserID != userID && !member.User.IsBot {
+				humans[member.UserID] = true
+			}
+		}
+
+		action, ok := req.Workspaces[workspace.ID]
+		switch {
+		case !ok && len(humans) == 0, ok && action.Action == OwnedWorkspaceDelete:
+			deletes = append(deletes, workspace)
+		case ok && action.Action == OwnedWorkspaceTransfer:
+			if !humans[action.NewOwnerID] {
+				return fmt.Errorf("new owner of %q must be a member of the workspace", workspace.Name)
+			}
+			transfers[workspace.ID] = action.NewOwnerID
+		case ok:
+			return fmt.Errorf("action for %q must be transfer or delete", workspace.Name)
+		default:
+			pending.Workspaces = append(pending.Workspaces, OwnedWorkspace{ID: workspace.ID, Name: workspace.Name, Members: len(humans)})
+		}
+	}
+	if len(pending.Workspaces) > 0 {
+		return pending
+	}
+
+	memberships, err := s.workspaceRepo.FindByMemberUserID(userID)
+	if err != nil {
+		return errors.New("failed to load workspaces")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for workspaceID, newOwnerID := range transfers {
+			if err := tx.Model(&models.Workspace{}).Where("id = ?", workspaceID).Update("owner_id", newOwnerID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.WorkspaceMember{}).
+				Where("workspace_id = ? AND user_id = ?", workspaceID, newOwnerID).
+				Update("role", models.RoleOwner).Error; err != nil {
+				return err
+			}
+		}
+		for _, workspace := range deletes {
+			if err := tx.Delete(&models.Workspace{}, "id = ?", workspace.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// task nya di workspace tetep ada (creator nya jadi anonim), personal task ikut dihapus
+		if err := tx.Where("user_id = ?", userID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Task{}).Where("assignee_id = ?", userID).Update("assignee_id", nil).Error; err != nil {
+			return err
+		}
+		personalTasks := tx.Model(&models.Task{}).Select("id").Where("user_id = ? AND workspace_id IS NULL", userID)
+		if err := deleteTaskReactions(tx, personalTasks); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND workspace_id IS NULL", userID).Delete(&models.Task{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PendingOrder{}).
+			Where("user_id = ? AND status = ?", userID, "pending").
+			Update("status", "cancel").Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&models.TaskWatcher{}, &models.TaskReminder{}, &models.Reaction{},
+			&models.Notification{}, &models.NotificationPreference{}, &models.CalendarFeed{},
+			&models.RefreshToken{}, &models.Session{}, &models.PasswordResetToken{}, &models.EmailVerificationToken{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("mentioned_user_id = ?", userID).Delete(&models.Mention{}).Error; err != nil {
+			return err
+		}
+
+		// username & email dilepas biar bisa dipake daftar lagi. Row nya di-soft delete, jadi comment &
+		// task yg dia tulis masih ada tapi gk nyambung ke siapa2 lagi
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":         "deleted-user-" + userID[:8],
+			"email":            "deleted-" + userID + "@users.invalid",
+			"password":         "",
+			"plan":             "free",
+			"plan_expires_at":  nil,
+			"reminder_windows": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, "id = ?", userID).Error
+	})
+	if err != nil {
+		log.Printf("[AccountService] failed to delete account %s: %v", userID, err)
+		return errors.New("failed to delete account")
+	}
+
+	for workspaceID, newOwnerID := range transfers {
+		s.workspaceService.recordWorkspace(userID, workspaceID, models.ActivityUpdated,
+			map[string]interface{}{"ownerId": userID}, map[string]interface{}{"ownerId": newOwnerID})
+	}
+	for i := range deletes {
+		s.workspaceService.recordWorkspace(userID, deletes[i].ID, models.ActivityDeleted, WorkspaceSnapshot(&deletes[i]), nil)
+		s.publisher.Publish(realtime.NewEvent(realtime.EventWorkspaceDeleted, deletes[i].ID, userID, nil))
+	}
+	for _, workspace := range memberships {
+		if containsWorkspace(deletes, workspace.ID) {
+			continue
+		}
+		s.workspaceService.recordMember(userID, workspace.ID, userID, models.ActivityRemoved)
+		s.publisher.Publish(realtime.NewEvent(realtime.EventMemberRemoved, workspace.ID, userID, realtime.MemberData{UserID: userID}))
+	}
+	return nil
+}
+
+func containsWorkspace(workspaces []models.Workspace, id string) bool {
+	for _, workspace := range workspaces {
+		if workspace.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultActivityLimit = 20
+	maxActivityLimit     = 100
+)
+
+type ActivityService struct {
+	db            *gorm.DB
+	activityRepo  repository.ActivityRepository
+	workspaceRepo repository.WorkspaceRepository
+	accessPolicy  *TaskAccessPolicy
+}
+
+func NewActivityService(
+	db *gorm.DB,
+	activityRepo repository.ActivityRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	accessPolicy *TaskAccessPolicy,
+) *ActivityService {
+	return &ActivityService{
+		db:            db,
+		activityRepo:  activityRepo,
+		workspaceRepo: workspaceRepo,
+		accessPolicy:  accessPolicy,
+	}
+}
+
+type ActivityPage struct {
+	Items []models.Activity `json:"items"`
+	Page  int               `json:"page"`
+	Limit int               `json:"limit"`
+	Total int64             `json:"total"`
+}
+
+// ActivityEntry is what the other services hand over when something changes
+type ActivityEntry struct {
+	ActorID     string
+	EntityType  string
+	EntityID    string
+	Action      string
+	WorkspaceID *string
+	TaskID      *string
+	Before      map[string]interface{}
+	After       map[string]interface{}
+}
+
+// Record stores an activity event. Gagal nyimpen log gk boleh bikin request utamanya gagal, jadi cuma di log aja
+func (s *ActivityService) Record(entry ActivityEntry) {
+	changes := DiffFields(entry.Before, entry.After)
+	if entry.Action == models.ActivityUpdated && len(changes) == 0 {
+		return
+	}
+
+	activity := &models.Activity{
+		ActorID:     entry.ActorID,
+		EntityType:  entry.EntityType,
+		EntityID:    entry.EntityID,
+		Action:      entry.Action,
+		WorkspaceID: entry.WorkspaceID,
+		TaskID:      entry.TaskID,
+		Changes:     changes,
+	}
+	if err := s.activityRepo.Create(activity); err != nil {
+		log.Printf("[ActivityService] failed to record %s %s %s: %v", entry.EntityType, entry.EntityID, entry.Action, err)
+	}
+}
+
+// DiffFields returns the fields whose value differs between before and after.
+// A nil before (create) or nil after (delete) records every field of the other side.
+func DiffFields(before, after map[string]interface{}) models.ActivityChanges {
+	changes := models.ActivityChanges{}
+	for key, to := range after {
+		from := before[key]
+		if before != nil && reflect.DeepEqual(from, to) {
+			continue
+		}
+		changes[key] = models.FieldChange{From: from, To: to}
+	}
+	for key, from := range before {
+		if _, ok := after[key]; !ok {
+			changes[key] = models.FieldChange{From: from, To: nil}
+		}
+	}
+	return changes
+}
+
+func TaskSnapshot(task *models.Task) map[string]interface{} {
+	return map[string]interface{}{
+		"title":       task.Title,
+		"description": task.Description,
+		"status":      task.Status,
+		"assigneeId":  derefString(task.AssigneeID),
+		"dueDate":     formatTime(task.DueDate),
+		"labels":      labelsSnapshot(task.Labels),
+	}
+}
+
+func labelsSnapshot(labels models.StringList) []string {
+	if len(labels) == 0 {
+		return []string{}
+	}
+	return append([]string{}, labels...)
+}
+
+func WorkspaceSnapshot(workspace *models.Workspace) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        workspace.Name,
+		"description": workspace.Description,
+		"keyPrefix":   workspace.KeyPrefix,
+	}
+}
+
+func formatTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func derefString(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func (s *ActivityService) GetForTask(taskID, userID string, page, limit int) (*ActivityPage, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	page, limit = normalizePage(page, limit)
+	items, total, err := s.activityRepo.FindByTaskID(task.ID, (page-1)*limit, limit)
+	return s.toPage(items, total, page, limit, err)
+}
+
+func (s *ActivityService) GetForWorkspace(workspaceID, userID string, page, limit int) (*ActivityPage, error) {
+	isMember, err := s.workspaceRepo.IsMember(workspaceID, userID)
+	if err != nil || !isMember {
+		return nil, errors.New("workspace not found or access denied")
+	}
+	page, limit = normalizePage(page, limit)
+	items, total, err := s.activityRepo.FindByWorkspaceID(workspaceID, (page-1)*limit, limit)
+	return s.toPage(items, total, page, limit, err)
+}
+
+func (s *ActivityService) GetForUser(userID string, page, limit int) (*ActivityPage, error) {
+	page, limit = normalizePage(page, limit)
+	items, total, err := s.activityRepo.FindByActorID(userID, (page-1)*limit, limit)
+	return s.toPage(items, total, page, limit, err)
+}
+
+func (s *ActivityService) toPage(items []models.Activity, total int64, page, limit int, err error) (*ActivityPage, error) {
+	if err != nil {
+		return nil, errors.New("failed to load activity")
+	}
+	if items == nil {
+		items = []models.Activity{}
+	}
+	return &ActivityPage{Items: items, Page: page, Limit: limit, Total: total}, nil
+}
+
+func normalizePage(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultActivityLimit
+	}
+	if limit > maxActivityLimit {
+		limit = maxActivityLimit
+	}
+	return page, limit
+}
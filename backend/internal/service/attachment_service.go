@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"log"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxAttachmentSize per file, sama buat upload manual & attachment email
+const MaxAttachmentSize = 10 << 20
+
+var ErrAttachmentTooLarge = errors.New("attachment is larger than 10MB")
+
+type AttachmentService struct {
+	db             *gorm.DB
+	attachmentRepo repository.AttachmentRepository
+	accessPolicy   *TaskAccessPolicy
+	dir            string
+}
+
+func NewAttachmentService(
+	db *gorm.DB,
+	attachmentRepo repository.AttachmentRepository,
+	accessPolicy *TaskAccessPolicy,
+) *AttachmentService {
+	dir := os.Getenv("ATTACHMENTS_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+	return &AttachmentService{
+		db:             db,
+		attachmentRepo: attachmentRepo,
+		accessPolicy:   accessPolicy,
+		dir:            dir,
+	}
+}
+
+func (s *AttachmentService) GetForTask(taskID, userID string) ([]models.Attachment, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := s.attachmentRepo.FindByTaskID(task.ID)
+	if err != nil {
+		return nil, errors.New("failed to load attachments")
+	}
+	if attachments == nil {
+		attachments = []models.Attachment{}
+	}
+	return attachments, nil
+}
+
+// Upload attaches a file to a task, siapa aja yg bisa liat task nya boleh upload
+func (s *AttachmentService) Upload(taskID, userID, filename, contentType string, r io.Reader) (*models.Attachment, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Save(task.ID, nil, userID, filename, contentType, r)
+}
+
+// Open returns the attachment and the path of its file on disk
+func (s *AttachmentService) Open(id, userID string) (*models.Attachment, string, error) {
+	attachment, err := s.attachmentRepo.FindByID(id)
+	if err != nil {
+		return nil, "", errors.New("attachment not found")
+	}
+	if _, err := s.accessPolicy.LoadViewable(attachment.TaskID, userID); err != nil {
+		return nil, "", errors.New("attachment not found")
+	}
+	return attachment, s.path(attachment), nil
+}
+
+// Save writes the file and its record without any access check, caller yg mastiin boleh
+func (s *AttachmentService) Save(taskID string, commentID *string, userID, filename, contentType string, r io.Reader) (*models.Attachment, error) {
+	filename = strings.TrimSpace(filepath.Base(filename))
+	if filename == "" || filename == "." || filename == string(filepath.Separator) {
+		filename = "attachment"
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	id := uuid.New().String()
+	storagePath, size, err := s.store(taskID, id, r)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		ID:           id,
+		TaskID:       taskID,
+		CommentID:    commentID,
+		UploadedByID: userID,
+		Filename:     filename,
+		ContentType:  contentType,
+		Size:         size,
+		StoragePath:  storagePath,
+	}
+	if err := s.attachmentRepo.Create(attachment); err != nil {
+		s.remove(storagePath)
+		return nil, errors.New("failed to save attachment")
+	}
+	return attachment, nil
+}
+
+// store writes the file under ATTACHMENTS_DIR/<taskID>/<id> and returns the relative path
+func (s *AttachmentService) store(taskID, id string, r io.Reader) (string, int64, error) {
+	storagePath := filepath.Join(taskID, id)
+	fullPath := filepath.Join(s.dir, storagePath)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", 0, errors.New("failed to save attachment")
+	}
+	file, err := os.Create(fullPath)
+	if err != nil {
+		return "", 0, errors.New("failed to save attachment")
+	}
+	size, err := io.Copy(file, io.LimitReader(r, MaxAttachmentSize+1))
+	file.Close()
+	if err != nil || size > MaxAttachmentSize {
+		os.Remove(fullPath)
+		if size > MaxAttachmentSize {
+			return "", 0, ErrAttachmentTooLarge
+		}
+		return "", 0, errors.New("failed to save attachment")
+	}
+	return storagePath, size, nil
+}
+
+func (s *AttachmentService) remove(storagePath string) {
+	fullPath := filepath.Join(s.dir, storagePath)
+	if err := os.Remove(fullPath); err != nil {
+		log.Printf("[AttachmentService] failed to clean up %s: %v", fullPath, err)
+	}
+}
+
+func (s *AttachmentService) path(attachment *models.Attachment) string {
+	return filepath.Join(s.dir, attachment.StoragePath)
+}
@@ -1,22 +1,68 @@
 package service
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"log"
+	"minitask/internal/mailer"
 	"minitask/internal/middleware"
 	"minitask/internal/models"
 	"minitask/internal/repository"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+const (
+	refreshTokenPrefix = "mt_rt_"
+	RefreshTokenTTL    = 30 * 24 * time.Hour
+
+	resetTokenPrefix = "mt_pr_"
+	PasswordResetTTL = time.Hour
+	// passwordResetCooldown biar forgot password gk bisa dipake nge-spam inbox orang
+	passwordResetCooldown = time.Minute
+	// refreshReuseGrace: dua tab yg refresh barengan pake token yg sama bukan pencurian
+	refreshReuseGrace = 10 * time.Second
+)
+
+var ErrInvalidRefreshToken = errors.New("Invalid or expired refresh token")
+
+// sessionTouchInterval biar middleware gk nulis last seen tiap request
+const sessionTouchInterval = time.Minute
+
+var ErrInvalidResetToken = errors.New("Reset link is invalid or has expired")
+
 type AuthService struct {
-	db       *gorm.DB
-	userRepo repository.UserRepository
+	db                *gorm.DB
+	userRepo          repository.UserRepository
+	refreshTokenRepo  repository.RefreshTokenRepository
+	sessionRepo       repository.SessionRepository
+	passwordResetRepo repository.PasswordResetRepository
+	verificationRepo  repository.EmailVerificationRepository
+	mailer            mailer.Mailer
 }
 
-func NewAuthService(db *gorm.DB, userRepo repository.UserRepository) *AuthService {
-	return &AuthService{db: db, userRepo: userRepo}
+func NewAuthService(
+	db *gorm.DB,
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	sessionRepo repository.SessionRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	verificationRepo repository.EmailVerificationRepository,
+	mail mailer.Mailer,
+) *AuthService {
+	return &AuthService{
+		db:                db,
+		userRepo:          userRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		sessionRepo:       sessionRepo,
+		passwordResetRepo: passwordResetRepo,
+		verificationRepo:  verificationRepo,
+		mailer:            mail,
+	}
 }
 
 type RegisterRequest struct { //req body buat regist
@@ -31,23 +77,51 @@ type LoginRequest struct { //req body buat login
 }
 
 type AuthResponse struct { //req respons abis login/regist
-	Token string      `json:"token"`
-	User  models.User `json:"user"`
+	Token        string      `json:"token"` // access token, umurnya cuma AccessTokenTTL
+	ExpiresAt    time.Time   `json:"expiresAt"`
+	RefreshToken string      `json:"refreshToken"`
+	User         models.User `json:"user"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+// ClientInfo is where a login/refresh came from, disimpen di session
+type ClientInfo struct {
+	UserAgent string
+	IP        string
 }
 
 // urus handlers dulu
-func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
+// Akun baru belum verified: tetep langsung login, tapi link verifikasi dikirim ke email nya
+func (s *AuthService) Register(req *RegisterRequest, client ClientInfo) (*AuthResponse, error) {
+	req.Email = strings.TrimSpace(req.Email)
 	if req.Username == "" {
 		return nil, errors.New("Username Required!")
 	}
 	if req.Email == "" {
 		return nil, errors.New("Email Required!")
 	}
-	if req.Password == "" {
-		return nil, errors.New("Password Required!")
+	if err := validateEmail(req.Email); err != nil {
+		return nil, err
 	}
-	if len(req.Password) < 6 {
-		return nil, errors.New("Password must be astleast 6 characters")
+	if err := validatePassword(req.Password); err != nil {
+		return nil, err
 	}
 
 	var existingUser models.User
@@ -75,20 +149,15 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 	if err != nil {
 		return nil, errors.New("Failed to create account")
 	}
-
-	token, err := middleware.GenerateToken(user.ID, user.Username)
-	if err != nil {
-		return nil, errors.New("Failed to generate token")
+	if err := s.sendVerification(&user); err != nil {
+		log.Printf("[AuthService] failed to send verification email to %s: %v", user.ID, err)
 	}
 
-	return &AuthResponse{
-		Token: token,
-		User:  user,
-	}, nil
+	return s.startSession(&user, client)
 }
 
 // Login handles user authentication
-func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
+func (s *AuthService) Login(req *LoginRequest, client ClientInfo) (*AuthResponse, error) {
 	if req.Email == "" {
 		return nil, errors.New("Email Required!")
 	}
@@ -102,20 +171,17 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 		return nil, errors.New("Invalid email or password")
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
-	if err != nil {
+	// bot user (incoming webhook) gk boleh login
+	if user.IsBot {
 		return nil, errors.New("Invalid email or password")
 	}
 
-	token, err := middleware.GenerateToken(user.ID, user.Username)
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return nil, errors.New("Failed to generate token")
+		return nil, errors.New("Invalid email or password")
 	}
 
-	return &AuthResponse{
-		Token: token,
-		User:  user,
-	}, nil
+	return s.startSession(&user, client)
 }
 
 // GetUserByID retrieves a user by their ID
@@ -128,3 +194,256 @@ func (s *AuthService) GetUserByID(userID string) (*models.User, error) {
 	user.Password = ""
 	return &user, nil
 }
+
+// Refresh rotates a refresh token: yg lama ditandai kepake, yg baru di family yg sama.
+// Token yg udah pernah dipake dateng lagi berarti ada yg nyolong, satu family di-revoke. Kecuali
+// masih dalam refreshReuseGrace (tab lain barusan refresh): dapet token baru juga di family yg sama
+func (s *AuthService) Refresh(req *RefreshRequest, client ClientInfo) (*AuthResponse, error) {
+	stored, err := s.refreshTokenRepo.FindByTokenHash(hashToken(strings.TrimSpace(req.RefreshToken)))
+	if err != nil || stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		if time.Since(*stored.UsedAt) > refreshReuseGrace {
+			s.revokeReusedFamily(stored)
+			return nil, ErrInvalidRefreshToken
+		}
+	} else if _, err := s.refreshTokenRepo.MarkUsed(stored.ID, time.Now()); err != nil {
+		return nil, errors.New("Failed to refresh token")
+	}
+	// MarkUsed false = request lain barengan baru aja make token ini, masuk grace juga jd gk di-revoke
+
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	response, err := s.issueTokens(user, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(RefreshTokenTTL)
+	if err := s.sessionRepo.Touch(stored.FamilyID, client.IP, time.Now(), &expiresAt); err != nil {
+		log.Printf("[AuthService] failed to update session %s: %v", stored.FamilyID, err)
+	}
+	return response, nil
+}
+
+// Logout revokes the refresh token's family, access token dari sesi itu juga langsung ditolak.
+// Token yg gk dikenal gk dianggap error, hasilnya sama aja: udah logout
+func (s *AuthService) Logout(req *RefreshRequest) error {
+	stored, err := s.refreshTokenRepo.FindByTokenHash(hashToken(strings.TrimSpace(req.RefreshToken)))
+	if err != nil {
+		return nil
+	}
+	if err := s.sessionRepo.Revoke(stored.FamilyID, "", time.Now()); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("Failed to log out")
+	}
+	return nil
+}
+
+// ValidateToken is the middleware's revocation check: user nya masih ada & sesi nya belum di-revoke
+func (s *AuthService) ValidateToken(claims *middleware.JWTClaims) error {
+	if claims.SessionID == "" {
+		return errors.New("Invalid or expired token")
+	}
+	if _, err := s.userRepo.FindByID(claims.UserID); err != nil {
+		return errors.New("Invalid or expired token")
+	}
+	session, err := s.sessionRepo.FindByID(claims.SessionID)
+	if err != nil || session.RevokedAt != nil || session.UserID != claims.UserID {
+		return errors.New("Invalid or expired token")
+	}
+	if now := time.Now(); now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.sessionRepo.Touch(session.ID, "", now, nil); err != nil {
+			log.Printf("[AuthService] failed to update session %s: %v", session.ID, err)
+		}
+	}
+	return nil
+}
+
+// ChangePassword needs the current password. Sesi lain di-sign out, sesi yg dipake sekarang tetep jalan
+func (s *AuthService) ChangePassword(userID, sessionID string, req *ChangePasswordRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("User not found")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return errors.New("Current password is incorrect")
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		return err
+	}
+	if err := s.setPassword(user, req.NewPassword, sessionID); err != nil {
+		return err
+	}
+	s.sendAccountEmail(mailer.TemplatePasswordChanged, user, frontendURL()+"/forgot-password", "")
+	return nil
+}
+
+// ForgotPassword emails a reset link. Selalu sukses dari sisi caller, biar gk bisa dipake ngecek
+// email mana yg kedaftar
+func (s *AuthService) ForgotPassword(req *ForgotPasswordRequest, client ClientInfo) error {
+	user, err := s.userRepo.FindByEmail(strings.TrimSpace(req.Email))
+	if err != nil || user.IsBot {
+		return nil
+	}
+	if latest, err := s.passwordResetRepo.FindLatestByUserID(user.ID); err == nil && time.Since(latest.CreatedAt) < passwordResetCooldown {
+		return nil
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return errors.New("Failed, please try again later")
+	}
+	token := resetTokenPrefix + hex.EncodeToString(b)
+	err = s.passwordResetRepo.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(PasswordResetTTL),
+		RequestIP: client.IP,
+	})
+	if err != nil {
+		return errors.New("Failed, please try again later")
+	}
+	s.sendAccountEmail(mailer.TemplatePasswordReset, user, frontendURL()+"/reset-password?token="+token, "1 hour")
+	return nil
+}
+
+// ResetPassword sets a new password from a reset link. Semua sesi di-sign out, jadi kalo yg reset
+// bukan yg nyolong akun, yg nyolong langsung ke-logout. Link nya dikirim ke email, jadi sekalian
+// dianggap bukti email nya beneran punya dia
+func (s *AuthService) ResetPassword(req *ResetPasswordRequest) error {
+	stored, err := s.passwordResetRepo.FindByTokenHash(hashToken(strings.TrimSpace(req.Token)))
+	if err != nil || stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	if ok, err := s.passwordResetRepo.MarkUsed(stored.ID, time.Now()); err != nil || !ok {
+		return ErrInvalidResetToken
+	}
+	if !user.EmailVerified() {
+		if err := s.userRepo.SetEmailVerifiedAt(user.ID, time.Now()); err != nil {
+			log.Printf("[AuthService] failed to mark %s verified after reset: %v", user.ID, err)
+		}
+	}
+	return s.setPassword(user, req.NewPassword, "")
+}
+
+// setPassword stores the new hash, burns open reset links and signs out every session except keepSessionID
+func (s *AuthService) setPassword(user *models.User, password, keepSessionID string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("Failed, please try again later")
+	}
+	if err := s.userRepo.SetPassword(user.ID, string(hashedPassword)); err != nil {
+		return errors.New("Failed to update password")
+	}
+
+	now := time.Now()
+	if err := s.sessionRepo.RevokeAllForUser(user.ID, keepSessionID, now); err != nil {
+		log.Printf("[AuthService] failed to revoke sessions of %s after password change: %v", user.ID, err)
+	}
+	if err := s.passwordResetRepo.InvalidateForUser(user.ID, now); err != nil {
+		log.Printf("[AuthService] failed to invalidate reset links of %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// sendAccountEmail sends in the background, SMTP yg lemot gk boleh nahan response
+func (s *AuthService) sendAccountEmail(template string, user *models.User, link, expiresIn string) {
+	msg, err := mailer.Render(template, user.Email, mailer.AccountEmail{Recipient: user.Username, Link: link, ExpiresIn: expiresIn})
+	if err != nil {
+		log.Printf("[AuthService] failed to render %s email: %v", template, err)
+		return
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("[AuthService] failed to send %s email to %s: %v", template, user.ID, err)
+		}
+	}()
+}
+
+// PurgeExpiredTokens removes refresh tokens, sessions, reset & verification links past their expiry, dijalanin scheduler
+func (s *AuthService) PurgeExpiredTokens() error {
+	now := time.Now()
+	if err := s.refreshTokenRepo.DeleteExpired(now); err != nil {
+		return err
+	}
+	if err := s.passwordResetRepo.DeleteExpired(now); err != nil {
+		return err
+	}
+	if err := s.verificationRepo.DeleteExpired(now); err != nil {
+		return err
+	}
+	return s.sessionRepo.DeleteExpired(now)
+}
+
+// startSession records a new session (login/register) and issues its first tokens
+func (s *AuthService) startSession(user *models.User, client ClientInfo) (*AuthResponse, error) {
+	now := time.Now()
+	session := &models.Session{
+		UserID:     user.ID,
+		UserAgent:  truncate(client.UserAgent, 255),
+		IP:         client.IP,
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+		LastSeenAt: now,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, errors.New("Failed to generate token")
+	}
+	return s.issueTokens(user, session.ID)
+}
+
+// issueTokens hands out an access token + a new refresh token in the given session
+func (s *AuthService) issueTokens(user *models.User, familyID string) (*AuthResponse, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.New("Failed to generate token")
+	}
+	refreshToken := refreshTokenPrefix + hex.EncodeToString(b)
+	err := s.refreshTokenRepo.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	})
+	if err != nil {
+		return nil, errors.New("Failed to generate token")
+	}
+
+	expiresAt := time.Now().Add(middleware.AccessTokenTTL)
+	token, err := middleware.GenerateToken(user.ID, user.Username, familyID, expiresAt)
+	if err != nil {
+		return nil, errors.New("Failed to generate token")
+	}
+
+	return &AuthResponse{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+		User:         *user,
+	}, nil
+}
+
+func (s *AuthService) revokeReusedFamily(token *models.RefreshToken) {
+	log.Printf("[AuthService] refresh token reuse detected for user %s, revoking session %s", token.UserID, token.FamilyID)
+	if err := s.sessionRepo.Revoke(token.FamilyID, "", time.Now()); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[AuthService] failed to revoke session %s: %v", token.FamilyID, err)
+	}
+}
+
+func validatePassword(password string) error {
+	if password == "" {
+		return errors.New("Password Required!")
+	}
+	if len(password) < 6 {
+		return errors.New("Password must be astleast 6 characters")
+	}
+	return nil
+}
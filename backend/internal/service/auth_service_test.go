@@ -0,0 +1,557 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"minitask/internal/mailer"
+	"minitask/internal/mailer/mailertest"
+	"minitask/internal/middleware"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type authUserRepo struct {
+	repository.UserRepository
+	users map[string]*models.User
+}
+
+func (r *authUserRepo) FindByID(id string) (*models.User, error) {
+	if user, ok := r.users[id]; ok {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *authUserRepo) SetPassword(id, hash string) error {
+	r.users[id].Password = hash
+	return nil
+}
+
+func (r *authUserRepo) SetEmailVerifiedAt(id string, at time.Time) error {
+	r.users[id].EmailVerifiedAt = &at
+	return nil
+}
+
+type fakeRefreshTokenRepo struct {
+	repository.RefreshTokenRepository
+	tokens map[string]*models.RefreshToken // by hash
+}
+
+func (r *fakeRefreshTokenRepo) Create(token *models.RefreshToken) error {
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) FindByTokenHash(hash string) (*models.RefreshToken, error) {
+	if token, ok := r.tokens[hash]; ok {
+		copied := *token
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRefreshTokenRepo) MarkUsed(id string, at time.Time) (bool, error) {
+	for _, token := range r.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			token.UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeSessionRepo struct {
+	repository.SessionRepository
+	sessions map[string]*models.Session
+}
+
+func (r *fakeSessionRepo) FindByID(id string) (*models.Session, error) {
+	if session, ok := r.sessions[id]; ok {
+		copied := *session
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSessionRepo) Touch(id, ip string, lastSeenAt time.Time, expiresAt *time.Time) error {
+	if session, ok := r.sessions[id]; ok {
+		session.LastSeenAt = lastSeenAt
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) Revoke(id, userID string, at time.Time) error {
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	session.RevokedAt = &at
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeAllForUser(userID, exceptID string, at time.Time) error {
+	for id, session := range r.sessions {
+		if session.UserID == userID && id != exceptID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+type fakePasswordResetRepo struct {
+	repository.PasswordResetRepository
+	tokens map[string]*models.PasswordResetToken // by hash
+}
+
+func (r *fakePasswordResetRepo) FindByTokenHash(hash string) (*models.PasswordResetToken, error) {
+	if token, ok := r.tokens[hash]; ok {
+		copied := *token
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePasswordResetRepo) MarkUsed(id string, at time.Time) (bool, error) {
+	for _, token := range r.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			token.UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakePasswordResetRepo) InvalidateForUser(userID string, at time.Time) error {
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &at
+		}
+	}
+	return nil
+}
+
+type fakeVerificationRepo struct {
+	repository.EmailVerificationRepository
+	tokens []*models.EmailVerificationToken
+}
+
+func (r *fakeVerificationRepo) Create(token *models.EmailVerificationToken) error {
+	if token.ID == "" {
+		token.ID = token.TokenHash
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakeVerificationRepo) FindByTokenHash(hash string) (*models.EmailVerificationToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeVerificationRepo) FindLatestByUserID(userID string) (*models.EmailVerificationToken, error) {
+	var latest *models.EmailVerificationToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && (latest == nil || token.CreatedAt.After(latest.CreatedAt)) {
+			latest = token
+		}
+	}
+	if latest == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return latest, nil
+}
+
+func (r *fakeVerificationRepo) CountSince(userID string, since time.Time) (int64, error) {
+	var count int64
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.CreatedAt.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeVerificationRepo) MarkUsed(id string, at time.Time) (bool, error) {
+	for _, token := range r.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			token.UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type authFixture struct {
+	service  *AuthService
+	users    *authUserRepo
+	tokens   *fakeRefreshTokenRepo
+	sessions *fakeSessionRepo
+	resets   *fakePasswordResetRepo
+	verifies *fakeVerificationRepo
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+	f := &authFixture{
+		users: &authUserRepo{users: map[string]*models.User{
+			"u1": {ID: "u1", Username: "alice", Email: "alice@example.com"},
+			"u2": {ID: "u2", Username: "bob", Email: "bob@example.com"},
+		}},
+		tokens: &fakeRefreshTokenRepo{tokens: map[string]*models.RefreshToken{}},
+		sessions: &fakeSessionRepo{sessions: map[string]*models.Session{
+			"s1": {ID: "s1", UserID: "u1", LastSeenAt: time.Now(), ExpiresAt: time.Now().Add(RefreshTokenTTL)},
+		}},
+		resets:   &fakePasswordResetRepo{tokens: map[string]*models.PasswordResetToken{}},
+		verifies: &fakeVerificationRepo{},
+	}
+	f.service = &AuthService{
+		userRepo:          f.users,
+		refreshTokenRepo:  f.tokens,
+		sessionRepo:       f.sessions,
+		passwordResetRepo: f.resets,
+		verificationRepo:  f.verifies,
+	}
+	return f
+}
+
+// addRefreshToken stores a token in session s1, usedAgo < 0 = belum pernah dipake
+func (f *authFixture) addRefreshToken(raw string, usedAgo time.Duration) *models.RefreshToken {
+	token := &models.RefreshToken{
+		ID:        raw,
+		UserID:    "u1",
+		FamilyID:  "s1",
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}
+	if usedAgo >= 0 {
+		usedAt := time.Now().Add(-usedAgo)
+		token.UsedAt = &usedAt
+	}
+	f.tokens.tokens[token.TokenHash] = token
+	return token
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	f := newAuthFixture(t)
+	old := f.addRefreshToken("mt_rt_old", -1)
+
+	response, err := f.service.Refresh(&RefreshRequest{RefreshToken: "mt_rt_old"}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if response.RefreshToken == "" || response.RefreshToken == "mt_rt_old" {
+		t.Fatalf("refresh token = %q, want a new one", response.RefreshToken)
+	}
+	if old.UsedAt == nil {
+		t.Error("old token was not marked used")
+	}
+	next := f.tokens.tokens[hashToken(response.RefreshToken)]
+	if next == nil || next.FamilyID != "s1" {
+		t.Errorf("new token = %+v, want it in family s1", next)
+	}
+}
+
+func TestRefreshReuseWithinGraceIsAccepted(t *testing.T) {
+	f := newAuthFixture(t)
+	// tab lain barusan refresh pake token yg sama
+	f.addRefreshToken("mt_rt_old", 2*time.Second)
+
+	response, err := f.service.Refresh(&RefreshRequest{RefreshToken: "mt_rt_old"}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v, want the reuse inside the grace window accepted", err)
+	}
+	if next := f.tokens.tokens[hashToken(response.RefreshToken)]; next == nil || next.FamilyID != "s1" {
+		t.Errorf("new token = %+v, want it in family s1", next)
+	}
+	if f.sessions.sessions["s1"].RevokedAt != nil {
+		t.Error("session was revoked for a reuse inside the grace window")
+	}
+}
+
+func TestRefreshReuseAfterGraceRevokesFamily(t *testing.T) {
+	f := newAuthFixture(t)
+	f.addRefreshToken("mt_rt_old", refreshReuseGrace+time.Second)
+
+	_, err := f.service.Refresh(&RefreshRequest{RefreshToken: "mt_rt_old"}, ClientInfo{})
+	if !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if f.sessions.sessions["s1"].RevokedAt == nil {
+		t.Fatal("session was not revoked after the token was reused")
+	}
+
+	// access token dari sesi itu juga ikut mati
+	err = f.service.ValidateToken(&middleware.JWTClaims{UserID: "u1", SessionID: "s1"})
+	if err == nil {
+		t.Error("ValidateToken() accepted a token of the revoked session")
+	}
+}
+
+func TestRefreshRejectsRevokedAndExpiredTokens(t *testing.T) {
+	f := newAuthFixture(t)
+	revoked := f.addRefreshToken("mt_rt_revoked", -1)
+	now := time.Now()
+	revoked.RevokedAt = &now
+	expired := f.addRefreshToken("mt_rt_expired", -1)
+	expired.ExpiresAt = now.Add(-time.Minute)
+
+	for _, raw := range []string{"mt_rt_revoked", "mt_rt_expired", "mt_rt_unknown"} {
+		if _, err := f.service.Refresh(&RefreshRequest{RefreshToken: raw}, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("Refresh(%s) error = %v, want ErrInvalidRefreshToken", raw, err)
+		}
+	}
+}
+
+func TestValidateTokenChecksSession(t *testing.T) {
+	f := newAuthFixture(t)
+	revokedAt := time.Now()
+	f.sessions.sessions["s2"] = &models.Session{ID: "s2", UserID: "u1", RevokedAt: &revokedAt}
+	f.sessions.sessions["s3"] = &models.Session{ID: "s3", UserID: "u2", LastSeenAt: time.Now()}
+
+	tests := map[string]struct {
+		claims  middleware.JWTClaims
+		wantErr bool
+	}{
+		"active session":          {claims: middleware.JWTClaims{UserID: "u1", SessionID: "s1"}},
+		"no sid":                  {claims: middleware.JWTClaims{UserID: "u1"}, wantErr: true},
+		"unknown sid":             {claims: middleware.JWTClaims{UserID: "u1", SessionID: "nope"}, wantErr: true},
+		"revoked session":         {claims: middleware.JWTClaims{UserID: "u1", SessionID: "s2"}, wantErr: true},
+		"session of another user": {claims: middleware.JWTClaims{UserID: "u1", SessionID: "s3"}, wantErr: true},
+		"user deleted":            {claims: middleware.JWTClaims{UserID: "gone", SessionID: "s1"}, wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := f.service.ValidateToken(&tt.claims)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTokenTouchesStaleSession(t *testing.T) {
+	f := newAuthFixture(t)
+	stale := time.Now().Add(-2 * sessionTouchInterval)
+	f.sessions.sessions["s1"].LastSeenAt = stale
+
+	if err := f.service.ValidateToken(&middleware.JWTClaims{UserID: "u1", SessionID: "s1"}); err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if !f.sessions.sessions["s1"].LastSeenAt.After(stale) {
+		t.Error("last seen was not updated")
+	}
+}
+
+func (f *authFixture) addResetLink(raw string, expiresIn time.Duration) *models.PasswordResetToken {
+	token := &models.PasswordResetToken{
+		ID:        raw,
+		UserID:    "u1",
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(expiresIn),
+	}
+	f.resets.tokens[token.TokenHash] = token
+	return token
+}
+
+func TestResetPassword(t *testing.T) {
+	f := newAuthFixture(t)
+	f.sessions.sessions["s2"] = &models.Session{ID: "s2", UserID: "u1"}
+	f.addResetLink("mt_pr_first", PasswordResetTTL)
+	other := f.addResetLink("mt_pr_second", PasswordResetTTL)
+
+	if err := f.service.ResetPassword(&ResetPasswordRequest{Token: "mt_pr_first", NewPassword: "new-secret"}); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	user := f.users.users["u1"]
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-secret")) != nil {
+		t.Error("password was not updated")
+	}
+	// link nya dikirim ke email, jadi sekalian bukti email nya punya dia
+	if !user.EmailVerified() {
+		t.Error("user was not marked verified")
+	}
+	for id, session := range f.sessions.sessions {
+		if session.RevokedAt == nil {
+			t.Errorf("session %s was not signed out", id)
+		}
+	}
+	if other.UsedAt == nil {
+		t.Error("the other open reset link was not invalidated")
+	}
+
+	// single use, link lain yg kebuka juga udah mati
+	for _, raw := range []string{"mt_pr_first", "mt_pr_second"} {
+		err := f.service.ResetPassword(&ResetPasswordRequest{Token: raw, NewPassword: "another-secret"})
+		if !errors.Is(err, ErrInvalidResetToken) {
+			t.Errorf("ResetPassword(%s) again error = %v, want ErrInvalidResetToken", raw, err)
+		}
+	}
+}
+
+func TestResetPasswordRejectsExpiredLink(t *testing.T) {
+	f := newAuthFixture(t)
+	f.addResetLink("mt_pr_old", -time.Minute)
+
+	err := f.service.ResetPassword(&ResetPasswordRequest{Token: "mt_pr_old", NewPassword: "new-secret"})
+	if !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("ResetPassword() error = %v, want ErrInvalidResetToken", err)
+	}
+	if f.users.users["u1"].Password != "" {
+		t.Error("password was changed by an expired link")
+	}
+}
+
+func TestResetPasswordInvalidPasswordKeepsLink(t *testing.T) {
+	f := newAuthFixture(t)
+	link := f.addResetLink("mt_pr_link", PasswordResetTTL)
+
+	if err := f.service.ResetPassword(&ResetPasswordRequest{Token: "mt_pr_link", NewPassword: "123"}); err == nil {
+		t.Fatal("ResetPassword() accepted a too short password")
+	}
+	// typo di password baru gk boleh ngabisin link nya
+	if link.UsedAt != nil {
+		t.Error("link was used up by a rejected password")
+	}
+	if err := f.service.ResetPassword(&ResetPasswordRequest{Token: "mt_pr_link", NewPassword: "new-secret"}); err != nil {
+		t.Errorf("ResetPassword() retry error = %v", err)
+	}
+}
+
+// addVerifyLink stores a link for u1 sent to email, created ago
+func (f *authFixture) addVerifyLink(raw, email string, ago time.Duration) *models.EmailVerificationToken {
+	token := &models.EmailVerificationToken{
+		ID:        raw,
+		UserID:    "u1",
+		Email:     email,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(EmailVerifyTTL - ago),
+		CreatedAt: time.Now().Add(-ago),
+	}
+	f.verifies.tokens = append(f.verifies.tokens, token)
+	return token
+}
+
+var verifyLinkToken = regexp.MustCompile(`verify-email\?token=(mt_ev_[0-9a-f]+)`)
+
+func TestResendThenVerifyEmail(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "https://app.test")
+	server := mailertest.NewServer(t)
+	f := newAuthFixture(t)
+	f.service.mailer = &mailer.SMTPMailer{Addr: server.Addr, From: "MiniTask <no-reply@minitask.test>"}
+
+	if err := f.service.ResendVerification("u1"); err != nil {
+		t.Fatalf("ResendVerification() error = %v", err)
+	}
+	envelope := server.Next(t, 5*time.Second)
+	if len(envelope.To) != 1 || envelope.To[0] != "alice@example.com" {
+		t.Errorf("sent to %v, want alice@example.com", envelope.To)
+	}
+	match := verifyLinkToken.FindStringSubmatch(envelope.Data)
+	if match == nil {
+		t.Fatalf("no verify link in email:\n%s", envelope.Data)
+	}
+
+	user, err := f.service.VerifyEmail(&VerifyEmailRequest{Token: match[1]})
+	if err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if !user.EmailVerified() || !f.users.users["u1"].EmailVerified() {
+		t.Error("user was not marked verified")
+	}
+	if user.Password != "" {
+		t.Error("password hash leaked in the response")
+	}
+
+	// single use
+	if _, err := f.service.VerifyEmail(&VerifyEmailRequest{Token: match[1]}); !errors.Is(err, ErrInvalidVerifyToken) {
+		t.Errorf("VerifyEmail() again error = %v, want ErrInvalidVerifyToken", err)
+	}
+	// udah verified, gk ada yg perlu dikirim lagi
+	if err := f.service.ResendVerification("u1"); err == nil {
+		t.Error("ResendVerification() sent a link to a verified user")
+	}
+}
+
+func TestVerifyEmailRejectsExpiredLink(t *testing.T) {
+	f := newAuthFixture(t)
+	f.addVerifyLink("mt_ev_old", "alice@example.com", EmailVerifyTTL+time.Minute)
+
+	if _, err := f.service.VerifyEmail(&VerifyEmailRequest{Token: "mt_ev_old"}); !errors.Is(err, ErrInvalidVerifyToken) {
+		t.Fatalf("VerifyEmail() error = %v, want ErrInvalidVerifyToken", err)
+	}
+	if f.users.users["u1"].EmailVerified() {
+		t.Error("user was verified by an expired link")
+	}
+}
+
+func TestVerifyEmailRejectsLinkAfterEmailChanged(t *testing.T) {
+	f := newAuthFixture(t)
+	// link nya dikirim ke alamat lama, terus email nya diganti
+	link := f.addVerifyLink("mt_ev_link", "old@example.com", time.Minute)
+
+	if _, err := f.service.VerifyEmail(&VerifyEmailRequest{Token: "mt_ev_link"}); !errors.Is(err, ErrInvalidVerifyToken) {
+		t.Fatalf("VerifyEmail() error = %v, want ErrInvalidVerifyToken", err)
+	}
+	if f.users.users["u1"].EmailVerified() || link.UsedAt != nil {
+		t.Error("link for the old address verified the new one")
+	}
+
+	// case email gk ngaruh
+	f.addVerifyLink("mt_ev_current", "Alice@Example.com", time.Minute)
+	if _, err := f.service.VerifyEmail(&VerifyEmailRequest{Token: "mt_ev_current"}); err != nil {
+		t.Errorf("VerifyEmail() error = %v for a link to the current address", err)
+	}
+}
+
+func TestResendVerificationCooldown(t *testing.T) {
+	f := newAuthFixture(t)
+	f.addVerifyLink("mt_ev_recent", "alice@example.com", 10*time.Second)
+
+	if err := f.service.ResendVerification("u1"); !errors.Is(err, ErrVerificationThrottled) {
+		t.Fatalf("ResendVerification() error = %v, want ErrVerificationThrottled", err)
+	}
+	if len(f.verifies.tokens) != 1 {
+		t.Errorf("links = %d, want no new link inside the cooldown", len(f.verifies.tokens))
+	}
+}
+
+func TestResendVerificationDailyCap(t *testing.T) {
+	server := mailertest.NewServer(t)
+	f := newAuthFixture(t)
+	f.service.mailer = &mailer.SMTPMailer{Addr: server.Addr, From: "MiniTask <no-reply@minitask.test>"}
+	for i := 1; i < maxVerificationPerDay; i++ {
+		f.addVerifyLink("mt_ev_"+string(rune('a'+i)), "alice@example.com", time.Duration(i)*time.Hour)
+	}
+	// yg kemarin gk diitung
+	f.addVerifyLink("mt_ev_yesterday", "alice@example.com", 25*time.Hour)
+
+	if err := f.service.ResendVerification("u1"); err != nil {
+		t.Fatalf("ResendVerification() error = %v, want the last one of the day sent", err)
+	}
+	server.Next(t, 5*time.Second)
+
+	// biar gk kena cooldown, yg barusan dianggep udah lewat semenit
+	f.verifies.tokens[len(f.verifies.tokens)-1].CreatedAt = time.Now().Add(-2 * verificationCooldown)
+	if err := f.service.ResendVerification("u1"); !errors.Is(err, ErrVerificationThrottled) {
+		t.Fatalf("ResendVerification() error = %v, want ErrVerificationThrottled over the daily cap", err)
+	}
+	if server.Pending() != 0 {
+		t.Error("an email was sent over the daily cap")
+	}
+}
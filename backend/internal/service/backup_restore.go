@@ -0,0 +1,278 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"minitask/internal/models"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// backupArchive is a parsed & validated archive, belum ada yg ditulis ke db
+type backupArchive struct {
+	manifest    BackupManifest
+	workspace   BackupWorkspace
+	members     []BackupMember
+	users       map[string]BackupUser
+	tasks       []BackupTask
+	attachments []BackupAttachment
+	files       map[string]*zip.File
+}
+
+// Restore recreates a backup archive as a brand new workspace owned by userID. Semua id baru dan
+// semuanya dalam satu transaction: gagal = gk ada yg kesimpen. Archive nya bisa dibikin tangan, jadi
+// gk ada akun yg dicocokin lewat email: semua isi atas nama yg restore, member lama di-invite ulang.
+// Gk ada notifikasi / webhook / realtime event, ini bukan aktivitas baru
+func (s *BackupService) Restore(userID string, r io.ReaderAt, size int64, name string) (*RestoreResult, error) {
+	archive, err := readBackupArchive(r, size)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = archive.workspace.Name
+	}
+	prefix := archive.workspace.KeyPrefix
+	if !keyPrefixPattern.MatchString(prefix) {
+		prefix = models.GenerateKeyPrefix(name)
+	}
+
+	result := &RestoreResult{OriginalMembers: []string{}}
+
+	workspace := &models.Workspace{
+		Name:        name,
+		Description: archive.workspace.Description,
+		OwnerID:     userID,
+		KeyPrefix:   prefix,
+	}
+	// member lama cuma dilaporin (isinya dari archive sendiri), masuk lagi lewat invite biasa
+	for _, member := range archive.members {
+		if user, ok := archive.users[member.UserID]; ok && !user.IsBot {
+			result.OriginalMembers = append(result.OriginalMembers, user.Username+" <"+user.Email+">")
+		}
+	}
+
+	numbers := assignBackupNumbers(archive.tasks)
+	for _, number := range numbers {
+		if number > workspace.TaskCounter {
+			workspace.TaskCounter = number
+		}
+	}
+
+	var stored []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		owner := &models.WorkspaceMember{WorkspaceID: workspace.ID, UserID: userID, Role: models.RoleOwner}
+		if err := tx.Omit(clause.Associations).Create(owner).Error; err != nil {
+			return err
+		}
+
+		tasks := map[string]string{}    // old task id -> new
+		comments := map[string]string{} // old comment id -> new
+		for i, backupTask := range archive.tasks {
+			task := &models.Task{
+				Title:       backupTask.Title,
+				Description: backupTask.Description,
+				Status:      backupTask.Status,
+				Order:       backupTask.Order,
+				UserID:      userID,
+				WorkspaceID: &workspace.ID,
+				DueDate:     backupTask.DueDate,
+				Labels:      fitLabels(backupTask.Labels),
+				Number:      &numbers[i],
+				Key:         fmt.Sprintf("%s-%d", prefix, numbers[i]),
+				CreatedAt:   backupTask.CreatedAt,
+				UpdatedAt:   backupTask.UpdatedAt,
+			}
+			if err := tx.Omit(clause.Associations).Create(task).Error; err != nil {
+				return err
+			}
+			tasks[backupTask.ID] = task.ID
+			result.Tasks++
+
+			for _, backupComment := range backupTask.Comments {
+				comment := &models.Comment{
+					Content:   backupComment.Content,
+					TaskID:    task.ID,
+					UserID:    userID,
+					EditedAt:  backupComment.EditedAt,
+					CreatedAt: backupComment.CreatedAt,
+					UpdatedAt: backupComment.UpdatedAt,
+				}
+				// comment atas nama yg restore, nama penulis aslinya ditulis di isi
+				if backupComment.AuthorID != userID {
+					comment.Content = fmt.Sprintf("**%s**:\n\n%s", backupUsername(archive.users, backupComment.AuthorID), backupComment.Content)
+				}
+				if err := tx.Omit(clause.Associations).Create(comment).Error; err != nil {
+					return err
+				}
+				comments[backupComment.ID] = comment.ID
+				result.Comments++
+			}
+		}
+
+		for _, backupAttachment := range archive.attachments {
+			attachment := &models.Attachment{
+				ID:           uuid.New().String(),
+				TaskID:       tasks[backupAttachment.TaskID],
+				UploadedByID: userID,
+				Filename:     backupAttachment.Filename,
+				ContentType:  backupAttachment.ContentType,
+				CreatedAt:    backupAttachment.CreatedAt,
+			}
+			if backupAttachment.CommentID != nil {
+				if commentID, ok := comments[*backupAttachment.CommentID]; ok {
+					attachment.CommentID = &commentID
+				}
+			}
+
+			file, err := archive.files["attachments/"+backupAttachment.ID].Open()
+			if err != nil {
+				return err
+			}
+			storagePath, size, err := s.attachmentService.store(attachment.TaskID, attachment.ID, file)
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("attachment %q: %w", backupAttachment.Filename, err)
+			}
+			stored = append(stored, storagePath)
+			attachment.StoragePath = storagePath
+			attachment.Size = size
+			if err := tx.Create(attachment).Error; err != nil {
+				return err
+			}
+			result.Attachments++
+		}
+		return nil
+	})
+	if err != nil {
+		for _, storagePath := range stored {
+			s.attachmentService.remove(storagePath)
+		}
+		if errors.Is(err, ErrAttachmentTooLarge) {
+			return nil, err
+		}
+		log.Printf("[BackupService] restore by %s failed: %v", userID, err)
+		return nil, errors.New("failed to restore workspace")
+	}
+
+	s.workspaceService.recordWorkspace(userID, workspace.ID, models.ActivityCreated, nil, WorkspaceSnapshot(workspace))
+	result.Workspace, err = s.workspaceRepo.FindByID(workspace.ID)
+	if err != nil {
+		return nil, errors.New("failed to load restored workspace")
+	}
+	return result, nil
+}
+
+func readBackupArchive(r io.ReaderAt, size int64) (*backupArchive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a zip file", ErrBackupInvalid)
+	}
+	archive := &backupArchive{files: map[string]*zip.File{}, users: map[string]BackupUser{}}
+	for _, file := range zr.File {
+		archive.files[file.Name] = file
+	}
+
+	if err := archive.decode("manifest.json", &archive.manifest); err != nil {
+		return nil, err
+	}
+	if archive.manifest.Format != BackupFormat {
+		return nil, fmt.Errorf("%w: not a minitask workspace backup", ErrBackupInvalid)
+	}
+	if archive.manifest.Version < 1 || archive.manifest.Version > BackupVersion {
+		return nil, fmt.Errorf("%w: unsupported backup version %d", ErrBackupInvalid, archive.manifest.Version)
+	}
+
+	var users []BackupUser
+	for name, v := range map[string]interface{}{
+		"workspace.json":   &archive.workspace,
+		"members.json":     &archive.members,
+		"users.json":       &users,
+		"tasks.json":       &archive.tasks,
+		"attachments.json": &archive.attachments,
+	} {
+		if err := archive.decode(name, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, user := range users {
+		archive.users[user.ID] = user
+	}
+
+	if strings.TrimSpace(archive.workspace.Name) == "" {
+		return nil, fmt.Errorf("%w: workspace name is missing", ErrBackupInvalid)
+	}
+	taskIDs := map[string]bool{}
+	for i := range archive.tasks {
+		task := &archive.tasks[i]
+		status, ok := parseImportStatus(task.Status)
+		if task.ID == "" || strings.TrimSpace(task.Title) == "" || !ok {
+			return nil, fmt.Errorf("%w: task %d is invalid", ErrBackupInvalid, i+1)
+		}
+		task.Status = status
+		taskIDs[task.ID] = true
+	}
+	for _, attachment := range archive.attachments {
+		if !taskIDs[attachment.TaskID] || archive.files["attachments/"+attachment.ID] == nil {
+			return nil, fmt.Errorf("%w: attachment %q is missing", ErrBackupInvalid, attachment.Filename)
+		}
+	}
+	return archive, nil
+}
+
+func (a *backupArchive) decode(name string, v interface{}) error {
+	file, ok := a.files[name]
+	if !ok {
+		return fmt.Errorf("%w: %s is missing", ErrBackupInvalid, name)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %s is unreadable", ErrBackupInvalid, name)
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(io.LimitReader(rc, maxBackupEntry)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s is not valid json", ErrBackupInvalid, name)
+	}
+	return nil
+}
+
+// assignBackupNumbers keeps the original task numbers (jadi WS-42 tetep WS-42), task tanpa
+// nomor atau nomor dobel dapet nomor baru setelah yg paling gede
+func assignBackupNumbers(tasks []BackupTask) []int {
+	numbers := make([]int, len(tasks))
+	used := map[int]bool{}
+	max := 0
+	for i, task := range tasks {
+		if task.Number != nil && *task.Number > 0 && !used[*task.Number] {
+			numbers[i] = *task.Number
+			used[*task.Number] = true
+			if *task.Number > max {
+				max = *task.Number
+			}
+		}
+	}
+	for i := range numbers {
+		if numbers[i] == 0 {
+			max++
+			numbers[i] = max
+		}
+	}
+	return numbers
+}
+
+func backupUsername(users map[string]BackupUser, id string) string {
+	if user, ok := users[id]; ok && user.Username != "" {
+		return user.Username
+	}
+	return "unknown"
+}
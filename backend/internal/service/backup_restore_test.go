@@ -0,0 +1,228 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"minitask/internal/models"
+	"minitask/internal/repository"
+)
+
+type backupWorkspaceRepo struct {
+	repository.WorkspaceRepository
+	members []models.WorkspaceMember
+}
+
+func (r *backupWorkspaceRepo) FindAllMembers(workspaceID string) ([]models.WorkspaceMember, error) {
+	return r.members, nil
+}
+
+type backupTaskRepo struct {
+	repository.TaskRepository
+	tasks []models.Task
+}
+
+func (r *backupTaskRepo) StreamByWorkspaceID(workspaceID string, batchSize int, fn func([]models.Task) error) error {
+	for start := 0; start < len(r.tasks); start += batchSize {
+		end := start + batchSize
+		if end > len(r.tasks) {
+			end = len(r.tasks)
+		}
+		if err := fn(r.tasks[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type backupAttachmentRepo struct {
+	repository.AttachmentRepository
+}
+
+func (r *backupAttachmentRepo) FindByWorkspaceID(workspaceID string) ([]models.Attachment, error) {
+	return nil, nil
+}
+
+// zipArchive builds an archive by hand, persis kayak orang yg ngedit backup nya sendiri
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func validArchiveFiles() map[string]string {
+	return map[string]string{
+		"manifest.json":    `{"format":"minitask-workspace-backup","version":1}`,
+		"workspace.json":   `{"name":"Launch","keyPrefix":"LCH"}`,
+		"members.json":     `[{"userId":"u1","role":"owner"},{"userId":"u2","role":"member"},{"userId":"bot","role":"member"}]`,
+		"users.json":       `[{"id":"u1","username":"alice","email":"alice@example.com"},{"id":"u2","username":"bob","email":"bob@example.com"},{"id":"bot","username":"ci","isBot":true}]`,
+		"tasks.json":       `[{"id":"t1","number":3,"title":"Ship it","status":"In Progress"}]`,
+		"attachments.json": `[]`,
+	}
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	three := 3
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := &BackupService{
+		workspaceRepo: &backupWorkspaceRepo{members: []models.WorkspaceMember{
+			{UserID: "u1", Role: models.RoleOwner, User: models.User{ID: "u1", Username: "alice", Email: "alice@example.com"}},
+			{UserID: "u2", Role: models.RoleMember, User: models.User{ID: "u2", Username: "bob", Email: "bob@example.com"}},
+		}},
+		taskRepo: &backupTaskRepo{tasks: []models.Task{
+			{ID: "t1", Number: &three, Key: "LCH-3", Title: "Ship it", Status: models.StatusInProgress, UserID: "u1", CreatedAt: created,
+				Comments: []models.Comment{{ID: "c1", UserID: "u2", Content: "lgtm", User: models.User{ID: "u2", Username: "bob"}}}},
+			{ID: "t2", Title: "Write docs", Status: models.StatusNotStarted, UserID: "u2"},
+		}},
+		attachmentRepo: &backupAttachmentRepo{},
+	}
+
+	var buf bytes.Buffer
+	if err := s.Backup(&models.Workspace{ID: "w1", Name: "Launch", KeyPrefix: "LCH"}, &buf); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	archive, err := readBackupArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("readBackupArchive() error = %v", err)
+	}
+
+	if archive.workspace.Name != "Launch" || archive.workspace.KeyPrefix != "LCH" {
+		t.Errorf("workspace = %+v", archive.workspace)
+	}
+	if len(archive.members) != 2 || len(archive.users) != 2 {
+		t.Errorf("members = %d, users = %d, want 2 and 2", len(archive.members), len(archive.users))
+	}
+	if len(archive.tasks) != 2 {
+		t.Fatalf("tasks = %d, want 2", len(archive.tasks))
+	}
+	task := archive.tasks[0]
+	if task.Key != "LCH-3" || task.Status != models.StatusInProgress || !task.CreatedAt.Equal(created) {
+		t.Errorf("task = %+v", task)
+	}
+	if len(task.Comments) != 1 || task.Comments[0].AuthorID != "u2" || task.Comments[0].Content != "lgtm" {
+		t.Errorf("comments = %+v", task.Comments)
+	}
+	if numbers := assignBackupNumbers(archive.tasks); numbers[0] != 3 || numbers[1] != 4 {
+		t.Errorf("numbers = %v, want [3 4]", numbers)
+	}
+}
+
+func TestReadBackupArchiveNormalizesStatus(t *testing.T) {
+	data := zipArchive(t, validArchiveFiles())
+	archive, err := readBackupArchive(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("readBackupArchive() error = %v", err)
+	}
+	if archive.tasks[0].Status != models.StatusInProgress {
+		t.Errorf("status = %q, want %q", archive.tasks[0].Status, models.StatusInProgress)
+	}
+	if archive.users["bot"].Username != "ci" || !archive.users["bot"].IsBot {
+		t.Errorf("users = %+v", archive.users)
+	}
+}
+
+func TestRestoreRejectsInvalidArchives(t *testing.T) {
+	tests := map[string]struct {
+		edit func(files map[string]string)
+		want string
+	}{
+		"wrong format": {
+			edit: func(f map[string]string) { f["manifest.json"] = `{"format":"something-else","version":1}` },
+			want: "not a minitask workspace backup",
+		},
+		"newer version": {
+			edit: func(f map[string]string) { f["manifest.json"] = `{"format":"minitask-workspace-backup","version":99}` },
+			want: "unsupported backup version 99",
+		},
+		"missing file": {
+			edit: func(f map[string]string) { delete(f, "tasks.json") },
+			want: "tasks.json is missing",
+		},
+		"broken json": {
+			edit: func(f map[string]string) { f["users.json"] = `[{"id":` },
+			want: "users.json is not valid json",
+		},
+		"no workspace name": {
+			edit: func(f map[string]string) { f["workspace.json"] = `{"name":"  "}` },
+			want: "workspace name is missing",
+		},
+		"unknown status": {
+			edit: func(f map[string]string) { f["tasks.json"] = `[{"id":"t1","title":"Ship it","status":"someday"}]` },
+			want: "task 1 is invalid",
+		},
+		"task without title": {
+			edit: func(f map[string]string) { f["tasks.json"] = `[{"id":"t1","title":" ","status":"done"}]` },
+			want: "task 1 is invalid",
+		},
+		"attachment without file": {
+			edit: func(f map[string]string) {
+				f["attachments.json"] = `[{"id":"a1","taskId":"t1","filename":"spec.pdf"}]`
+			},
+			want: `attachment "spec.pdf" is missing`,
+		},
+		"attachment of unknown task": {
+			edit: func(f map[string]string) {
+				f["attachments.json"] = `[{"id":"a1","taskId":"t9","filename":"spec.pdf"}]`
+				f["attachments/a1"] = "%PDF"
+			},
+			want: `attachment "spec.pdf" is missing`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			files := validArchiveFiles()
+			tt.edit(files)
+			data := zipArchive(t, files)
+
+			// archive yg invalid ditolak sebelum nyentuh db, jadi service kosong cukup
+			_, err := (&BackupService{}).Restore("u1", bytes.NewReader(data), int64(len(data)), "")
+			if !errors.Is(err, ErrBackupInvalid) {
+				t.Fatalf("Restore() error = %v, want ErrBackupInvalid", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Restore() error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("not a zip", func(t *testing.T) {
+		data := []byte("definitely not a zip")
+		_, err := (&BackupService{}).Restore("u1", bytes.NewReader(data), int64(len(data)), "")
+		if !errors.Is(err, ErrBackupInvalid) {
+			t.Fatalf("Restore() error = %v, want ErrBackupInvalid", err)
+		}
+	})
+}
+
+func TestAssignBackupNumbers(t *testing.T) {
+	n := func(v int) *int { return &v }
+	tasks := []BackupTask{
+		{Number: n(7)},
+		{Number: nil},
+		{Number: n(7)}, // dobel, dapet nomor baru
+		{Number: n(2)},
+		{Number: n(0)},
+	}
+	got := assignBackupNumbers(tasks)
+	want := []int{7, 8, 9, 2, 10}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("assignBackupNumbers() = %v, want %v", got, want)
+		}
+	}
+}
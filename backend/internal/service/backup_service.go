@@ -0,0 +1,330 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	BackupFormat = "minitask-workspace-backup"
+	// BackupVersion naik kalo isi archive berubah gk backward compatible, restore nolak versi yg lebih baru
+	BackupVersion = 1
+
+	backupBatchSize = 200
+	// maxBackupEntry batas satu file json di dalam archive pas di-decompress, jaga2 zip bomb
+	maxBackupEntry = 64 << 20
+)
+
+// ErrBackupInvalid wraps everything wrong with an uploaded archive, handler nya balikin 400
+var ErrBackupInvalid = errors.New("invalid backup archive")
+
+type BackupService struct {
+	db                *gorm.DB
+	workspaceRepo     repository.WorkspaceRepository
+	taskRepo          repository.TaskRepository
+	userRepo          repository.UserRepository
+	attachmentRepo    repository.AttachmentRepository
+	attachmentService *AttachmentService
+	workspaceService  *WorkspaceService
+}
+
+func NewBackupService(
+	db *gorm.DB,
+	workspaceRepo repository.WorkspaceRepository,
+	taskRepo repository.TaskRepository,
+	userRepo repository.UserRepository,
+	attachmentRepo repository.AttachmentRepository,
+	attachmentService *AttachmentService,
+	workspaceService *WorkspaceService,
+) *BackupService {
+	return &BackupService{
+		db:                db,
+		workspaceRepo:     workspaceRepo,
+		taskRepo:          taskRepo,
+		userRepo:          userRepo,
+		attachmentRepo:    attachmentRepo,
+		attachmentService: attachmentService,
+		workspaceService:  workspaceService,
+	}
+}
+
+// Archive layout (zip):
+//
+//	manifest.json     format, version, exportedAt
+//	workspace.json    name, description, keyPrefix
+//	members.json      userId, role, joinedAt
+//	users.json        every user referenced anywhere in the archive
+//	tasks.json        tasks with their comments
+//	attachments.json  metadata, file nya di attachments/<id>
+type BackupManifest struct {
+	Format      string    `json:"format"`
+	Version     int       `json:"version"`
+	ExportedAt  time.Time `json:"exportedAt"`
+	WorkspaceID string    `json:"workspaceId"`
+}
+
+type BackupWorkspace struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	KeyPrefix   string    `json:"keyPrefix"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type BackupMember struct {
+	UserID   string    `json:"userId"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+type BackupUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	IsBot    bool   `json:"isBot"`
+}
+
+type BackupTask struct {
+	ID          string          `json:"id"`
+	Number      *int            `json:"number"`
+	Key         string          `json:"key"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Status      string          `json:"status"`
+	Order       int             `json:"order"`
+	Labels      []string        `json:"labels"`
+	CreatedByID string          `json:"createdById"`
+	AssigneeID  *string         `json:"assigneeId"`
+	DueDate     *time.Time      `json:"dueDate"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	Comments    []BackupComment `json:"comments"`
+}
+
+type BackupComment struct {
+	ID        string     `json:"id"`
+	AuthorID  string     `json:"authorId"`
+	Content   string     `json:"content"`
+	EditedAt  *time.Time `json:"editedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+type BackupAttachment struct {
+	ID           string    `json:"id"`
+	TaskID       string    `json:"taskId"`
+	CommentID    *string   `json:"commentId"`
+	UploadedByID string    `json:"uploadedById"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type RestoreResult struct {
+	Workspace   *models.Workspace `json:"workspace"`
+	Tasks       int               `json:"tasks"`
+	Comments    int               `json:"comments"`
+	Attachments int               `json:"attachments"`
+	// OriginalMembers is who was in the backed up workspace, buat di-invite ulang
+	OriginalMembers []string `json:"originalMembers"`
+}
+
+// CheckBackup is called before the response starts, cuma owner yg boleh backup (isinya email member)
+func (s *BackupService) CheckBackup(workspaceID, userID string) (*models.Workspace, error) {
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil || workspace.OwnerID != userID {
+		return nil, errors.New("workspace not found or not authorized")
+	}
+	return workspace, nil
+}
+
+// Backup streams the whole workspace as a zip archive. Task dibaca per batch, jadi gk pernah
+// ke-load semua sekaligus
+func (s *BackupService) Backup(workspace *models.Workspace, out io.Writer) error {
+	zw := zip.NewWriter(out)
+	users := map[string]BackupUser{}
+	addUser := func(user *models.User) {
+		if user != nil && user.ID != "" {
+			users[user.ID] = BackupUser{ID: user.ID, Username: user.Username, Email: user.Email, IsBot: user.IsBot}
+		}
+	}
+
+	manifest := BackupManifest{Format: BackupFormat, Version: BackupVersion, ExportedAt: time.Now().UTC(), WorkspaceID: workspace.ID}
+	if err := writeBackupJSON(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+	err := writeBackupJSON(zw, "workspace.json", BackupWorkspace{
+		Name:        workspace.Name,
+		Description: workspace.Description,
+		KeyPrefix:   workspace.KeyPrefix,
+		CreatedAt:   workspace.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	members, err := s.workspaceRepo.FindAllMembers(workspace.ID)
+	if err != nil {
+		return err
+	}
+	backupMembers := make([]BackupMember, 0, len(members))
+	for i := range members {
+		addUser(&members[i].User)
+		backupMembers = append(backupMembers, BackupMember{UserID: members[i].UserID, Role: members[i].Role, JoinedAt: members[i].JoinedAt})
+	}
+	if err := writeBackupJSON(zw, "members.json", backupMembers); err != nil {
+		return err
+	}
+
+	if err := s.backupTasks(zw, workspace.ID, addUser); err != nil {
+		return err
+	}
+
+	attachments, err := s.attachmentRepo.FindByWorkspaceID(workspace.ID)
+	if err != nil {
+		return err
+	}
+	backupAttachments := make([]BackupAttachment, 0, len(attachments))
+	files := make([]*models.Attachment, 0, len(attachments))
+	for i := range attachments {
+		attachment := &attachments[i]
+		// file yg udah hilang dari disk di-skip aja, daripada backup nya gagal total
+		if _, err := os.Stat(s.attachmentService.path(attachment)); err != nil {
+			log.Printf("[BackupService] skipping attachment %s of workspace %s: %v", attachment.ID, workspace.ID, err)
+			continue
+		}
+		files = append(files, attachment)
+		backupAttachments = append(backupAttachments, BackupAttachment{
+			ID:           attachment.ID,
+			TaskID:       attachment.TaskID,
+			CommentID:    attachment.CommentID,
+			UploadedByID: attachment.UploadedByID,
+			Filename:     attachment.Filename,
+			ContentType:  attachment.ContentType,
+			Size:         attachment.Size,
+			CreatedAt:    attachment.CreatedAt,
+		})
+		if _, ok := users[attachment.UploadedByID]; !ok {
+			if user, err := s.userRepo.FindByID(attachment.UploadedByID); err == nil {
+				addUser(user)
+			}
+		}
+	}
+
+	backupUsers := make([]BackupUser, 0, len(users))
+	for _, user := range users {
+		backupUsers = append(backupUsers, user)
+	}
+	if err := writeBackupJSON(zw, "users.json", backupUsers); err != nil {
+		return err
+	}
+	if err := writeBackupJSON(zw, "attachments.json", backupAttachments); err != nil {
+		return err
+	}
+	for _, attachment := range files {
+		if err := s.backupAttachmentFile(zw, attachment); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// backupTasks writes tasks.json as a json array, satu batch satu kali write
+func (s *BackupService) backupTasks(zw *zip.Writer, workspaceID string, addUser func(*models.User)) error {
+	w, err := zw.Create("tasks.json")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	first := true
+	err = s.taskRepo.StreamByWorkspaceID(workspaceID, backupBatchSize, func(tasks []models.Task) error {
+		for i := range tasks {
+			task := &tasks[i]
+			addUser(&task.User)
+			addUser(task.Assignee)
+
+			backupTask := BackupTask{
+				ID:          task.ID,
+				Number:      task.Number,
+				Key:         task.Key,
+				Title:       task.Title,
+				Description: task.Description,
+				Status:      task.Status,
+				Order:       task.Order,
+				Labels:      task.Labels,
+				CreatedByID: task.UserID,
+				AssigneeID:  task.AssigneeID,
+				DueDate:     task.DueDate,
+				CreatedAt:   task.CreatedAt,
+				UpdatedAt:   task.UpdatedAt,
+				Comments:    make([]BackupComment, 0, len(task.Comments)),
+			}
+			for j := range task.Comments {
+				comment := &task.Comments[j]
+				addUser(&comment.User)
+				backupTask.Comments = append(backupTask.Comments, BackupComment{
+					ID:        comment.ID,
+					AuthorID:  comment.UserID,
+					Content:   comment.Content,
+					EditedAt:  comment.EditedAt,
+					CreatedAt: comment.CreatedAt,
+					UpdatedAt: comment.UpdatedAt,
+				})
+			}
+
+			data, err := json.Marshal(backupTask)
+			if err != nil {
+				return err
+			}
+			if !first {
+				data = append([]byte(","), data...)
+			}
+			first = false
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+func (s *BackupService) backupAttachmentFile(zw *zip.Writer, attachment *models.Attachment) error {
+	file, err := os.Open(s.attachmentService.path(attachment))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "attachments/" + attachment.ID, Method: zip.Deflate, Modified: attachment.CreatedAt})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func writeBackupJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
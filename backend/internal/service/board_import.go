@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"minitask/internal/importer"
+	"minitask/internal/models"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	BoardSourceTrello  = "trello"
+	BoardSourceTodoist = "todoist"
+)
+
+// BoardImportOptions controls how a Trello board / Todoist backup becomes a workspace
+type BoardImportOptions struct {
+	Name string `json:"name"` // workspace name, default nama board/project
+	// ListStatuses maps a list (Trello) or project (Todoist) name to a status.
+	// List yg gk di-map ditebak dari nama nya ("Done" -> done, "Doing" -> in_progress)
+	ListStatuses    map[string]string `json:"listStatuses"`
+	IncludeArchived bool              `json:"includeArchived"`
+}
+
+type BoardImportResult struct {
+	Workspace *models.Workspace `json:"workspace"`
+	Tasks     int               `json:"tasks"`
+	Comments  int               `json:"comments"`
+	Skipped   int               `json:"skipped"`
+	// MatchedMembers punya akun di sini tapi gk otomatis dimasukin, owner yg invite sendiri
+	MatchedMembers   []string `json:"matchedMembers"`
+	UnmatchedMembers []string `json:"unmatchedMembers"`
+}
+
+// ImportBoard reads a Trello JSON or Todoist CSV/JSON export and creates one new workspace
+// from it through WorkspaceService. Kalo di tengah jalan gagal, workspace nya dihapus lagi
+func (s *ImportService) ImportBoard(userID, source, filename string, r io.Reader, opts *BoardImportOptions) (*BoardImportResult, error) {
+	board, err := parseBoard(source, filename, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(board.Cards) > MaxImportRows {
+		return nil, fmt.Errorf("export has more than %d tasks", MaxImportRows)
+	}
+	for list, status := range opts.ListStatuses {
+		if _, ok := parseImportStatus(status); !ok || status == "" {
+			return nil, fmt.Errorf("invalid status %q for list %q", status, list)
+		}
+	}
+
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		name = board.Name
+	}
+	workspace, err := s.workspaceService.Create(&CreateWorkspaceRequest{Name: name, Description: board.Description}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BoardImportResult{Workspace: workspace, MatchedMembers: []string{}, UnmatchedMembers: []string{}}
+	if err := s.importBoard(workspace.ID, userID, board, opts, result); err != nil {
+		if delErr := s.workspaceService.Delete(workspace.ID, userID); delErr != nil {
+			log.Printf("[ImportService] failed to clean up workspace %s after failed import: %v", workspace.ID, delErr)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *ImportService) importBoard(workspaceID, userID string, board *importer.Board, opts *BoardImportOptions, result *BoardImportResult) error {
+	members := map[string]string{} // external id -> user id, cuma yg import sendiri yg bisa langsung di-assign
+	for _, member := range board.Members {
+		label := member.FullName
+		if label == "" {
+			label = member.Username
+		}
+		if member.Email == "" {
+			result.UnmatchedMembers = append(result.UnmatchedMembers, label)
+			continue
+		}
+		// cuma akun yg email nya udah verified, biar gk bisa ada yg daftar pake email orang terus ikut ke-import
+		user, err := s.userRepo.FindByEmail(member.Email)
+		if err != nil || user.IsBot || !user.EmailVerified() {
+			result.UnmatchedMembers = append(result.UnmatchedMembers, label+" <"+member.Email+">")
+			continue
+		}
+		if user.ID == userID {
+			members[member.ExternalID] = user.ID
+			continue
+		}
+		// member lain gk dimasukin diam-diam, cuma dilaporin kayak restore
+		result.MatchedMembers = append(result.MatchedMembers, user.Username+" <"+member.Email+">")
+	}
+
+	for _, card := range board.Cards {
+		if strings.TrimSpace(card.Title) == "" || (card.Archived && !opts.IncludeArchived) {
+			result.Skipped++
+			continue
+		}
+
+		req := &CreateWorkspaceTaskRequest{
+			Title:       strings.TrimSpace(card.Title),
+			Description: card.DescriptionWithChecklists(),
+			Labels:      fitLabels(append(card.Labels, card.List)),
+			DueDate:     card.DueDate,
+		}
+		for _, id := range card.MemberIDs {
+			if userID, ok := members[id]; ok {
+				req.AssigneeID = &userID
+				break
+			}
+		}
+		task, err := s.workspaceService.CreateTask(workspaceID, userID, req)
+		if err != nil {
+			return fmt.Errorf("failed to import %q: %v", card.Title, err)
+		}
+
+		status := boardCardStatus(&card, opts.ListStatuses)
+		if status != models.StatusNotStarted {
+			if _, err := s.workspaceService.UpdateTask(workspaceID, task.ID, userID, &UpdateWorkspaceTaskRequest{Status: &status}); err != nil {
+				return fmt.Errorf("failed to import %q: %v", card.Title, err)
+			}
+		}
+		result.Tasks++
+
+		// comment dibikin atas nama yg import, penulis aslinya ditulis di isi comment
+		for _, comment := range card.Comments {
+			content := fmt.Sprintf("**%s**", comment.Author)
+			if !comment.CreatedAt.IsZero() {
+				content += " (" + comment.CreatedAt.UTC().Format("2006-01-02 15:04") + ")"
+			}
+			content += ":\n\n" + comment.Text
+			if _, err := s.commentService.Create(&CreateCommentRequest{Content: content, TaskID: task.ID}, userID); err != nil {
+				return fmt.Errorf("failed to import comments of %q: %v", card.Title, err)
+			}
+			result.Comments++
+		}
+	}
+	return nil
+}
+
+func parseBoard(source, filename string, r io.Reader) (*importer.Board, error) {
+	name := strings.TrimSuffix(filename, filepath.Ext(filename))
+	switch source {
+	case BoardSourceTrello:
+		return importer.ParseTrello(r)
+	case BoardSourceTodoist:
+		if strings.EqualFold(filepath.Ext(filename), ".csv") {
+			return importer.ParseTodoistCSV(r, name)
+		}
+		return importer.ParseTodoistJSON(r, "")
+	}
+	return nil, errors.New("source must be trello or todoist")
+}
+
+// boardCardStatus: card yg udah selesai selalu done, sisanya ikut mapping list nya
+func boardCardStatus(card *importer.Card, listStatuses map[string]string) string {
+	if card.Done {
+		return models.StatusDone
+	}
+	if status, ok := listStatuses[card.List]; ok {
+		status, _ = parseImportStatus(status)
+		return status
+	}
+	if status, ok := parseImportStatus(card.List); ok {
+		return status
+	}
+	name := strings.ToLower(card.List)
+	switch {
+	case strings.Contains(name, "done"), strings.Contains(name, "complete"), strings.Contains(name, "selesai"):
+		return models.StatusDone
+	case strings.Contains(name, "progress"), strings.Contains(name, "doing"), strings.Contains(name, "review"):
+		return models.StatusInProgress
+	}
+	return models.StatusNotStarted
+}
+
+// fitLabels cuts imported labels down to what NormalizeLabels accepts instead of failing the import
+func fitLabels(labels []string) []string {
+	fitted := make([]string, 0, len(labels))
+	seen := map[string]bool{}
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if runes := []rune(label); len(runes) > maxLabelLength {
+			label = string(runes[:maxLabelLength])
+		}
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		fitted = append(fitted, label)
+		if len(fitted) == maxLabels {
+			break
+		}
+	}
+	return fitted
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"minitask/internal/models"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// createBotMember creates an integration's bot user and adds it to the workspace with RoleBot.
+// secret cuma dipake buat password hash random, bot emang gk bisa login
+func createBotMember(tx *gorm.DB, workspaceID, name, suffix, secret string) (*models.User, error) {
+	password, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	bot := &models.User{
+		Username: botUsername(name) + "-bot-" + suffix,
+		Email:    "bot-" + suffix + "@bots.minitask.local",
+		Password: string(password),
+		IsBot:    true,
+	}
+	now := time.Now()
+	bot.EmailVerifiedAt = &now // email nya palsu, tapi bot emang gk pernah butuh verifikasi
+	if err := tx.Create(bot).Error; err != nil {
+		return nil, err
+	}
+	member := &models.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      bot.ID,
+		Role:        models.RoleBot,
+		JoinedAt:    time.Now(),
+	}
+	if err := tx.Create(member).Error; err != nil {
+		return nil, err
+	}
+	return bot, nil
+}
+
+// generateBotSuffix bikin suffix username/email bot sendiri, jangan diambil dari secret integrasi
+// soalnya username & email bot keliatan sama semua member
+func generateBotSuffix() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
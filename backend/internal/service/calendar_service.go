@@ -0,0 +1,289 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"minitask/internal/ical"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	calendarTokenPrefix = "mt_cal_"
+
+	// feed cuma nampilin deadline 30 hari ke belakang, kalender gk perlu history setahun
+	calendarLookback = 30 * 24 * time.Hour
+	calendarMaxTasks = 1000
+)
+
+var ErrFeedNotFound = errors.New("calendar feed not found")
+
+type CalendarService struct {
+	db            *gorm.DB
+	feedRepo      repository.CalendarFeedRepository
+	taskRepo      repository.TaskRepository
+	workspaceRepo repository.WorkspaceRepository
+}
+
+func NewCalendarService(
+	db *gorm.DB,
+	feedRepo repository.CalendarFeedRepository,
+	taskRepo repository.TaskRepository,
+	workspaceRepo repository.WorkspaceRepository,
+) *CalendarService {
+	return &CalendarService{
+		db:            db,
+		feedRepo:      feedRepo,
+		taskRepo:      taskRepo,
+		workspaceRepo: workspaceRepo,
+	}
+}
+
+type CreateCalendarFeedRequest struct {
+	WorkspaceID *string `json:"workspaceId"` // null = personal feed
+	Timezone    string  `json:"timezone"`    // IANA, e.g. Asia/Jakarta
+}
+
+type UpdateCalendarFeedRequest struct {
+	Timezone string `json:"timezone"`
+}
+
+// CalendarFeedWithToken is only returned on create/regenerate, token nya gk disimpen plain
+type CalendarFeedWithToken struct {
+	models.CalendarFeed
+	Token    string `json:"token"`
+	FeedPath string `json:"feedPath"`
+}
+
+func (s *CalendarService) GetFeeds(userID string) ([]models.CalendarFeed, error) {
+	feeds, err := s.feedRepo.FindAllByUserID(userID)
+	if err != nil {
+		return nil, errors.New("failed to load calendar feeds")
+	}
+	if feeds == nil {
+		feeds = []models.CalendarFeed{}
+	}
+	return feeds, nil
+}
+
+func (s *CalendarService) CreateFeed(userID string, req *CreateCalendarFeedRequest) (*CalendarFeedWithToken, error) {
+	if req.WorkspaceID != nil {
+		isMember, err := s.workspaceRepo.IsMember(*req.WorkspaceID, userID)
+		if err != nil || !isMember {
+			return nil, errors.New("workspace not found or access denied")
+		}
+	}
+	timezone, err := normalizeTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.feedRepo.FindByScope(userID, req.WorkspaceID); err == nil {
+		return nil, errors.New("calendar feed already exists, regenerate it to get a new url")
+	}
+
+	token, hash, err := generateCalendarToken()
+	if err != nil {
+		return nil, errors.New("failed to create calendar feed")
+	}
+	feed := &models.CalendarFeed{
+		UserID:      userID,
+		WorkspaceID: req.WorkspaceID,
+		TokenHash:   hash,
+		TokenPrefix: token[:len(calendarTokenPrefix)+6],
+		Timezone:    timezone,
+	}
+	if err := s.feedRepo.Create(feed); err != nil {
+		return nil, errors.New("failed to create calendar feed")
+	}
+	return withFeedToken(feed, token), nil
+}
+
+func (s *CalendarService) UpdateFeed(id, userID string, req *UpdateCalendarFeedRequest) (*models.CalendarFeed, error) {
+	feed, err := s.findOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	timezone, err := normalizeTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	feed.Timezone = timezone
+	if err := s.feedRepo.Update(feed); err != nil {
+		return nil, errors.New("failed to update calendar feed")
+	}
+	return feed, nil
+}
+
+// RegenerateToken invalidates the old url right away, kalo url nya kesebar
+func (s *CalendarService) RegenerateToken(id, userID string) (*CalendarFeedWithToken, error) {
+	feed, err := s.findOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	token, hash, err := generateCalendarToken()
+	if err != nil {
+		return nil, errors.New("failed to regenerate calendar feed")
+	}
+	feed.TokenHash = hash
+	feed.TokenPrefix = token[:len(calendarTokenPrefix)+6]
+	if err := s.feedRepo.Update(feed); err != nil {
+		return nil, errors.New("failed to regenerate calendar feed")
+	}
+	return withFeedToken(feed, token), nil
+}
+
+func (s *CalendarService) DeleteFeed(id, userID string) error {
+	feed, err := s.findOwned(id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.feedRepo.Delete(feed.ID); err != nil {
+		return errors.New("failed to delete calendar feed")
+	}
+	return nil
+}
+
+func (s *CalendarService) findOwned(id, userID string) (*models.CalendarFeed, error) {
+	feed, err := s.feedRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, ErrFeedNotFound
+	}
+	return feed, nil
+}
+
+// Render writes the ICS for a feed token. component: ical.ComponentEvent / ical.ComponentTodo.
+// User yg udah keluar dari workspace otomatis gk dapet isi feed workspace itu lagi
+func (s *CalendarService) Render(token, component string, w io.Writer) error {
+	if !strings.HasPrefix(token, calendarTokenPrefix) {
+		return ErrFeedNotFound
+	}
+	feed, err := s.feedRepo.FindByTokenHash(hashToken(token))
+	if err != nil {
+		return ErrFeedNotFound
+	}
+	location, err := time.LoadLocation(feed.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	from := time.Now().Add(-calendarLookback)
+	calendar := &ical.Calendar{Name: "MiniTask", Location: location}
+	var tasks []models.Task
+	if feed.WorkspaceID != nil {
+		workspace, err := s.workspaceRepo.FindByID(*feed.WorkspaceID)
+		if err != nil {
+			return ErrFeedNotFound
+		}
+		if isMember, err := s.workspaceRepo.IsMember(workspace.ID, feed.UserID); err != nil || !isMember {
+			return ErrFeedNotFound
+		}
+		calendar.Name = "MiniTask: " + workspace.Name
+		tasks, err = s.taskRepo.FindWithDueDateForWorkspace(workspace.ID, from, calendarMaxTasks)
+		if err != nil {
+			return err
+		}
+	} else {
+		tasks, err = s.taskRepo.FindWithDueDateForUser(feed.UserID, from, calendarMaxTasks)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range tasks {
+		calendar.Entries = append(calendar.Entries, calendarEntry(&tasks[i], location))
+	}
+	if err := s.feedRepo.TouchLastAccessed(feed.ID, time.Now()); err != nil {
+		log.Printf("[CalendarService] failed to update last access of feed %s: %v", feed.ID, err)
+	}
+	return calendar.Write(w, component)
+}
+
+func calendarEntry(task *models.Task, location *time.Location) ical.Entry {
+	due := task.DueDate.In(location)
+	summary := task.Title
+	if task.Key != "" {
+		summary = "[" + task.Key + "] " + summary
+	}
+	if task.Status == models.StatusDone {
+		summary = "✓ " + summary
+	}
+
+	link := TaskLink(task.WorkspaceID, task.ID)
+	lines := []string{"Status: " + statusLabel(task.Status)}
+	if task.Assignee != nil {
+		lines = append(lines, "Assignee: @"+task.Assignee.Username)
+	}
+	description := strings.Join(lines, "\n")
+	if task.Description != "" {
+		description += "\n\n" + task.Description
+	}
+	description += "\n\n" + link
+
+	return ical.Entry{
+		UID:         task.ID + "@minitask",
+		Summary:     summary,
+		Description: description,
+		URL:         link,
+		Categories:  task.Labels,
+		Due:         *task.DueDate,
+		// due date tanpa jam (tengah malem di zona feed) jadi all-day
+		AllDay:       due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0,
+		Status:       todoStatus(task.Status),
+		LastModified: task.UpdatedAt,
+	}
+}
+
+func statusLabel(status string) string {
+	switch status {
+	case models.StatusInProgress:
+		return "In progress"
+	case models.StatusDone:
+		return "Done"
+	}
+	return "Not started"
+}
+
+func todoStatus(status string) string {
+	switch status {
+	case models.StatusInProgress:
+		return "IN-PROCESS"
+	case models.StatusDone:
+		return "COMPLETED"
+	}
+	return "NEEDS-ACTION"
+}
+
+func normalizeTimezone(timezone string) (string, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		return "UTC", nil
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", errors.New("invalid timezone")
+	}
+	return timezone, nil
+}
+
+func withFeedToken(feed *models.CalendarFeed, token string) *CalendarFeedWithToken {
+	return &CalendarFeedWithToken{
+		CalendarFeed: *feed,
+		Token:        token,
+		FeedPath:     "/api/v1/calendar/" + token + ".ics",
+	}
+}
+
+func generateCalendarToken() (token, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = calendarTokenPrefix + hex.EncodeToString(b)
+	return token, hashToken(token), nil
+}
@@ -2,23 +2,48 @@ package service
 
 import (
 	"errors"
+	"log"
 	"minitask/internal/models"
+	"minitask/internal/realtime"
 	"minitask/internal/repository"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type CommentService struct {
-	db          *gorm.DB
-	commentRepo repository.CommentRepository
-	taskRepo    repository.TaskRepository
+	db              *gorm.DB
+	commentRepo     repository.CommentRepository
+	taskRepo        repository.TaskRepository
+	accessPolicy    *TaskAccessPolicy
+	mentionService  *MentionService
+	reactionService *ReactionService
+	activityService *ActivityService
+	publisher       realtime.Publisher
+	watcherService  *WatcherService
 }
 
-func NewCommentService(db *gorm.DB, commentRepo repository.CommentRepository, taskRepo repository.TaskRepository) *CommentService {
+func NewCommentService(
+	db *gorm.DB,
+	commentRepo repository.CommentRepository,
+	taskRepo repository.TaskRepository,
+	accessPolicy *TaskAccessPolicy,
+	mentionService *MentionService,
+	reactionService *ReactionService,
+	activityService *ActivityService,
+	publisher realtime.Publisher,
+	watcherService *WatcherService,
+) *CommentService {
 	return &CommentService{
-		db:          db,
-		commentRepo: commentRepo,
-		taskRepo:    taskRepo,
+		db:              db,
+		commentRepo:     commentRepo,
+		taskRepo:        taskRepo,
+		accessPolicy:    accessPolicy,
+		mentionService:  mentionService,
+		reactionService: reactionService,
+		activityService: activityService,
+		publisher:       publisher,
+		watcherService:  watcherService,
 	}
 }
 
@@ -39,15 +64,15 @@ func (s *CommentService) Create(req *CreateCommentRequest, userID string) (*mode
 		return nil, errors.New("task ID is required")
 	}
 
-	// Verify task exists
-	_, err := s.taskRepo.FindByID(req.TaskID)
+	// Verify task exists and the user can see it
+	task, err := s.accessPolicy.LoadViewable(req.TaskID, userID)
 	if err != nil {
-		return nil, errors.New("task not found")
+		return nil, err
 	}
 
 	comment := &models.Comment{
 		Content: req.Content,
-		TaskID:  req.TaskID,
+		TaskID:  task.ID,
 		UserID:  userID,
 	}
 
@@ -55,19 +80,50 @@ func (s *CommentService) Create(req *CreateCommentRequest, userID string) (*mode
 	if err != nil {
 		return nil, errors.New("failed to create comment")
 	}
+
+	mentioned, err := s.mentionService.SyncCommentMentions(comment, task)
+	if err != nil {
+		log.Printf("[CommentService] failed to save mentions for comment %s: %v", comment.ID, err)
+	}
+	s.watcherService.AutoWatch(task.ID, userID)
+	s.notifyCommented(comment, task, mentioned)
+
+	s.recordComment(userID, comment, task, models.ActivityCreated, nil, map[string]interface{}{"content": comment.Content})
+	renderComment(comment)
+	s.publishComment(realtime.EventCommentCreated, userID, task, comment)
 	return comment, nil
 }
 
-func (s *CommentService) GetAllByTaskID(taskID string) ([]models.Comment, error) {
-	return s.commentRepo.FindAllByTaskID(taskID)
+func (s *CommentService) GetAllByTaskID(taskID, userID string) ([]models.Comment, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.FindAllByTaskID(task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionService.AttachToComments(comments, userID); err != nil {
+		log.Printf("[CommentService] failed to load reactions for task %s: %v", taskID, err)
+	}
+	renderComments(comments)
+	return comments, nil
 }
 
-func (s *CommentService) GetByID(id string) (*models.Comment, error) {
-	comment, err := s.commentRepo.FindByID(id)
+func (s *CommentService) GetByID(id, userID string) (*models.Comment, error) {
+	comment, _, err := s.findViewable(id, userID)
 	if err != nil {
-		return nil, errors.New("comment not found")
+		return nil, err
 	}
-	return comment, nil
+
+	comments := []models.Comment{*comment}
+	if err := s.reactionService.AttachToComments(comments, userID); err != nil {
+		log.Printf("[CommentService] failed to load reactions for comment %s: %v", id, err)
+	}
+	renderComments(comments)
+	return &comments[0], nil
 }
 
 func (s *CommentService) Update(id string, userID string, req *UpdateCommentRequest) (*models.Comment, error) {
@@ -75,25 +131,127 @@ func (s *CommentService) Update(id string, userID string, req *UpdateCommentRequ
 		return nil, errors.New("content cannot be empty")
 	}
 
-	comment, err := s.commentRepo.FindByIDAndUserID(id, userID)
-	if err != nil {
+	// Only the author edits, and only while they can still see the task
+	comment, task, err := s.findViewable(id, userID)
+	if err != nil || comment.UserID != userID {
 		return nil, errors.New("comment not found or not authorized")
 	}
 
+	if comment.Content == req.Content {
+		renderComment(comment)
+		return comment, nil
+	}
+
+	// simpen isi lama dulu biar ada jejaknya
+	revision := &models.CommentRevision{
+		CommentID:  comment.ID,
+		Content:    comment.Content,
+		EditedByID: userID,
+	}
+	now := time.Now()
+	before := map[string]interface{}{"content": comment.Content}
 	comment.Content = req.Content
-	err = s.commentRepo.Update(comment)
+	comment.EditedAt = &now
+	err = s.commentRepo.UpdateWithRevision(comment, revision)
 	if err != nil {
 		return nil, errors.New("failed to update comment")
 	}
 
+	if _, err := s.mentionService.SyncCommentMentions(comment, task); err != nil {
+		log.Printf("[CommentService] failed to save mentions for comment %s: %v", comment.ID, err)
+	}
+
+	s.recordComment(userID, comment, task, models.ActivityUpdated, before, map[string]interface{}{"content": comment.Content})
+	renderComment(comment)
+	s.publishComment(realtime.EventCommentUpdated, userID, task, comment)
 	return comment, nil
 }
 
-// Delete removes a comment (only owner can delete)
+// GetRevisions returns the edit history of a comment, oldest first
+func (s *CommentService) GetRevisions(id, userID string) ([]models.CommentRevision, error) {
+	comment, _, err := s.findViewable(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.commentRepo.FindRevisions(comment.ID)
+	if err != nil {
+		return nil, errors.New("failed to load comment history")
+	}
+	renderRevisions(revisions)
+	return revisions, nil
+}
+
+// findViewable loads a comment together with its task, hiding comments on tasks the user can't see
+func (s *CommentService) findViewable(id, userID string) (*models.Comment, *models.Task, error) {
+	comment, err := s.commentRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, errors.New("comment not found")
+	}
+
+	task, err := s.accessPolicy.LoadViewable(comment.TaskID, userID)
+	if err != nil {
+		return nil, nil, errors.New("comment not found")
+	}
+	return comment, task, nil
+}
+
+// Delete removes a comment (the author, or the workspace owner as moderator)
 func (s *CommentService) Delete(id string, userID string) error {
-	err := s.commentRepo.Delete(id, userID)
+	comment, task, err := s.findViewable(id, userID)
+	if err != nil {
+		return errors.New("comment not found or not authorized")
+	}
+	if comment.UserID != userID && !s.accessPolicy.CanModerate(task, userID) {
+		return errors.New("comment not found or not authorized")
+	}
+
+	err = s.commentRepo.DeleteByID(comment.ID)
 	if err != nil {
 		return errors.New("comment not found or not authorized")
 	}
+
+	if err := s.mentionService.RemoveCommentMentions(comment); err != nil {
+		log.Printf("[CommentService] failed to remove mentions for comment %s: %v", comment.ID, err)
+	}
+
+	s.recordComment(userID, comment, task, models.ActivityDeleted, map[string]interface{}{"content": comment.Content, "authorId": comment.UserID}, nil)
+	s.publishComment(realtime.EventCommentDeleted, userID, task, map[string]string{"id": comment.ID, "taskId": comment.TaskID})
 	return nil
 }
+
+// notifyCommented lets the task watchers know about a new comment, kecuali yg udah dapet notif mention
+func (s *CommentService) notifyCommented(comment *models.Comment, task *models.Task, mentioned []models.User) {
+	skip := map[string]bool{}
+	for _, user := range mentioned {
+		skip[user.ID] = true
+	}
+	s.watcherService.NotifyWatchers(task, NotifyInput{
+		Type:      models.NotificationTaskCommented,
+		ActorID:   comment.UserID,
+		CommentID: &comment.ID,
+		Subject:   task.Title,
+		Excerpt:   comment.Content,
+	}, skip)
+}
+
+// personal task gk ada board yg di share, jadi cuma workspace task yg di broadcast
+func (s *CommentService) publishComment(eventType, actorID string, task *models.Task, data interface{}) {
+	if task.WorkspaceID == nil {
+		return
+	}
+	s.publisher.Publish(realtime.NewEvent(eventType, *task.WorkspaceID, actorID, data))
+}
+
+func (s *CommentService) recordComment(actorID string, comment *models.Comment, task *models.Task, action string, before, after map[string]interface{}) {
+	s.activityService.Record(ActivityEntry{
+		ActorID:     actorID,
+		EntityType:  models.ActivityEntityComment,
+		EntityID:    comment.ID,
+		Action:      action,
+		WorkspaceID: task.WorkspaceID,
+		TaskID:      &task.ID,
+		Before:      before,
+		After:       after,
+	})
+}
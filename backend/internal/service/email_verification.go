@@ -0,0 +1,113 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"minitask/internal/mailer"
+	"minitask/internal/models"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const (
+	verifyTokenPrefix = "mt_ev_"
+	EmailVerifyTTL    = 24 * time.Hour
+	// resend dibatesin per menit & per hari, biar gk bisa dipake nge-spam inbox orang
+	verificationCooldown  = time.Minute
+	maxVerificationPerDay = 5
+)
+
+var (
+	// ErrEmailNotVerified is returned by actions unverified accounts can't do yet, handler nya balikin 403
+	ErrEmailNotVerified      = errors.New("please verify your email address first")
+	ErrInvalidVerifyToken    = errors.New("Verification link is invalid or has expired")
+	ErrVerificationThrottled = errors.New("Too many verification emails, please try again later")
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyEmail marks the account verified from an emailed link. Link nya cuma berlaku buat email yg
+// dikirimin, jadi kalo email nya keburu diganti link lama gk kepake lagi
+func (s *AuthService) VerifyEmail(req *VerifyEmailRequest) (*models.User, error) {
+	stored, err := s.verificationRepo.FindByTokenHash(hashToken(strings.TrimSpace(req.Token)))
+	if err != nil || stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidVerifyToken
+	}
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil || !strings.EqualFold(user.Email, stored.Email) {
+		return nil, ErrInvalidVerifyToken
+	}
+	if ok, err := s.verificationRepo.MarkUsed(stored.ID, time.Now()); err != nil || !ok {
+		return nil, ErrInvalidVerifyToken
+	}
+	if !user.EmailVerified() {
+		now := time.Now()
+		if err := s.userRepo.SetEmailVerifiedAt(user.ID, now); err != nil {
+			return nil, errors.New("Failed to verify email")
+		}
+		user.EmailVerifiedAt = &now
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// ResendVerification emails a fresh link to the signed in user, link yg lama tetep jalan sampe expired
+func (s *AuthService) ResendVerification(userID string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("User not found")
+	}
+	if user.EmailVerified() {
+		return errors.New("Email is already verified")
+	}
+	if latest, err := s.verificationRepo.FindLatestByUserID(user.ID); err == nil && time.Since(latest.CreatedAt) < verificationCooldown {
+		return ErrVerificationThrottled
+	}
+	sent, err := s.verificationRepo.CountSince(user.ID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return errors.New("Failed, please try again later")
+	}
+	if sent >= maxVerificationPerDay {
+		return ErrVerificationThrottled
+	}
+	if err := s.sendVerification(user); err != nil {
+		return errors.New("Failed, please try again later")
+	}
+	return nil
+}
+
+func (s *AuthService) sendVerification(user *models.User) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	token := verifyTokenPrefix + hex.EncodeToString(b)
+	err := s.verificationRepo.Create(&models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(EmailVerifyTTL),
+	})
+	if err != nil {
+		return err
+	}
+	s.sendAccountEmail(mailer.TemplateVerifyEmail, user, frontendURL()+"/verify-email?token="+token, "24 hours")
+	return nil
+}
+
+// validateEmail only accepts a bare address ("a@b.co"), bukan "Nama <a@b.co>" atau domain tanpa titik
+func validateEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return errors.New("Invalid email address")
+	}
+	at := strings.LastIndex(email, "@")
+	if domain := email[at+1:]; !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return errors.New("Invalid email address")
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ExportCSV  = "csv"
+	ExportJSON = "json"
+
+	exportBatchSize = 200
+)
+
+var ErrExportFormat = errors.New("format must be csv or json")
+
+type ExportService struct {
+	db            *gorm.DB
+	taskRepo      repository.TaskRepository
+	workspaceRepo repository.WorkspaceRepository
+}
+
+func NewExportService(
+	db *gorm.DB,
+	taskRepo repository.TaskRepository,
+	workspaceRepo repository.WorkspaceRepository,
+) *ExportService {
+	return &ExportService{
+		db:            db,
+		taskRepo:      taskRepo,
+		workspaceRepo: workspaceRepo,
+	}
+}
+
+type TaskExport struct {
+	ID          string          `json:"id"`
+	Key         string          `json:"key,omitempty"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Status      string          `json:"status"`
+	Labels      []string        `json:"labels"`
+	CreatedBy   string          `json:"createdBy"`
+	Assignee    *string         `json:"assignee"`
+	DueDate     *time.Time      `json:"dueDate"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	Comments    []CommentExport `json:"comments"`
+}
+
+type CommentExport struct {
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+var exportColumns = []string{
+	"key", "id", "title", "description", "status", "labels", "created_by", "assignee",
+	"due_date", "created_at", "updated_at", "comments",
+}
+
+// ExportStream is what the handler writes to the response, Flush dipanggil tiap batch
+type ExportStream interface {
+	io.Writer
+	Flush()
+}
+
+// CheckWorkspaceExport is called before the response starts, abis header kekirim error gk bisa jadi 403 lagi
+func (s *ExportService) CheckWorkspaceExport(workspaceID, userID, format string) error {
+	if format != ExportCSV && format != ExportJSON {
+		return ErrExportFormat
+	}
+	isMember, err := s.workspaceRepo.IsMember(workspaceID, userID)
+	if err != nil || !isMember {
+		return errors.New("access denied")
+	}
+	return nil
+}
+
+func (s *ExportService) ExportPersonal(userID, format string, out ExportStream) error {
+	return s.export(format, out, func(fn func([]models.Task) error) error {
+		return s.taskRepo.StreamByUserID(userID, exportBatchSize, fn)
+	})
+}
+
+func (s *ExportService) ExportWorkspace(workspaceID, format string, out ExportStream) error {
+	return s.export(format, out, func(fn func([]models.Task) error) error {
+		return s.taskRepo.StreamByWorkspaceID(workspaceID, exportBatchSize, fn)
+	})
+}
+
+func (s *ExportService) export(format string, out ExportStream, stream func(func([]models.Task) error) error) error {
+	switch format {
+	case ExportCSV:
+		return s.exportCSV(out, stream)
+	case ExportJSON:
+		return s.exportJSON(out, stream)
+	}
+	return ErrExportFormat
+}
+
+func (s *ExportService) exportCSV(out ExportStream, stream func(func([]models.Task) error) error) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(exportColumns); err != nil {
+		return err
+	}
+	err := stream(func(tasks []models.Task) error {
+		for i := range tasks {
+			row := toTaskExport(&tasks[i])
+			assignee, dueDate := "", ""
+			if row.Assignee != nil {
+				assignee = *row.Assignee
+			}
+			if row.DueDate != nil {
+				dueDate = row.DueDate.UTC().Format(time.RFC3339)
+			}
+			// comments digabung satu cell, satu baris per comment
+			comments := make([]string, 0, len(row.Comments))
+			for _, comment := range row.Comments {
+				comments = append(comments, comment.CreatedAt.UTC().Format(time.RFC3339)+" "+comment.Author+": "+comment.Content)
+			}
+			record := []string{
+				row.Key, row.ID, row.Title, row.Description, row.Status, strings.Join(row.Labels, ","),
+				row.CreatedBy, assignee, dueDate,
+				row.CreatedAt.UTC().Format(time.RFC3339), row.UpdatedAt.UTC().Format(time.RFC3339),
+				strings.Join(comments, "\n"),
+			}
+			for j := range record {
+				record[j] = csvSafe(record[j])
+			}
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		out.Flush()
+		return w.Error()
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvSafe keeps spreadsheet apps from running a cell as a formula (CSV injection), judul task
+// "=HYPERLINK(...)" dari member lain jadi teks biasa pas dibuka di Excel
+func csvSafe(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// exportJSON writes one array element at a time instead of marshalling the whole slice
+func (s *ExportService) exportJSON(out ExportStream, stream func(func([]models.Task) error) error) error {
+	if _, err := io.WriteString(out, "["); err != nil {
+		return err
+	}
+	first := true
+	err := stream(func(tasks []models.Task) error {
+		for i := range tasks {
+			data, err := json.Marshal(toTaskExport(&tasks[i]))
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := io.WriteString(out, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			if _, err := out.Write(data); err != nil {
+				return err
+			}
+		}
+		out.Flush()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "]\n")
+	return err
+}
+
+func toTaskExport(task *models.Task) *TaskExport {
+	row := &TaskExport{
+		ID:          task.ID,
+		Key:         task.Key,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		Labels:      task.Labels,
+		CreatedBy:   task.User.Username,
+		DueDate:     task.DueDate,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+		Comments:    make([]CommentExport, 0, len(task.Comments)),
+	}
+	if row.Labels == nil {
+		row.Labels = []string{}
+	}
+	if task.Assignee != nil {
+		row.Assignee = &task.Assignee.Username
+	}
+	for _, comment := range task.Comments {
+		row.Comments = append(row.Comments, CommentExport{
+			Author:    comment.User.Username,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+	return row
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"minitask/internal/models"
+)
+
+type bufferStream struct {
+	bytes.Buffer
+}
+
+func (b *bufferStream) Flush() {}
+
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	task := models.Task{
+		ID:          "t1",
+		Key:         "WS-1",
+		Title:       `=HYPERLINK("http://evil.test","click")`,
+		Description: "+1 dari aku",
+		Status:      models.StatusNotStarted,
+		Labels:      models.StringList{"bug"},
+		User:        models.User{Username: "@alice"},
+		CreatedAt:   time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC),
+	}
+	var out bufferStream
+	err := (&ExportService{}).exportCSV(&out, func(handle func([]models.Task) error) error {
+		return handle([]models.Task{task})
+	})
+	if err != nil {
+		t.Fatalf("exportCSV() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("rows = %v, %v", rows, err)
+	}
+	got := map[string]string{}
+	for i, column := range rows[0] {
+		got[column] = rows[1][i]
+	}
+	for column, want := range map[string]string{
+		"title":       `'=HYPERLINK("http://evil.test","click")`,
+		"description": "'+1 dari aku",
+		"created_by":  "'@alice",
+		"key":         "WS-1",
+		"labels":      "bug",
+	} {
+		if got[column] != want {
+			t.Errorf("%s = %q, want %q", column, got[column], want)
+		}
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := map[string]string{
+		"":           "",
+		"plain":      "plain",
+		"=1+2":       "'=1+2",
+		"+62 812":    "'+62 812",
+		"-5":         "'-5",
+		"@SUM(A1)":   "'@SUM(A1)",
+		"\tindent":   "'\tindent",
+		"\rcarry":    "'\rcarry",
+		"a=b":        "a=b",
+		"2026-01-05": "2026-01-05",
+	}
+	for in, want := range tests {
+		if got := csvSafe(in); got != want {
+			t.Errorf("csvSafe(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
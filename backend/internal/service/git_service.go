@@ -0,0 +1,294 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"minitask/internal/gitlink"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GitService struct {
+	db                 *gorm.DB
+	gitIntegrationRepo repository.GitIntegrationRepository
+	workspaceRepo      repository.WorkspaceRepository
+	taskRepo           repository.TaskRepository
+	accessPolicy       *TaskAccessPolicy
+	workspaceService   *WorkspaceService
+}
+
+func NewGitService(
+	db *gorm.DB,
+	gitIntegrationRepo repository.GitIntegrationRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	taskRepo repository.TaskRepository,
+	accessPolicy *TaskAccessPolicy,
+	workspaceService *WorkspaceService,
+) *GitService {
+	return &GitService{
+		db:                 db,
+		gitIntegrationRepo: gitIntegrationRepo,
+		workspaceRepo:      workspaceRepo,
+		taskRepo:           taskRepo,
+		accessPolicy:       accessPolicy,
+		workspaceService:   workspaceService,
+	}
+}
+
+type EnableGitIntegrationRequest struct {
+	AutoCloseOnMerge bool `json:"autoCloseOnMerge"`
+}
+
+type UpdateGitIntegrationRequest struct {
+	AutoCloseOnMerge *bool `json:"autoCloseOnMerge"`
+}
+
+// GitIntegrationWithSecret is only returned on enable/rotate, dipake buat setting webhook di GitHub/GitLab
+type GitIntegrationWithSecret struct {
+	models.GitIntegration
+	Secret      string `json:"secret"`
+	WebhookPath string `json:"webhookPath"`
+}
+
+// GitEventResult tells the provider what we did with the delivery, keliatan di delivery log GitHub/GitLab
+type GitEventResult struct {
+	Linked []string `json:"linked"`
+	Closed []string `json:"closed"`
+}
+
+func (s *GitService) authorizeOwner(workspaceID, userID string) (*models.Workspace, error) {
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil || workspace.OwnerID != userID {
+		return nil, errors.New("workspace not found or not authorized")
+	}
+	return workspace, nil
+}
+
+func (s *GitService) Get(workspaceID, userID string) (*models.GitIntegration, error) {
+	if _, err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	integration, err := s.gitIntegrationRepo.FindByWorkspaceID(workspaceID)
+	if err != nil {
+		return nil, errors.New("git integration not enabled")
+	}
+	return integration, nil
+}
+
+// Enable creates the integration together with its bot, the bot is who moves tasks to done on merge
+func (s *GitService) Enable(workspaceID, userID string, req *EnableGitIntegrationRequest) (*GitIntegrationWithSecret, error) {
+	if _, err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.gitIntegrationRepo.FindByWorkspaceID(workspaceID); err == nil {
+		return nil, errors.New("git integration already enabled")
+	}
+	secret, err := generateGitSecret()
+	if err != nil {
+		return nil, errors.New("failed to enable git integration")
+	}
+
+	suffix, err := generateBotSuffix()
+	if err != nil {
+		return nil, errors.New("failed to enable git integration")
+	}
+
+	integration := &models.GitIntegration{
+		WorkspaceID:      workspaceID,
+		Secret:           secret,
+		AutoCloseOnMerge: req.AutoCloseOnMerge,
+		CreatedByID:      userID,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		bot, err := createBotMember(tx, workspaceID, "git", suffix, secret)
+		if err != nil {
+			return err
+		}
+		integration.BotUserID = bot.ID
+		return tx.Create(integration).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to enable git integration")
+	}
+	return s.withSecret(integration), nil
+}
+
+func (s *GitService) Update(workspaceID, userID string, req *UpdateGitIntegrationRequest) (*models.GitIntegration, error) {
+	integration, err := s.Get(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.AutoCloseOnMerge != nil {
+		integration.AutoCloseOnMerge = *req.AutoCloseOnMerge
+	}
+	if err := s.gitIntegrationRepo.Update(integration); err != nil {
+		return nil, errors.New("failed to update git integration")
+	}
+	return integration, nil
+}
+
+// RotateSecret invalidates the old secret right away, webhook di provider harus diupdate juga
+func (s *GitService) RotateSecret(workspaceID, userID string) (*GitIntegrationWithSecret, error) {
+	integration, err := s.Get(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateGitSecret()
+	if err != nil {
+		return nil, errors.New("failed to rotate secret")
+	}
+	integration.Secret = secret
+	if err := s.gitIntegrationRepo.Update(integration); err != nil {
+		return nil, errors.New("failed to rotate secret")
+	}
+	return s.withSecret(integration), nil
+}
+
+// Disable removes the integration and its bot member. Links yang udah ada tetep disimpen
+func (s *GitService) Disable(workspaceID, userID string) error {
+	integration, err := s.Get(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.gitIntegrationRepo.Delete(integration.ID); err != nil {
+		return errors.New("failed to disable git integration")
+	}
+	if err := s.workspaceRepo.RemoveMember(workspaceID, integration.BotUserID); err != nil {
+		log.Printf("[GitService] failed to remove bot %s from workspace %s: %v", integration.BotUserID, workspaceID, err)
+	}
+	return nil
+}
+
+func (s *GitService) GetLinksForTask(taskID, userID string) ([]models.TaskLink, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.gitIntegrationRepo.FindLinksByTaskID(task.ID)
+	if err != nil {
+		return nil, errors.New("failed to load task links")
+	}
+	if links == nil {
+		links = []models.TaskLink{}
+	}
+	return links, nil
+}
+
+// HandleWebhook verifies and processes one GitHub/GitLab delivery for the workspace
+func (s *GitService) HandleWebhook(workspaceID string, header http.Header, body []byte) (*GitEventResult, error) {
+	integration, err := s.gitIntegrationRepo.FindByWorkspaceID(workspaceID)
+	if err != nil {
+		return nil, errors.New("invalid signature")
+	}
+	provider := gitlink.DetectProvider(header)
+	if provider == "" || !gitlink.Verify(provider, header, body, integration.Secret) {
+		return nil, errors.New("invalid signature")
+	}
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil {
+		return nil, errors.New("invalid signature")
+	}
+
+	event, err := gitlink.Parse(provider, header, body)
+	if errors.Is(err, gitlink.ErrUnsupportedEvent) {
+		// event lain (issues, tag push, ...) di-ack aja biar provider gk retry
+		return &GitEventResult{Linked: []string{}, Closed: []string{}}, nil
+	}
+	if err != nil {
+		return nil, errors.New("invalid payload")
+	}
+
+	result := &GitEventResult{Linked: []string{}, Closed: []string{}}
+	for _, commit := range event.Commits {
+		for _, ref := range gitlink.ParseReferences(workspace.KeyPrefix, commit.Message) {
+			s.link(workspace.ID, ref, result, &models.TaskLink{
+				Provider:   event.Provider,
+				Repository: event.Repository,
+				Type:       models.TaskLinkCommit,
+				ExternalID: commit.ID,
+				URL:        commit.URL,
+				Title:      firstLine(commit.Message),
+				Author:     commit.Author,
+				Closes:     ref.Closes,
+			})
+		}
+	}
+
+	if pr := event.PullRequest; pr != nil {
+		for _, ref := range gitlink.ParseReferences(workspace.KeyPrefix, pr.Title, pr.Body, pr.Branch) {
+			task := s.link(workspace.ID, ref, result, &models.TaskLink{
+				Provider:   event.Provider,
+				Repository: event.Repository,
+				Type:       models.TaskLinkPullRequest,
+				ExternalID: strconv.Itoa(pr.Number),
+				URL:        pr.URL,
+				Title:      pr.Title,
+				Author:     pr.Author,
+				State:      pr.State,
+				Closes:     ref.Closes,
+			})
+			if task == nil || pr.State != "merged" || !ref.Closes || !integration.AutoCloseOnMerge || task.Status == models.StatusDone {
+				continue
+			}
+			done := models.StatusDone
+			if _, err := s.workspaceService.UpdateTask(workspace.ID, task.ID, integration.BotUserID, &UpdateWorkspaceTaskRequest{Status: &done}); err != nil {
+				log.Printf("[GitService] failed to close task %s on merge: %v", task.Key, err)
+				continue
+			}
+			result.Closed = append(result.Closed, task.Key)
+		}
+	}
+
+	if err := s.gitIntegrationRepo.TouchLastEvent(integration.ID, time.Now()); err != nil {
+		log.Printf("[GitService] failed to update last event of %s: %v", integration.ID, err)
+	}
+	return result, nil
+}
+
+// link attaches the commit/PR to the referenced task. Key yg gk ada tasknya di-skip aja
+func (s *GitService) link(workspaceID string, ref gitlink.Reference, result *GitEventResult, link *models.TaskLink) *models.Task {
+	task, err := s.taskRepo.FindByWorkspaceAndKey(workspaceID, ref.Key)
+	if err != nil {
+		return nil
+	}
+	link.TaskID = task.ID
+	link.WorkspaceID = workspaceID
+	if err := s.gitIntegrationRepo.UpsertLink(link); err != nil {
+		log.Printf("[GitService] failed to link %s %s to task %s: %v", link.Type, link.ExternalID, task.Key, err)
+		return nil
+	}
+	result.Linked = append(result.Linked, task.Key)
+	return task
+}
+
+func (s *GitService) withSecret(integration *models.GitIntegration) *GitIntegrationWithSecret {
+	return &GitIntegrationWithSecret{
+		GitIntegration: *integration,
+		Secret:         integration.Secret,
+		WebhookPath:    "/api/v1/hooks/git/" + integration.WorkspaceID,
+	}
+}
+
+func generateGitSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	if len(line) > 200 {
+		line = line[:200]
+	}
+	return line
+}
@@ -0,0 +1,412 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxImportRows per file, tiap task tetep bikin activity/notif/event jadi jangan kegedean
+const MaxImportRows = 1000
+
+// ImportFields are the task fields a CSV column can be mapped to
+var ImportFields = []string{"title", "description", "status", "labels", "assignee", "dueDate"}
+
+type ImportService struct {
+	db               *gorm.DB
+	taskRepo         repository.TaskRepository
+	userRepo         repository.UserRepository
+	workspaceRepo    repository.WorkspaceRepository
+	taskService      *TaskService
+	workspaceService *WorkspaceService
+	commentService   *CommentService
+}
+
+func NewImportService(
+	db *gorm.DB,
+	taskRepo repository.TaskRepository,
+	userRepo repository.UserRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	taskService *TaskService,
+	workspaceService *WorkspaceService,
+	commentService *CommentService,
+) *ImportService {
+	return &ImportService{
+		db:               db,
+		taskRepo:         taskRepo,
+		userRepo:         userRepo,
+		workspaceRepo:    workspaceRepo,
+		taskService:      taskService,
+		workspaceService: workspaceService,
+		commentService:   commentService,
+	}
+}
+
+// ImportMapping maps a task field (ImportFields) to a CSV header, e.g. {"title": "Task Name"}
+type ImportMapping map[string]string
+
+// ImportPreview is the column mapping step: headers, a few rows and a guessed mapping
+type ImportPreview struct {
+	Headers          []string      `json:"headers"`
+	Rows             [][]string    `json:"rows"`
+	SuggestedMapping ImportMapping `json:"suggestedMapping"`
+	Fields           []string      `json:"fields"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"` // line in the file where the row starts, header = line 1
+	Field   string `json:"field,omitempty"`
+	Message string `json:"error"`
+}
+
+func (e *ImportRowError) Error() string {
+	return e.Message
+}
+
+type ImportResult struct {
+	DryRun  bool             `json:"dryRun"`
+	Total   int              `json:"total"`
+	Valid   int              `json:"valid"`
+	Created int              `json:"created"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+var ErrImportInvalid = errors.New("import has invalid rows, nothing was created")
+
+// importAliases buat nebak mapping dari header spreadsheet yg umum
+var importAliases = map[string][]string{
+	"title":       {"title", "task", "name", "task name", "summary", "judul"},
+	"description": {"description", "notes", "details", "desc", "deskripsi"},
+	"status":      {"status", "state"},
+	"labels":      {"labels", "label", "tags", "tag"},
+	"assignee":    {"assignee", "assigned to", "owner", "email", "username"},
+	"dueDate":     {"due date", "duedate", "due", "deadline", "due_date"},
+}
+
+func (s *ImportService) Preview(r io.Reader) (*ImportPreview, error) {
+	headers, rows, _, err := readImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	preview := &ImportPreview{
+		Headers:          headers,
+		Rows:             rows,
+		SuggestedMapping: ImportMapping{},
+		Fields:           ImportFields,
+	}
+	if len(preview.Rows) > 5 {
+		preview.Rows = preview.Rows[:5]
+	}
+	for _, field := range ImportFields {
+		for _, header := range headers {
+			if containsFold(importAliases[field], strings.TrimSpace(header)) {
+				preview.SuggestedMapping[field] = header
+				break
+			}
+		}
+	}
+	return preview, nil
+}
+
+// ImportPersonal validates every row with the same rules as TaskService.Create and, kalo bukan
+// dry run & semua valid, bikin semuanya dalam satu transaction
+func (s *ImportService) ImportPersonal(userID string, r io.Reader, mapping ImportMapping, dryRun bool) (*ImportResult, error) {
+	return s.run(r, mapping, dryRun, func(row importRow) (*models.Task, error) {
+		if row.Assignee != "" {
+			return nil, &ImportRowError{Field: "assignee", Message: "personal tasks can't have an assignee"}
+		}
+		task := &models.Task{
+			Title:       row.Title,
+			Description: row.Description,
+			UserID:      userID,
+			Labels:      row.Labels,
+			DueDate:     row.DueDate,
+			Status:      row.Status,
+		}
+		if err := s.taskService.prepare(task); err != nil {
+			return nil, err
+		}
+		return task, nil
+	}, func(task *models.Task) {
+		s.taskService.afterCreate(task)
+	})
+}
+
+// ImportWorkspace goes through WorkspaceService.CreateTask rules (membership, who may assign, labels)
+func (s *ImportService) ImportWorkspace(workspaceID, userID string, r io.Reader, mapping ImportMapping, dryRun bool) (*ImportResult, error) {
+	isMember, err := s.workspaceRepo.IsMember(workspaceID, userID)
+	if err != nil || !isMember {
+		return nil, errors.New("workspace not found or access denied")
+	}
+
+	assignees := map[string]*string{}
+	return s.run(r, mapping, dryRun, func(row importRow) (*models.Task, error) {
+		req := &CreateWorkspaceTaskRequest{
+			Title:       row.Title,
+			Description: row.Description,
+			Labels:      row.Labels,
+			DueDate:     row.DueDate,
+		}
+		if row.Assignee != "" {
+			id, ok := assignees[row.Assignee]
+			if !ok {
+				id = s.resolveAssignee(row.Assignee)
+				assignees[row.Assignee] = id
+			}
+			if id == nil {
+				return nil, &ImportRowError{Field: "assignee", Message: "user " + row.Assignee + " not found"}
+			}
+			req.AssigneeID = id
+		}
+		task, err := s.workspaceService.prepareTask(workspaceID, userID, req)
+		if err != nil {
+			return nil, err
+		}
+		if row.Status != "" {
+			task.Status = row.Status
+		}
+		return task, nil
+	}, func(task *models.Task) {
+		s.workspaceService.afterCreateTask(task, userID)
+	})
+}
+
+// resolveAssignee accepts an email or a username (with or without @)
+func (s *ImportService) resolveAssignee(value string) *string {
+	var user *models.User
+	var err error
+	if strings.Contains(strings.TrimPrefix(value, "@"), "@") {
+		user, err = s.userRepo.FindByEmail(strings.ToLower(value))
+		if err == nil && !user.EmailVerified() {
+			return nil
+		}
+	} else {
+		user, err = s.userRepo.FindByUsername(strings.TrimPrefix(value, "@"))
+	}
+	if err != nil {
+		return nil
+	}
+	return &user.ID
+}
+
+type importRow struct {
+	Title       string
+	Description string
+	Status      string
+	Labels      []string
+	Assignee    string
+	DueDate     *time.Time
+}
+
+func (s *ImportService) run(
+	r io.Reader,
+	mapping ImportMapping,
+	dryRun bool,
+	build func(row importRow) (*models.Task, error),
+	after func(task *models.Task),
+) (*ImportResult, error) {
+	headers, rows, lines, err := readImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := resolveMapping(headers, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{DryRun: dryRun, Total: len(rows), Errors: []ImportRowError{}}
+	tasks := make([]*models.Task, 0, len(rows))
+	for i, record := range rows {
+		row, rowErr := parseImportRow(record, columns)
+		var task *models.Task
+		if rowErr == nil {
+			var err error
+			task, err = build(row)
+			if err != nil {
+				var typed *ImportRowError
+				if errors.As(err, &typed) {
+					rowErr = typed
+				} else {
+					rowErr = &ImportRowError{Message: err.Error()}
+				}
+			}
+		}
+		if rowErr != nil {
+			rowErr.Row = lines[i]
+			result.Errors = append(result.Errors, *rowErr)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	result.Valid = len(tasks)
+
+	if dryRun {
+		return result, nil
+	}
+	if len(result.Errors) > 0 {
+		return result, ErrImportInvalid
+	}
+	if err := s.taskRepo.CreateAll(tasks); err != nil {
+		return nil, errors.New("failed to import tasks")
+	}
+	for _, task := range tasks {
+		after(task)
+	}
+	result.Created = len(tasks)
+	return result, nil
+}
+
+// readImportCSV returns the header, the non-blank rows and the line each row starts on. Line nya
+// dari FieldPos, soalnya baris kosong & cell yg isinya multi-line bikin index != nomor baris
+func readImportCSV(r io.Reader) ([]string, [][]string, []int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	headers, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil, errors.New("csv file is empty")
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid csv: %v", err)
+	}
+	if len(headers) > 0 {
+		headers[0] = strings.TrimPrefix(headers[0], "\ufeff") // BOM dari Excel
+	}
+
+	rows := [][]string{}
+	lines := []int{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid csv: %v", err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, nil, nil, fmt.Errorf("csv has more than %d rows", MaxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, record)
+		lines = append(lines, line)
+	}
+	return headers, rows, lines, nil
+}
+
+// resolveMapping turns field -> header into field -> column index
+func resolveMapping(headers []string, mapping ImportMapping) (map[string]int, error) {
+	columns := map[string]int{}
+	for field, header := range mapping {
+		if header == "" {
+			continue
+		}
+		if !containsFold(ImportFields, field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		index := -1
+		for i, h := range headers {
+			if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(header)) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("column %q not found", header)
+		}
+		columns[field] = index
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("title must be mapped to a column")
+	}
+	return columns, nil
+}
+
+func parseImportRow(record []string, columns map[string]int) (importRow, *ImportRowError) {
+	get := func(field string) string {
+		index, ok := columns[field]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	row := importRow{
+		Title:       get("title"),
+		Description: get("description"),
+		Assignee:    get("assignee"),
+	}
+	if row.Title == "" {
+		return row, &ImportRowError{Field: "title", Message: "title cannot be empty"}
+	}
+
+	status, ok := parseImportStatus(get("status"))
+	if !ok {
+		return row, &ImportRowError{Field: "status", Message: "invalid status " + get("status")}
+	}
+	row.Status = status
+
+	if labels := get("labels"); labels != "" {
+		row.Labels = strings.FieldsFunc(labels, func(r rune) bool { return r == ',' || r == ';' })
+	}
+
+	if due := get("dueDate"); due != "" {
+		dueDate, err := parseImportDate(due)
+		if err != nil {
+			return row, &ImportRowError{Field: "dueDate", Message: "invalid date " + due + ", use YYYY-MM-DD"}
+		}
+		row.DueDate = &dueDate
+	}
+	return row, nil
+}
+
+// parseImportStatus accepts our values plus the usual spreadsheet wording ("To Do", "Doing", "Completed")
+func parseImportStatus(value string) (string, bool) {
+	normalized := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(value)))
+	switch normalized {
+	case "", models.StatusNotStarted, "todo", "to_do", "open", "new", "backlog":
+		return models.StatusNotStarted, true
+	case models.StatusInProgress, "doing", "started", "wip":
+		return models.StatusInProgress, true
+	case models.StatusDone, "completed", "complete", "closed", "finished", "selesai":
+		return models.StatusDone, true
+	}
+	return "", false
+}
+
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date")
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
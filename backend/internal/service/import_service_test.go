@@ -0,0 +1,59 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"minitask/internal/models"
+)
+
+func TestImportErrorsReportFileLines(t *testing.T) {
+	csv := strings.Join([]string{
+		"title,description,status",
+		"First,ok,not_started",
+		"",
+		"Second,\"spans",
+		"three",
+		"lines\",bogus",
+		",,",
+		"Third,after blanks,bogus",
+		"",
+	}, "\n")
+
+	result, err := (&ImportService{}).run(strings.NewReader(csv), ImportMapping{"title": "title", "description": "description", "status": "status"}, true,
+		func(row importRow) (*models.Task, error) {
+			if row.Status != models.StatusNotStarted {
+				return nil, &ImportRowError{Field: "status", Message: "unknown status"}
+			}
+			return &models.Task{Title: row.Title}, nil
+		}, nil)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if result.Total != 3 || result.Valid != 1 {
+		t.Errorf("total = %d valid = %d, want 3 and 1", result.Total, result.Valid)
+	}
+	var lines []int
+	for _, rowErr := range result.Errors {
+		lines = append(lines, rowErr.Row)
+	}
+	if len(lines) != 2 || lines[0] != 4 || lines[1] != 8 {
+		t.Errorf("error lines = %v, want [4 8]", lines)
+	}
+}
+
+func TestReadImportCSVLines(t *testing.T) {
+	headers, rows, lines, err := readImportCSV(strings.NewReader("\ufefftitle\r\nA\r\n\r\n\"B\r\nb\"\r\nC\r\n"))
+	if err != nil {
+		t.Fatalf("readImportCSV() error = %v", err)
+	}
+	if headers[0] != "title" {
+		t.Errorf("header = %q, BOM should be stripped", headers[0])
+	}
+	if len(rows) != 3 || rows[1][0] != "B\nb" {
+		t.Fatalf("rows = %q", rows)
+	}
+	if want := []int{2, 4, 6}; len(lines) != 3 || lines[0] != want[0] || lines[1] != want[1] || lines[2] != want[2] {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+}
@@ -0,0 +1,438 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"minitask/internal/gitlink"
+	"minitask/internal/inbound"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrInboundRejected means the message itself is bad (unparseable, unknown address, ...),
+// ngirim ulang gk bakal berhasil. Error lain (db mati dll) boleh di-retry
+var ErrInboundRejected = errors.New("message rejected")
+
+type InboundEmailService struct {
+	db                *gorm.DB
+	mailboxRepo       repository.InboundMailboxRepository
+	workspaceRepo     repository.WorkspaceRepository
+	userRepo          repository.UserRepository
+	taskRepo          repository.TaskRepository
+	workspaceService  *WorkspaceService
+	commentService    *CommentService
+	attachmentService *AttachmentService
+	domain            string
+	endpointSecret    string
+	authservID        string
+}
+
+func NewInboundEmailService(
+	db *gorm.DB,
+	mailboxRepo repository.InboundMailboxRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	userRepo repository.UserRepository,
+	taskRepo repository.TaskRepository,
+	workspaceService *WorkspaceService,
+	commentService *CommentService,
+	attachmentService *AttachmentService,
+) *InboundEmailService {
+	domain := os.Getenv("INBOUND_EMAIL_DOMAIN")
+	if domain == "" {
+		domain = "inbound.minitask.local"
+	}
+	return &InboundEmailService{
+		db:                db,
+		mailboxRepo:       mailboxRepo,
+		workspaceRepo:     workspaceRepo,
+		userRepo:          userRepo,
+		taskRepo:          taskRepo,
+		workspaceService:  workspaceService,
+		commentService:    commentService,
+		attachmentService: attachmentService,
+		domain:            strings.ToLower(domain),
+		endpointSecret:    os.Getenv("INBOUND_EMAIL_SECRET"),
+		// INBOUND_AUTHSERV_ID = authserv-id di Authentication-Results yg ditulis mail server kita,
+		// tanpa itu semua email masuk atas nama bot mailbox
+		authservID: os.Getenv("INBOUND_AUTHSERV_ID"),
+	}
+}
+
+// VerifyEndpoint checks the shared secret of the raw message endpoint. Tanpa INBOUND_EMAIL_SECRET
+// endpoint nya mati, cuma maildir yg jalan
+func (s *InboundEmailService) VerifyEndpoint(secret string) bool {
+	return s.endpointSecret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.endpointSecret)) == 1
+}
+
+type InboundEmailPage struct {
+	Items []models.InboundEmail `json:"items"`
+	Page  int                   `json:"page"`
+	Limit int                   `json:"limit"`
+	Total int64                 `json:"total"`
+}
+
+func (s *InboundEmailService) authorizeOwner(workspaceID, userID string) (*models.Workspace, error) {
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil || workspace.OwnerID != userID {
+		return nil, errors.New("workspace not found or not authorized")
+	}
+	return workspace, nil
+}
+
+func (s *InboundEmailService) Get(workspaceID, userID string) (*models.InboundMailbox, error) {
+	if _, err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	mailbox, err := s.mailboxRepo.FindByWorkspaceID(workspaceID)
+	if err != nil {
+		return nil, errors.New("inbound email not enabled")
+	}
+	return s.withAddress(mailbox), nil
+}
+
+// Enable gives the workspace an inbound address. Email dari orang luar (client) dibikin atas nama bot nya
+func (s *InboundEmailService) Enable(workspaceID, userID string) (*models.InboundMailbox, error) {
+	workspace, err := s.authorizeOwner(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.mailboxRepo.FindByWorkspaceID(workspaceID); err == nil {
+		return nil, errors.New("inbound email already enabled")
+	}
+	localPart, secret, err := generateLocalPart(workspace.KeyPrefix)
+	if err != nil {
+		return nil, errors.New("failed to enable inbound email")
+	}
+
+	suffix, err := generateBotSuffix()
+	if err != nil {
+		return nil, errors.New("failed to enable inbound email")
+	}
+
+	mailbox := &models.InboundMailbox{
+		WorkspaceID: workspaceID,
+		LocalPart:   localPart,
+		CreatedByID: userID,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		bot, err := createBotMember(tx, workspaceID, "email", suffix, secret)
+		if err != nil {
+			return err
+		}
+		mailbox.BotUserID = bot.ID
+		return tx.Create(mailbox).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to enable inbound email")
+	}
+	return s.withAddress(mailbox), nil
+}
+
+// RotateAddress swaps the local part, address lama langsung mati (misal kebocoran spam)
+func (s *InboundEmailService) RotateAddress(workspaceID, userID string) (*models.InboundMailbox, error) {
+	workspace, err := s.authorizeOwner(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	mailbox, err := s.mailboxRepo.FindByWorkspaceID(workspaceID)
+	if err != nil {
+		return nil, errors.New("inbound email not enabled")
+	}
+	localPart, _, err := generateLocalPart(workspace.KeyPrefix)
+	if err != nil {
+		return nil, errors.New("failed to rotate address")
+	}
+	if err := s.db.Model(mailbox).Update("local_part", localPart).Error; err != nil {
+		return nil, errors.New("failed to rotate address")
+	}
+	return s.withAddress(mailbox), nil
+}
+
+func (s *InboundEmailService) Disable(workspaceID, userID string) error {
+	mailbox, err := s.Get(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.mailboxRepo.Delete(mailbox.ID); err != nil {
+		return errors.New("failed to disable inbound email")
+	}
+	if err := s.workspaceRepo.RemoveMember(workspaceID, mailbox.BotUserID); err != nil {
+		log.Printf("[InboundEmailService] failed to remove bot %s from workspace %s: %v", mailbox.BotUserID, workspaceID, err)
+	}
+	return nil
+}
+
+func (s *InboundEmailService) GetEmails(workspaceID, userID string, page, limit int) (*InboundEmailPage, error) {
+	if _, err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	page, limit = normalizePage(page, limit)
+	items, total, err := s.mailboxRepo.FindEmails(workspaceID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.New("failed to load inbound emails")
+	}
+	if items == nil {
+		items = []models.InboundEmail{}
+	}
+	return &InboundEmailPage{Items: items, Page: page, Limit: limit, Total: total}, nil
+}
+
+// Process turns one raw RFC 5322 message into a task, or into a comment when the subject
+// has a task key of the workspace ("Re: [WS-42] ..."). Message yg sama gk diproses dua kali.
+// Cuma message yg emang gk valid / gk boleh yg jadi ErrInboundRejected, error lain (db mati dll)
+// dibalikin apa adanya biar pengirimnya retry
+func (s *InboundEmailService) Process(raw []byte) (*models.InboundEmail, error) {
+	msg, err := inbound.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInboundRejected, err)
+	}
+	mailbox, err := s.findMailbox(msg.To)
+	if err != nil {
+		return nil, err
+	}
+	if mailbox == nil {
+		return nil, fmt.Errorf("%w: no inbound mailbox for recipient", ErrInboundRejected)
+	}
+	workspace, err := s.workspaceRepo.FindByID(mailbox.WorkspaceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: workspace not found", ErrInboundRejected)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	messageID := msg.MessageID
+	if messageID == "" {
+		sum := sha256.Sum256(raw)
+		messageID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	record := &models.InboundEmail{
+		MailboxID:   mailbox.ID,
+		WorkspaceID: workspace.ID,
+		MessageID:   messageID,
+		From:        msg.From,
+		Subject:     msg.Subject,
+		Result:      models.InboundEmailProcessing,
+	}
+	claimed, err := s.mailboxRepo.ClaimEmail(record)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, nil
+	}
+
+	err = s.deliver(record, workspace, mailbox, msg)
+	if err != nil && !errors.Is(err, ErrInboundRejected) {
+		// gagal sementara: claim nya dilepas biar message nya bisa diproses lagi
+		if releaseErr := s.mailboxRepo.DeleteEmail(record.ID); releaseErr != nil {
+			log.Printf("[InboundEmailService] failed to release email %s: %v", messageID, releaseErr)
+		}
+		return nil, err
+	}
+	if err != nil {
+		record.Result = models.InboundEmailRejected
+		record.Error = err.Error()
+	}
+	if updateErr := s.mailboxRepo.UpdateEmail(record); updateErr != nil {
+		log.Printf("[InboundEmailService] failed to log email %s: %v", messageID, updateErr)
+	}
+	return record, err
+}
+
+// deliver creates the task or comment of a claimed message
+func (s *InboundEmailService) deliver(record *models.InboundEmail, workspace *models.Workspace, mailbox *models.InboundMailbox, msg *inbound.Message) error {
+	actorID, attribution, err := s.resolveSender(workspace.ID, mailbox, msg)
+	if err != nil {
+		return err
+	}
+
+	var task *models.Task
+	refs := gitlink.ParseReferences(workspace.KeyPrefix, msg.Subject)
+	if len(refs) > 0 {
+		task, err = s.taskRepo.FindByWorkspaceAndKey(workspace.ID, refs[0].Key)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	if task != nil {
+		return s.addComment(record, task, actorID, attribution, msg)
+	}
+	return s.createTask(record, workspace.ID, actorID, attribution, msg)
+}
+
+func (s *InboundEmailService) createTask(record *models.InboundEmail, workspaceID, actorID, attribution string, msg *inbound.Message) error {
+	title := inbound.CleanSubject(msg.Subject)
+	if title == "" {
+		title = "(no subject)"
+	}
+	if len(title) > 200 {
+		title = title[:200]
+	}
+	description := msg.Text
+	if attribution != "" {
+		description = strings.TrimSpace(attribution + "\n\n" + description)
+	}
+
+	task, err := s.workspaceService.CreateTask(workspaceID, actorID, &CreateWorkspaceTaskRequest{
+		Title:       title,
+		Description: description,
+	})
+	if err != nil {
+		return err
+	}
+	record.Result = models.InboundEmailTask
+	record.TaskID = &task.ID
+	s.saveAttachments(task.ID, nil, actorID, msg)
+	return nil
+}
+
+func (s *InboundEmailService) addComment(record *models.InboundEmail, task *models.Task, actorID, attribution string, msg *inbound.Message) error {
+	content := inbound.StripQuoted(msg.Text)
+	if content == "" && len(msg.Attachments) == 0 {
+		return fmt.Errorf("%w: reply has no content", ErrInboundRejected)
+	}
+	if content == "" {
+		content = "(attachment)"
+	}
+	if attribution != "" {
+		content = attribution + "\n\n" + content
+	}
+
+	comment, err := s.commentService.Create(&CreateCommentRequest{Content: content, TaskID: task.ID}, actorID)
+	if errors.Is(err, ErrTaskAccessDenied) {
+		return fmt.Errorf("%w: %v", ErrInboundRejected, err)
+	}
+	if err != nil {
+		return err
+	}
+	record.Result = models.InboundEmailComment
+	record.TaskID = &task.ID
+	record.CommentID = &comment.ID
+	s.saveAttachments(task.ID, &comment.ID, actorID, msg)
+	return nil
+}
+
+func (s *InboundEmailService) saveAttachments(taskID string, commentID *string, actorID string, msg *inbound.Message) {
+	for _, file := range msg.Attachments {
+		_, err := s.attachmentService.Save(taskID, commentID, actorID, file.Filename, file.ContentType, bytes.NewReader(file.Data))
+		if err != nil {
+			log.Printf("[InboundEmailService] failed to save attachment %q of task %s: %v", file.Filename, taskID, err)
+		}
+	}
+}
+
+// resolveSender acts as the sender only when our mail server verified the From domain (DKIM/DMARC)
+// and they're a member of the workspace. Selain itu pake bot mailbox + baris "From:" biar tetep
+// keliatan siapa yg ngirim, header From doang gampang dipalsu client yg tau address nya.
+// Bot nya udah bukan member = mailbox nya gk boleh bikin apa2 lagi, itu rejected
+func (s *InboundEmailService) resolveSender(workspaceID string, mailbox *models.InboundMailbox, msg *inbound.Message) (string, string, error) {
+	if msg.SenderAuthenticated(s.authservID) {
+		user, err := s.userRepo.FindByEmail(msg.From)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", err
+		}
+		if err == nil && !user.IsBot && user.EmailVerified() {
+			isMember, err := s.workspaceRepo.IsMember(workspaceID, user.ID)
+			if err != nil {
+				return "", "", err
+			}
+			if isMember {
+				return user.ID, "", nil
+			}
+		}
+	}
+
+	isMember, err := s.workspaceRepo.IsMember(workspaceID, mailbox.BotUserID)
+	if err != nil {
+		return "", "", err
+	}
+	if !isMember {
+		return "", "", fmt.Errorf("%w: inbound mailbox is no longer a workspace member", ErrInboundRejected)
+	}
+	from := msg.From
+	if msg.FromName != "" {
+		from = fmt.Sprintf("%s <%s>", msg.FromName, msg.From)
+	}
+	return mailbox.BotUserID, "From: " + from, nil
+}
+
+// findMailbox matches recipients on our domain, "+tag" di local part diabaikan. Nil tanpa error =
+// gk ada address kita di recipient nya
+func (s *InboundEmailService) findMailbox(recipients []string) (*models.InboundMailbox, error) {
+	for _, address := range recipients {
+		localPart, domain, ok := strings.Cut(address, "@")
+		if !ok || domain != s.domain {
+			continue
+		}
+		localPart, _, _ = strings.Cut(localPart, "+")
+		mailbox, err := s.mailboxRepo.FindByLocalPart(localPart)
+		if err == nil {
+			return mailbox, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// ProcessMaildir reads new messages from a local maildir (INBOUND_MAILDIR) and moves them to cur/.
+// Message yg gagal karena error sementara dibiarin di new/ biar dicoba lagi run berikutnya
+func (s *InboundEmailService) ProcessMaildir(dir string) error {
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, "new", entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("[InboundEmailService] failed to read %s: %v", path, err)
+			continue
+		}
+
+		flags := ":2,S"
+		if _, err := s.Process(raw); err != nil {
+			if !errors.Is(err, ErrInboundRejected) {
+				log.Printf("[InboundEmailService] failed to process %s, will retry: %v", entry.Name(), err)
+				continue
+			}
+			log.Printf("[InboundEmailService] rejected %s: %v", entry.Name(), err)
+			flags = ":2,ST" // seen + trashed
+		}
+		if err := os.Rename(path, filepath.Join(dir, "cur", entry.Name()+flags)); err != nil {
+			log.Printf("[InboundEmailService] failed to move %s to cur: %v", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (s *InboundEmailService) withAddress(mailbox *models.InboundMailbox) *models.InboundMailbox {
+	mailbox.Address = mailbox.LocalPart + "@" + s.domain
+	return mailbox
+}
+
+// generateLocalPart makes e.g. "ws-4f1c9a02b7d3", random part nya yg bikin address susah ditebak
+func generateLocalPart(prefix string) (localPart, secret string, err error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	secret = hex.EncodeToString(b)
+	return strings.ToLower(prefix) + "-" + secret[:12], secret, nil
+}
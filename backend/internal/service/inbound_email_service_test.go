@@ -0,0 +1,221 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"minitask/internal/models"
+	"minitask/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var errDBDown = errors.New("connection refused")
+
+// fakeMailboxRepo stores the claimed email log in memory, lookupErr bikin FindByLocalPart gagal kayak db mati
+type fakeMailboxRepo struct {
+	repository.InboundMailboxRepository
+	mailbox   *models.InboundMailbox
+	lookupErr error
+	emails    map[string]*models.InboundEmail // by mailbox + message id
+	released  []string
+}
+
+func (r *fakeMailboxRepo) FindByLocalPart(localPart string) (*models.InboundMailbox, error) {
+	if r.lookupErr != nil {
+		return nil, r.lookupErr
+	}
+	if r.mailbox == nil || r.mailbox.LocalPart != localPart {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.mailbox, nil
+}
+
+func (r *fakeMailboxRepo) ClaimEmail(email *models.InboundEmail) (bool, error) {
+	key := email.MailboxID + "/" + email.MessageID
+	if _, ok := r.emails[key]; ok {
+		return false, nil
+	}
+	email.ID = "email-" + email.MessageID
+	copied := *email
+	r.emails[key] = &copied
+	return true, nil
+}
+
+func (r *fakeMailboxRepo) UpdateEmail(email *models.InboundEmail) error {
+	copied := *email
+	r.emails[email.MailboxID+"/"+email.MessageID] = &copied
+	return nil
+}
+
+func (r *fakeMailboxRepo) DeleteEmail(id string) error {
+	for key, email := range r.emails {
+		if email.ID == id {
+			delete(r.emails, key)
+		}
+	}
+	r.released = append(r.released, id)
+	return nil
+}
+
+type inboundWorkspaceRepo struct {
+	repository.WorkspaceRepository
+	workspace *models.Workspace
+	members   map[string]bool
+	memberErr error
+}
+
+func (r *inboundWorkspaceRepo) FindByID(id string) (*models.Workspace, error) {
+	if r.workspace == nil || r.workspace.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.workspace, nil
+}
+
+func (r *inboundWorkspaceRepo) IsMember(workspaceID, userID string) (bool, error) {
+	if r.memberErr != nil {
+		return false, r.memberErr
+	}
+	return r.members[userID], nil
+}
+
+func newInboundTestService(mailboxes *fakeMailboxRepo, workspaces *inboundWorkspaceRepo) *InboundEmailService {
+	return &InboundEmailService{
+		mailboxRepo:   mailboxes,
+		workspaceRepo: workspaces,
+		domain:        "inbound.test",
+	}
+}
+
+func inboundFixture() (*fakeMailboxRepo, *inboundWorkspaceRepo) {
+	mailboxes := &fakeMailboxRepo{
+		mailbox: &models.InboundMailbox{ID: "mb1", WorkspaceID: "ws1", LocalPart: "ws-abc", BotUserID: "bot1"},
+		emails:  map[string]*models.InboundEmail{},
+	}
+	workspaces := &inboundWorkspaceRepo{
+		workspace: &models.Workspace{ID: "ws1", KeyPrefix: "WS"},
+		members:   map[string]bool{},
+	}
+	return mailboxes, workspaces
+}
+
+func rawEmail(to, messageID string) []byte {
+	return []byte(strings.Join([]string{
+		"From: Client <client@example.com>",
+		"To: " + to,
+		"Subject: Login broken",
+		"Message-ID: <" + messageID + ">",
+		"",
+		"It's broken.",
+		"",
+	}, "\r\n"))
+}
+
+func TestProcessRejectsBadMessages(t *testing.T) {
+	mailboxes, workspaces := inboundFixture()
+	s := newInboundTestService(mailboxes, workspaces)
+
+	for name, raw := range map[string][]byte{
+		"unparseable":       []byte("just text"),
+		"unknown recipient": rawEmail("nobody@inbound.test", "m1@example.com"),
+		"other domain":      rawEmail("ws-abc@elsewhere.test", "m2@example.com"),
+	} {
+		if _, err := s.Process(raw); !errors.Is(err, ErrInboundRejected) {
+			t.Errorf("%s: Process() error = %v, want ErrInboundRejected", name, err)
+		}
+	}
+	if len(mailboxes.emails) != 0 {
+		t.Errorf("rejected before claiming, but %d emails were logged", len(mailboxes.emails))
+	}
+}
+
+func TestProcessRejectsWhenBotWasRemoved(t *testing.T) {
+	mailboxes, workspaces := inboundFixture()
+	s := newInboundTestService(mailboxes, workspaces)
+
+	record, err := s.Process(rawEmail("ws-abc@inbound.test", "m1@example.com"))
+	if !errors.Is(err, ErrInboundRejected) {
+		t.Fatalf("Process() error = %v, want ErrInboundRejected", err)
+	}
+	if record == nil || record.Result != models.InboundEmailRejected {
+		t.Fatalf("record = %+v", record)
+	}
+	if logged := mailboxes.emails["mb1/m1@example.com"]; logged == nil || logged.Result != models.InboundEmailRejected {
+		t.Errorf("logged = %+v, want a rejected entry", logged)
+	}
+}
+
+func TestProcessTemporaryErrorsAreNotRejections(t *testing.T) {
+	t.Run("mailbox lookup", func(t *testing.T) {
+		mailboxes, workspaces := inboundFixture()
+		mailboxes.lookupErr = errDBDown
+		_, err := newInboundTestService(mailboxes, workspaces).Process(rawEmail("ws-abc@inbound.test", "m1@example.com"))
+		if err == nil || errors.Is(err, ErrInboundRejected) {
+			t.Fatalf("Process() error = %v, want a retryable error", err)
+		}
+	})
+
+	t.Run("membership check releases the claim", func(t *testing.T) {
+		mailboxes, workspaces := inboundFixture()
+		workspaces.memberErr = errDBDown
+		s := newInboundTestService(mailboxes, workspaces)
+
+		_, err := s.Process(rawEmail("ws-abc@inbound.test", "m1@example.com"))
+		if err == nil || errors.Is(err, ErrInboundRejected) {
+			t.Fatalf("Process() error = %v, want a retryable error", err)
+		}
+		if len(mailboxes.emails) != 0 || len(mailboxes.released) != 1 {
+			t.Fatalf("emails = %v released = %v, want the claim released", mailboxes.emails, mailboxes.released)
+		}
+
+		// db nya idup lagi: message yg sama diproses ulang, bukan dianggep duplikat
+		workspaces.memberErr = nil
+		if _, err := s.Process(rawEmail("ws-abc@inbound.test", "m1@example.com")); !errors.Is(err, ErrInboundRejected) {
+			t.Errorf("retry error = %v, want it processed again (and rejected, bot is not a member)", err)
+		}
+	})
+}
+
+func TestProcessSkipsClaimedMessages(t *testing.T) {
+	mailboxes, workspaces := inboundFixture()
+	mailboxes.emails["mb1/m1@example.com"] = &models.InboundEmail{ID: "e0", MailboxID: "mb1", MessageID: "m1@example.com", Result: models.InboundEmailProcessing}
+	s := newInboundTestService(mailboxes, workspaces)
+
+	record, err := s.Process(rawEmail("ws-abc@inbound.test", "m1@example.com"))
+	if err != nil || record != nil {
+		t.Fatalf("Process() = %+v, %v, want nil, nil for a duplicate", record, err)
+	}
+}
+
+func TestProcessMaildirKeepsRetryableMessagesInNew(t *testing.T) {
+	mailboxes, workspaces := inboundFixture()
+	s := newInboundTestService(mailboxes, workspaces)
+
+	dir := t.TempDir()
+	for _, sub := range []string{"new", "cur", "tmp"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name string, raw []byte) {
+		if err := os.WriteFile(filepath.Join(dir, "new", name), raw, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("rejected", []byte("just text"))
+	write("retry", rawEmail("ws-abc@inbound.test", "m1@example.com"))
+	workspaces.memberErr = errDBDown
+
+	if err := s.ProcessMaildir(dir); err != nil {
+		t.Fatalf("ProcessMaildir() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new", "retry")); err != nil {
+		t.Errorf("retryable message left new/: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cur", "rejected:2,ST")); err != nil {
+		t.Errorf("rejected message not trashed: %v", err)
+	}
+}
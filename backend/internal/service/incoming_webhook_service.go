@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"strings"
+	"time"
+)
+
+const incomingTokenPrefix = "mt_in_"
+
+type IncomingWebhookService struct {
+	db                  *gorm.DB
+	incomingWebhookRepo repository.IncomingWebhookRepository
+	workspaceRepo       repository.WorkspaceRepository
+	userRepo            repository.UserRepository
+	workspaceService    *WorkspaceService
+}
+
+func NewIncomingWebhookService(
+	db *gorm.DB,
+	incomingWebhookRepo repository.IncomingWebhookRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	userRepo repository.UserRepository,
+	workspaceService *WorkspaceService,
+) *IncomingWebhookService {
+	return &IncomingWebhookService{
+		db:                  db,
+		incomingWebhookRepo: incomingWebhookRepo,
+		workspaceRepo:       workspaceRepo,
+		userRepo:            userRepo,
+		workspaceService:    workspaceService,
+	}
+}
+
+type CreateIncomingWebhookRequest struct {
+	Name string `json:"name"`
+}
+
+// IncomingWebhookWithToken is only returned on create/rotate, token nya gk disimpen plain
+type IncomingWebhookWithToken struct {
+	models.IncomingWebhook
+	Token string `json:"token"`
+}
+
+// IncomingTaskPayload is what external systems post to their incoming webhook url
+type IncomingTaskPayload struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Labels      []string   `json:"labels"`
+	Assignee    string     `json:"assignee"` // username
+	DueDate     *time.Time `json:"dueDate"`
+}
+
+type IntegrationTaskPage struct {
+	Items []models.IntegrationTask `json:"items"`
+	Page  int                      `json:"page"`
+	Limit int                      `json:"limit"`
+	Total int64                    `json:"total"`
+}
+
+func (s *IncomingWebhookService) authorizeOwner(workspaceID, userID string) (*models.Workspace, error) {
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil || workspace.OwnerID != userID {
+		return nil, errors.New("workspace not found or not authorized")
+	}
+	return workspace, nil
+}
+
+func (s *IncomingWebhookService) GetAll(workspaceID, userID string) ([]models.IncomingWebhook, error) {
+	if _, err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	webhooks, err := s.incomingWebhookRepo.FindAllByWorkspaceID(workspaceID)
+	if err != nil {
+		return nil, errors.New("failed to load incoming webhooks")
+	}
+	if webhooks == nil {
+		webhooks = []models.IncomingWebhook{}
+	}
+	return webhooks, nil
+}
+
+// Create makes the bot user for the integration, adds it to the workspace and hands out the token
+func (s *IncomingWebhookService) Create(workspaceID, userID string, req *CreateIncomingWebhookRequest) (*IncomingWebhookWithToken, error) {
+	if _, err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	token, hash, err := generateIncomingToken()
+	if err != nil {
+		return nil, errors.New("failed to create incoming webhook")
+	}
+	suffix, err := generateBotSuffix()
+	if err != nil {
+		return nil, errors.New("failed to create incoming webhook")
+	}
+	webhook := &models.IncomingWebhook{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		TokenHash:   hash,
+		TokenPrefix: token[:len(incomingTokenPrefix)+6],
+		CreatedByID: userID,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		bot, err := createBotMember(tx, workspaceID, name, suffix, token)
+		if err != nil {
+			return err
+		}
+		webhook.BotUserID = bot.ID
+		webhook.BotUser = bot
+		return tx.Omit("BotUser").Create(webhook).Error
+	})
+	if err != nil {
+		return nil, errors.New("failed to create incoming webhook")
+	}
+	return &IncomingWebhookWithToken{IncomingWebhook: *webhook, Token: token}, nil
+}
+
+// RotateToken invalidates the old url right away
+func (s *IncomingWebhookService) RotateToken(workspaceID, webhookID, userID string) (*IncomingWebhookWithToken, error) {
+	if _, err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	webhook, err := s.incomingWebhookRepo.FindByID(webhookID, workspaceID)
+	if err != nil {
+		return nil, errors.New("incoming webhook not found")
+	}
+
+	token, hash, err := generateIncomingToken()
+	if err != nil {
+		return nil, errors.New("failed to rotate token")
+	}
+	webhook.TokenHash = hash
+	webhook.TokenPrefix = token[:len(incomingTokenPrefix)+6]
+	if err := s.incomingWebhookRepo.UpdateToken(webhook.ID, webhook.TokenHash, webhook.TokenPrefix); err != nil {
+		return nil, errors.New("failed to rotate token")
+	}
+	return &IncomingWebhookWithToken{IncomingWebhook: *webhook, Token: token}, nil
+}
+
+// Delete removes the integration and takes its bot out of the workspace. The bot user itself
+// stays so tasks it created still show who made them
+func (s *IncomingWebhookService) Delete(workspaceID, webhookID, userID string) error {
+	if _, err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return err
+	}
+	webhook, err := s.incomingWebhookRepo.FindByID(webhookID, workspaceID)
+	if err != nil {
+		return errors.New("incoming webhook not found")
+	}
+	if err := s.incomingWebhookRepo.Delete(webhook.ID); err != nil {
+		return errors.New("failed to delete incoming webhook")
+	}
+	if err := s.workspaceRepo.RemoveMember(workspaceID, webhook.BotUserID); err != nil {
+		log.Printf("[IncomingWebhookService] failed to remove bot %s from workspace %s: %v", webhook.BotUserID, workspaceID, err)
+	}
+	return nil
+}
+
+func (s *IncomingWebhookService) GetTasks(workspaceID, webhookID, userID string, page, limit int) (*IntegrationTaskPage, error) {
+	if _, err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.incomingWebhookRepo.FindByID(webhookID, workspaceID); err != nil {
+		return nil, errors.New("incoming webhook not found")
+	}
+
+	page, limit = normalizePage(page, limit)
+	items, total, err := s.incomingWebhookRepo.FindIntegrationTasks(webhookID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.New("failed to load integration tasks")
+	}
+	if items == nil {
+		items = []models.IntegrationTask{}
+	}
+	for i := range items {
+		if items[i].Task != nil {
+			renderTask(items[i].Task)
+		}
+	}
+	return &IntegrationTaskPage{Items: items, Page: page, Limit: limit, Total: total}, nil
+}
+
+// Authenticate resolves the token from an incoming webhook url
+func (s *IncomingWebhookService) Authenticate(token string) (*models.IncomingWebhook, error) {
+	if !strings.HasPrefix(token, incomingTokenPrefix) {
+		return nil, errors.New("invalid token")
+	}
+	webhook, err := s.incomingWebhookRepo.FindByTokenHash(hashToken(token))
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+	// workspace udah dihapus = url nya mati juga
+	if _, err := s.workspaceRepo.FindByID(webhook.WorkspaceID); err != nil {
+		return nil, errors.New("invalid token")
+	}
+	return webhook, nil
+}
+
+// CreateTask creates a task as the integration's bot through the normal workspace flow,
+// so mentions, activity, realtime events and outgoing webhooks all still happen
+func (s *IncomingWebhookService) CreateTask(webhook *models.IncomingWebhook, payload *IncomingTaskPayload, raw []byte, sourceIP string) (*models.Task, error) {
+	req := &CreateWorkspaceTaskRequest{
+		Title:       strings.TrimSpace(payload.Title),
+		Description: payload.Description,
+		Labels:      payload.Labels,
+		DueDate:     payload.DueDate,
+	}
+	if payload.Assignee != "" {
+		assignee, err := s.userRepo.FindByUsername(strings.TrimPrefix(payload.Assignee, "@"))
+		if err != nil {
+			return nil, errors.New("assignee not found")
+		}
+		req.AssigneeID = &assignee.ID
+	}
+
+	task, err := s.workspaceService.CreateTask(webhook.WorkspaceID, webhook.BotUserID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &models.IntegrationTask{
+		IncomingWebhookID: webhook.ID,
+		WorkspaceID:       webhook.WorkspaceID,
+		TaskID:            task.ID,
+		Payload:           compactJSON(raw),
+		SourceIP:          sourceIP,
+	}
+	if err := s.incomingWebhookRepo.CreateIntegrationTask(record); err != nil {
+		log.Printf("[IncomingWebhookService] failed to audit task %s from %s: %v", task.ID, webhook.ID, err)
+	}
+	if err := s.incomingWebhookRepo.TouchLastUsed(webhook.ID, now); err != nil {
+		log.Printf("[IncomingWebhookService] failed to update last used of %s: %v", webhook.ID, err)
+	}
+	return task, nil
+}
+
+func generateIncomingToken() (token, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = incomingTokenPrefix + hex.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// botUsername makes a mention-friendly username out of the integration name
+func botUsername(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			b.WriteRune('-')
+		}
+		if b.Len() >= 24 {
+			break
+		}
+	}
+	username := strings.Trim(b.String(), "-")
+	if username == "" {
+		return "integration"
+	}
+	return username
+}
+
+// compactJSON keeps the raw payload for the audit log, re-encoded without whitespace
+func compactJSON(raw []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"fmt"
+	"minitask/internal/models"
+	"strings"
+)
+
+const (
+	maxLabels      = 10
+	maxLabelLength = 50
+)
+
+// NormalizeLabels trims, lowercases and dedupes task labels
+func NormalizeLabels(labels []string) (models.StringList, error) {
+	normalized := models.StringList{}
+	seen := map[string]bool{}
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label == "" || seen[label] {
+			continue
+		}
+		if len([]rune(label)) > maxLabelLength {
+			return nil, fmt.Errorf("labels can be at most %d characters", maxLabelLength)
+		}
+		seen[label] = true
+		normalized = append(normalized, label)
+	}
+	if len(normalized) > maxLabels {
+		return nil, fmt.Errorf("a task can have at most %d labels", maxLabels)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"os"
+	"strings"
+)
+
+func frontendURL() string {
+	return strings.TrimRight(os.Getenv("FRONTEND_URL"), "/")
+}
+
+// TaskLink points at the page where a task is shown in the frontend
+func TaskLink(workspaceID *string, taskID string) string {
+	if workspaceID != nil {
+		return frontendURL() + "/workspace/" + *workspaceID + "?task=" + taskID
+	}
+	return frontendURL() + "/tasks/" + taskID
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// @username, tapi bukan bagian dari email (rina@mail.com)
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_][A-Za-z0-9_.-]*)`)
+
+type MentionService struct {
+	db                  *gorm.DB
+	mentionRepo         repository.MentionRepository
+	userRepo            repository.UserRepository
+	workspaceRepo       repository.WorkspaceRepository
+	notificationService *NotificationService
+}
+
+func NewMentionService(
+	db *gorm.DB,
+	mentionRepo repository.MentionRepository,
+	userRepo repository.UserRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	notificationService *NotificationService,
+) *MentionService {
+	return &MentionService{
+		db:                  db,
+		mentionRepo:         mentionRepo,
+		userRepo:            userRepo,
+		workspaceRepo:       workspaceRepo,
+		notificationService: notificationService,
+	}
+}
+
+// ParseMentions returns the unique usernames mentioned in text, in order of appearance
+func ParseMentions(text string) []string {
+	seen := map[string]bool{}
+	var usernames []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// SyncCommentMentions stores the mention links of a comment and returns the users that were newly mentioned
+func (s *MentionService) SyncCommentMentions(comment *models.Comment, task *models.Task) ([]models.User, error) {
+	return s.sync(models.MentionSourceComment, comment.Content, comment.UserID, task, &comment.ID)
+}
+
+// SyncTaskMentions stores the mention links of a task description and returns the users that were newly mentioned
+func (s *MentionService) SyncTaskMentions(task *models.Task, authorID string) ([]models.User, error) {
+	return s.sync(models.MentionSourceTask, task.Description, authorID, task, nil)
+}
+
+func (s *MentionService) RemoveCommentMentions(comment *models.Comment) error {
+	return s.mentionRepo.DeleteBySource(models.MentionSourceComment, comment.TaskID, &comment.ID)
+}
+
+func (s *MentionService) GetMentionsForUser(userID string) ([]models.Mention, error) {
+	mentions, err := s.mentionRepo.FindAllByMentionedUserID(userID)
+	if err != nil {
+		return nil, errors.New("failed to load mentions")
+	}
+	renderMentions(mentions)
+	return mentions, nil
+}
+
+func (s *MentionService) sync(sourceType, text, authorID string, task *models.Task, commentID *string) ([]models.User, error) {
+	// Mentions cuma berlaku di workspace, personal task gk punya member lain
+	if task.WorkspaceID == nil {
+		return nil, s.mentionRepo.DeleteBySource(sourceType, task.ID, commentID)
+	}
+	workspaceID := *task.WorkspaceID
+
+	wanted := map[string]models.User{}
+	for _, username := range ParseMentions(text) {
+		user, err := s.userRepo.FindByUsername(username)
+		if err != nil || user.ID == authorID {
+			continue
+		}
+		isMember, err := s.workspaceRepo.IsMember(workspaceID, user.ID)
+		if err != nil || !isMember {
+			continue
+		}
+		wanted[user.ID] = *user
+	}
+
+	existing, err := s.mentionRepo.FindAllBySource(sourceType, task.ID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep mentions that are still in the text so editing doesn't re-notify anyone
+	for _, mention := range existing {
+		if _, ok := wanted[mention.MentionedUserID]; ok {
+			delete(wanted, mention.MentionedUserID)
+			continue
+		}
+		if err := s.mentionRepo.Delete(mention.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var added []models.User
+	for _, user := range wanted {
+		mention := &models.Mention{
+			MentionedUserID: user.ID,
+			AuthorID:        authorID,
+			SourceType:      sourceType,
+			TaskID:          task.ID,
+			CommentID:       commentID,
+			WorkspaceID:     workspaceID,
+		}
+		created, err := s.mentionRepo.Create(mention)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			continue
+		}
+		added = append(added, user)
+
+		s.notificationService.Notify(NotifyInput{
+			UserID:      user.ID,
+			Type:        models.NotificationMentioned,
+			ActorID:     authorID,
+			WorkspaceID: &workspaceID,
+			TaskID:      &task.ID,
+			CommentID:   commentID,
+			Subject:     task.Title,
+			Excerpt:     text,
+		})
+	}
+	return added, nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"minitask/internal/mailer"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationService struct {
+	db               *gorm.DB
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	mailer           mailer.Mailer
+	sending          sync.WaitGroup
+}
+
+func NewNotificationService(
+	db *gorm.DB,
+	notificationRepo repository.NotificationRepository,
+	userRepo repository.UserRepository,
+	mail mailer.Mailer,
+) *NotificationService {
+	return &NotificationService{
+		db:               db,
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		mailer:           mail,
+	}
+}
+
+// NotifyInput describes something that happened to a user. Subject is the thing it happened
+// on (task title, workspace name), Excerpt optional extra text like the comment content.
+type NotifyInput struct {
+	UserID      string
+	Type        string
+	ActorID     string
+	WorkspaceID *string
+	TaskID      *string
+	CommentID   *string
+	Subject     string
+	Excerpt     string
+	DedupeKey   string
+}
+
+type NotificationPage struct {
+	Items  []models.Notification `json:"items"`
+	Page   int                   `json:"page"`
+	Limit  int                   `json:"limit"`
+	Total  int64                 `json:"total"`
+	Unread int64                 `json:"unread"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []models.NotificationPreference `json:"preferences"`
+}
+
+// Notify records a notification and emails it (for types that have an email template) unless
+// the user turned that channel off, the user is the actor themself, or the dedupe key was already
+// used. Errors are only logged, same as activity.
+func (s *NotificationService) Notify(input NotifyInput) {
+	if input.UserID == "" || input.UserID == input.ActorID {
+		return
+	}
+	inApp, email := s.channels(input.UserID, input.Type)
+	if !inApp && !email {
+		return
+	}
+
+	notification := &models.Notification{
+		UserID:      input.UserID,
+		Type:        input.Type,
+		WorkspaceID: input.WorkspaceID,
+		TaskID:      input.TaskID,
+		CommentID:   input.CommentID,
+		Title:       s.title(input),
+		Body:        excerpt(input.Excerpt, 200),
+		Hidden:      !inApp,
+	}
+	if input.ActorID != "" {
+		notification.ActorID = &input.ActorID
+	}
+	if input.DedupeKey != "" {
+		notification.DedupeKey = &input.DedupeKey
+	}
+	if notification.Body == "" {
+		notification.Body = input.Subject
+	}
+
+	created, err := s.notificationRepo.Create(notification)
+	if err != nil {
+		log.Printf("[NotificationService] failed to notify %s (%s): %v", input.UserID, input.Type, err)
+		return
+	}
+	// udah pernah dikirim (dedupe key sama), email nya juga gk usah
+	if created && email {
+		s.sendEmail(notification)
+	}
+}
+
+// sendEmail mails a notification in the background so SMTP never slows down the request
+func (s *NotificationService) sendEmail(notification *models.Notification) {
+	user, err := s.userRepo.FindByID(notification.UserID)
+	if err != nil {
+		return
+	}
+
+	link := frontendURL() + "/dashboard"
+	if notification.TaskID != nil {
+		link = TaskLink(notification.WorkspaceID, *notification.TaskID)
+	}
+	msg, err := mailer.Render(notification.Type, user.Email, mailer.NotificationEmail{
+		Recipient: user.Username,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Link:      link,
+	})
+	if err != nil {
+		log.Printf("[NotificationService] failed to render %s email: %v", notification.Type, err)
+		return
+	}
+	s.SendAsync(msg)
+}
+
+// SendAsync sends an email without waiting for the SMTP server, failures are logged
+func (s *NotificationService) SendAsync(msg mailer.Message) {
+	s.sending.Add(1)
+	go func() {
+		defer s.sending.Done()
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("[NotificationService] failed to send email to %s: %v", msg.To, err)
+		}
+	}()
+}
+
+// Wait blocks until every email queued with SendAsync has been handed to the SMTP server
+func (s *NotificationService) Wait() {
+	s.sending.Wait()
+}
+
+func (s *NotificationService) title(input NotifyInput) string {
+	actor := "Someone"
+	if input.ActorID != "" {
+		if user, err := s.userRepo.FindByID(input.ActorID); err == nil {
+			actor = user.Username
+		}
+	}
+
+	switch input.Type {
+	case models.NotificationTaskAssigned:
+		return fmt.Sprintf("%s assigned you to \"%s\"", actor, input.Subject)
+	case models.NotificationMentioned:
+		return fmt.Sprintf("%s mentioned you in \"%s\"", actor, input.Subject)
+	case models.NotificationTaskCommented:
+		return fmt.Sprintf("%s commented on \"%s\"", actor, input.Subject)
+	case models.NotificationWorkspaceRemoved:
+		return fmt.Sprintf("You were removed from %s", input.Subject)
+	case models.NotificationPlanExpiring:
+		return fmt.Sprintf("Your Pro plan expires on %s", input.Subject)
+	case models.NotificationTaskUpdated:
+		return fmt.Sprintf("%s updated \"%s\"", actor, input.Subject)
+	case models.NotificationTaskDueSoon:
+		return fmt.Sprintf("\"%s\" is due soon", input.Subject)
+	}
+	return input.Subject
+}
+
+func excerpt(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "…"
+}
+
+// channels tells which ways a user wants to hear about a notification type. Email only
+// applies to types that have an email template
+func (s *NotificationService) channels(userID, notificationType string) (inApp, email bool) {
+	hasEmail := mailer.HasTemplate(notificationType)
+	pref, err := s.notificationRepo.FindPreference(userID, notificationType)
+	if err != nil {
+		// belum pernah di set = default
+		return true, hasEmail && models.DefaultEmailPreference(notificationType)
+	}
+	return pref.InApp, hasEmail && pref.EmailEnabled()
+}
+
+func (s *NotificationService) List(userID string, unreadOnly bool, page, limit int) (*NotificationPage, error) {
+	page, limit = normalizePage(page, limit)
+	items, total, err := s.notificationRepo.FindByUserID(userID, unreadOnly, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.New("failed to load notifications")
+	}
+	unread, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, errors.New("failed to load notifications")
+	}
+	if items == nil {
+		items = []models.Notification{}
+	}
+	return &NotificationPage{Items: items, Page: page, Limit: limit, Total: total, Unread: unread}, nil
+}
+
+func (s *NotificationService) UnreadCount(userID string) (int64, error) {
+	count, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return 0, errors.New("failed to count notifications")
+	}
+	return count, nil
+}
+
+func (s *NotificationService) GetByID(id, userID string) (*models.Notification, error) {
+	notification, err := s.notificationRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, errors.New("notification not found")
+	}
+	return notification, nil
+}
+
+func (s *NotificationService) MarkRead(id, userID string) error {
+	now := time.Now()
+	if err := s.notificationRepo.SetReadAt(id, userID, &now); err != nil {
+		return errors.New("notification not found")
+	}
+	return nil
+}
+
+func (s *NotificationService) MarkUnread(id, userID string) error {
+	if err := s.notificationRepo.SetReadAt(id, userID, nil); err != nil {
+		return errors.New("notification not found")
+	}
+	return nil
+}
+
+func (s *NotificationService) MarkAllRead(userID string) error {
+	if err := s.notificationRepo.MarkAllRead(userID); err != nil {
+		return errors.New("failed to mark notifications as read")
+	}
+	return nil
+}
+
+// GetPreferences returns one entry per notification type, filling in defaults
+func (s *NotificationService) GetPreferences(userID string) ([]models.NotificationPreference, error) {
+	stored, err := s.notificationRepo.FindPreferences(userID)
+	if err != nil {
+		return nil, errors.New("failed to load notification preferences")
+	}
+	byType := map[string]models.NotificationPreference{}
+	for _, pref := range stored {
+		byType[pref.Type] = pref
+	}
+
+	prefs := make([]models.NotificationPreference, 0, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		pref, ok := byType[notificationType]
+		if !ok {
+			pref = models.NotificationPreference{UserID: userID, Type: notificationType, InApp: true}
+		}
+		if pref.Email == nil {
+			email := pref.EmailEnabled()
+			pref.Email = &email
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, nil
+}
+
+func (s *NotificationService) UpdatePreferences(userID string, req *UpdateNotificationPreferencesRequest) ([]models.NotificationPreference, error) {
+	for _, pref := range req.Preferences {
+		if !isNotificationType(pref.Type) {
+			return nil, fmt.Errorf("unknown notification type: %s", pref.Type)
+		}
+	}
+
+	for _, pref := range req.Preferences {
+		record := &models.NotificationPreference{
+			UserID: userID,
+			Type:   pref.Type,
+			InApp:  pref.InApp,
+			Email:  pref.Email,
+		}
+		if err := s.notificationRepo.UpsertPreference(record); err != nil {
+			return nil, errors.New("failed to save notification preferences")
+		}
+	}
+	return s.GetPreferences(userID)
+}
+
+func isNotificationType(notificationType string) bool {
+	for _, t := range models.NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
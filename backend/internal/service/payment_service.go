@@ -19,17 +19,31 @@ import (
 )
 
 type PaymentService struct {
-	db       *gorm.DB
-	userRepo repository.UserRepository
+	db                  *gorm.DB
+	userRepo            repository.UserRepository
+	activityService     *ActivityService
+	notificationService *NotificationService
 }
 
-func NewPaymentService(db *gorm.DB, userRepo repository.UserRepository) *PaymentService {
-	return &PaymentService{db: db, userRepo: userRepo}
+func NewPaymentService(
+	db *gorm.DB,
+	userRepo repository.UserRepository,
+	activityService *ActivityService,
+	notificationService *NotificationService,
+) *PaymentService {
+	return &PaymentService{
+		db:                  db,
+		userRepo:            userRepo,
+		activityService:     activityService,
+		notificationService: notificationService,
+	}
 }
 
 const (
 	ProPlanPrice    = 49000
 	ProPlanDuration = 30
+	// warn users this long before their Pro plan runs out
+	PlanExpiryWarning = 3 * 24 * time.Hour
 )
 
 type CreateCheckoutRequest struct {
@@ -88,6 +102,10 @@ func (s *PaymentService) CreateSnapToken(userID string, plan string) (*CheckoutR
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
+	// email nya dikirim ke midtrans buat bukti bayar, harus email yg beneran punya dia
+	if !user.EmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
 
 	if plan != "pro" {
 		return nil, errors.New("invalid plan")
@@ -171,6 +189,11 @@ func (s *PaymentService) CreateSnapToken(userID string, plan string) (*CheckoutR
 	if err := s.db.Create(pendingOrder).Error; err != nil {
 		return nil, errors.New("failed to save payment order")
 	}
+	s.recordPayment(userID, pendingOrder.ID, models.ActivityCheckoutCreated, nil, map[string]interface{}{
+		"orderId": orderID,
+		"plan":    plan,
+		"amount":  ProPlanPrice,
+	})
 
 	return &CheckoutResponse{
 		Token:       snapResp.Token,
@@ -220,9 +243,14 @@ func (s *PaymentService) HandleWebhook(notification *MidtransNotification) error
 		if err := s.db.Model(&order).Update("status", "paid").Error; err != nil {
 			return errors.New("failed to update order status")
 		}
+		s.recordPlanUpgrade(&user, &order, newExpiry)
 
 	} else if notification.TransactionStatus == "expire" || notification.TransactionStatus == "cancel" {
+		previousStatus := order.Status
 		s.db.Model(&order).Update("status", notification.TransactionStatus)
+		s.recordPayment(order.UserID, order.ID, models.ActivityOrderClosed,
+			map[string]interface{}{"status": previousStatus},
+			map[string]interface{}{"status": notification.TransactionStatus})
 	}
 
 	return nil
@@ -299,6 +327,7 @@ func (s *PaymentService) VerifyPayment(userID string, orderID string) (string, e
 		}
 
 		s.db.Model(&order).Update("status", "paid")
+		s.recordPlanUpgrade(&user, &order, newExpiry)
 		log.Printf("[VerifyPayment] user upgraded to pro successfully")
 		return "pro", nil
 	}
@@ -308,11 +337,67 @@ func (s *PaymentService) VerifyPayment(userID string, orderID string) (string, e
 }
 
 func (s *PaymentService) CheckAndDowngradeExpiredPlans(userID string) error {
-	return s.db.Model(&models.User{}).
+	result := s.db.Model(&models.User{}).
 		Where("id = ? AND plan != 'free' AND plan_expires_at < ?", userID, time.Now()).
 		Updates(map[string]interface{}{
 			"plan": "free",
-		}).Error
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		s.recordPayment(userID, userID, models.ActivityPlanDowngraded,
+			map[string]interface{}{"plan": "pro"},
+			map[string]interface{}{"plan": "free"})
+	}
+	return nil
+}
+
+// NotifyExpiringPlans warns every Pro user whose plan ends within PlanExpiryWarning.
+// Dedupe key pakai tanggal expiry, jadi perpanjang plan = notif baru lagi nanti
+func (s *PaymentService) NotifyExpiringPlans() error {
+	now := time.Now()
+	var users []models.User
+	err := s.db.
+		Where("plan = 'pro' AND plan_expires_at > ? AND plan_expires_at <= ?", now, now.Add(PlanExpiryWarning)).
+		Find(&users).Error
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		s.notificationService.Notify(NotifyInput{
+			UserID:    user.ID,
+			Type:      models.NotificationPlanExpiring,
+			Subject:   user.PlanExpiresAt.Format("2 Jan 2006"),
+			DedupeKey: fmt.Sprintf("plan_expiring:%d", user.PlanExpiresAt.Unix()),
+		})
+	}
+	return nil
+}
+
+func (s *PaymentService) recordPlanUpgrade(user *models.User, order *models.PendingOrder, newExpiry time.Time) {
+	before := map[string]interface{}{"plan": user.Plan, "planExpiresAt": nil}
+	if user.PlanExpiresAt != nil {
+		before["planExpiresAt"] = *user.PlanExpiresAt
+	}
+	s.recordPayment(order.UserID, order.ID, models.ActivityPlanUpgraded, before, map[string]interface{}{
+		"plan":          "pro",
+		"planExpiresAt": newExpiry,
+	})
+}
+
+// payment activity selalu atas nama user yg bayar, webhook midtrans gk punya actor sendiri
+func (s *PaymentService) recordPayment(userID, entityID, action string, before, after map[string]interface{}) {
+	s.activityService.Record(ActivityEntry{
+		ActorID:    userID,
+		EntityType: models.ActivityEntityPayment,
+		EntityID:   entityID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+	})
 }
 
 func (s *PaymentService) GetUserPlan(userID string) (*models.User, error) {
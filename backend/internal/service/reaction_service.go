@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+type ReactionService struct {
+	db           *gorm.DB
+	reactionRepo repository.ReactionRepository
+	commentRepo  repository.CommentRepository
+	accessPolicy *TaskAccessPolicy
+}
+
+func NewReactionService(
+	db *gorm.DB,
+	reactionRepo repository.ReactionRepository,
+	commentRepo repository.CommentRepository,
+	accessPolicy *TaskAccessPolicy,
+) *ReactionService {
+	return &ReactionService{
+		db:           db,
+		reactionRepo: reactionRepo,
+		commentRepo:  commentRepo,
+		accessPolicy: accessPolicy,
+	}
+}
+
+type ToggleReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// isEmoji only accepts unicode symbols plus the joiners/modifiers used to build emoji sequences
+func isEmoji(s string) bool {
+	if s == "" || len(s) > 32 {
+		return false
+	}
+	hasSymbol := false
+	for _, r := range s {
+		switch {
+		case r == 0x200D || r == 0xFE0F || r == 0x20E3:
+			// zero width joiner, variation selector, keycap
+		case r >= 0x1F3FB && r <= 0x1F3FF:
+			// skin tone modifiers
+		case r >= 0xE0020 && r <= 0xE007F:
+			// tag sequences (subdivision flags)
+		case unicode.Is(unicode.So, r):
+			hasSymbol = true
+		default:
+			return false
+		}
+	}
+	return hasSymbol
+}
+
+func (s *ReactionService) ToggleOnComment(commentID, userID string, req *ToggleReactionRequest) ([]models.ReactionSummary, error) {
+	comment, err := s.commentRepo.FindByID(commentID)
+	if err != nil {
+		return nil, errors.New("comment not found")
+	}
+	if _, err := s.accessPolicy.LoadViewable(comment.TaskID, userID); err != nil {
+		return nil, errors.New("comment not found")
+	}
+	return s.toggle(models.ReactionTargetComment, comment.ID, userID, req.Emoji)
+}
+
+func (s *ReactionService) ToggleOnTask(taskID, userID string, req *ToggleReactionRequest) ([]models.ReactionSummary, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toggle(models.ReactionTargetTask, task.ID, userID, req.Emoji)
+}
+
+// AttachToComments fills in the reaction summaries of each comment for the given viewer
+func (s *ReactionService) AttachToComments(comments []models.Comment, viewerID string) error {
+	ids := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+
+	summaries, err := s.reactionRepo.SummarizeByTargets(models.ReactionTargetComment, ids, viewerID)
+	if err != nil {
+		return err
+	}
+	for i := range comments {
+		comments[i].Reactions = reactionsOrEmpty(summaries[comments[i].ID])
+	}
+	return nil
+}
+
+// AttachToTasks fills in the reaction summaries of each task and its preloaded comments
+func (s *ReactionService) AttachToTasks(tasks []models.Task, viewerID string) error {
+	taskIDs := make([]string, 0, len(tasks))
+	var comments []*models.Comment
+	var commentIDs []string
+	for i := range tasks {
+		taskIDs = append(taskIDs, tasks[i].ID)
+		for j := range tasks[i].Comments {
+			comments = append(comments, &tasks[i].Comments[j])
+			commentIDs = append(commentIDs, tasks[i].Comments[j].ID)
+		}
+	}
+
+	taskSummaries, err := s.reactionRepo.SummarizeByTargets(models.ReactionTargetTask, taskIDs, viewerID)
+	if err != nil {
+		return err
+	}
+	commentSummaries, err := s.reactionRepo.SummarizeByTargets(models.ReactionTargetComment, commentIDs, viewerID)
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		tasks[i].Reactions = reactionsOrEmpty(taskSummaries[tasks[i].ID])
+	}
+	for _, comment := range comments {
+		comment.Reactions = reactionsOrEmpty(commentSummaries[comment.ID])
+	}
+	return nil
+}
+
+func (s *ReactionService) toggle(targetType, targetID, userID, emoji string) ([]models.ReactionSummary, error) {
+	if !isEmoji(emoji) {
+		return nil, errors.New("reaction must be a single emoji")
+	}
+
+	// delete dulu, kalo gk ada yg kehapus baru insert. Dua request barengan gk bisa 500 lagi,
+	// insert yg kalah cuma no-op karena ON CONFLICT DO NOTHING
+	removed, err := s.reactionRepo.Remove(targetType, targetID, userID, emoji)
+	if err != nil {
+		return nil, errors.New("failed to toggle reaction")
+	}
+	if !removed {
+		reaction := &models.Reaction{
+			TargetType: targetType,
+			TargetID:   targetID,
+			UserID:     userID,
+			Emoji:      emoji,
+		}
+		if err := s.reactionRepo.Create(reaction); err != nil {
+			return nil, errors.New("failed to add reaction")
+		}
+	}
+
+	summaries, err := s.reactionRepo.SummarizeByTargets(targetType, []string{targetID}, userID)
+	if err != nil {
+		return nil, errors.New("failed to load reactions")
+	}
+	return reactionsOrEmpty(summaries[targetID]), nil
+}
+
+// biar json nya [] bukan null
+// deleteTaskReactions removes the reactions on the given tasks and on their comments. Dipanggil di
+// transaksi yg hapus task nya, sebelum task nya ke-soft delete biar subquery nya masih nemu
+func deleteTaskReactions(tx *gorm.DB, taskIDs interface{}) error {
+	if err := tx.Where("target_type = ? AND target_id IN (?)", models.ReactionTargetTask, taskIDs).Delete(&models.Reaction{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("target_type = ? AND target_id IN (?)", models.ReactionTargetComment,
+		tx.Model(&models.Comment{}).Select("id").Where("task_id IN (?)", taskIDs)).
+		Delete(&models.Reaction{}).Error
+}
+
+func reactionsOrEmpty(summaries []models.ReactionSummary) []models.ReactionSummary {
+	if summaries == nil {
+		return []models.ReactionSummary{}
+	}
+	return summaries
+}
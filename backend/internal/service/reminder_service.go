@@ -0,0 +1,401 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"minitask/internal/mailer"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxReminderMinutes caps reminder windows, offsets and snoozes (7 hari)
+	MaxReminderMinutes = 7 * 24 * 60
+	maxReminderWindows = 5
+	defaultSnooze      = 60
+)
+
+type ReminderService struct {
+	db                  *gorm.DB
+	taskRepo            repository.TaskRepository
+	userRepo            repository.UserRepository
+	reminderRepo        repository.ReminderRepository
+	accessPolicy        *TaskAccessPolicy
+	notificationService *NotificationService
+	watcherService      *WatcherService
+}
+
+func NewReminderService(
+	db *gorm.DB,
+	taskRepo repository.TaskRepository,
+	userRepo repository.UserRepository,
+	reminderRepo repository.ReminderRepository,
+	accessPolicy *TaskAccessPolicy,
+	notificationService *NotificationService,
+	watcherService *WatcherService,
+) *ReminderService {
+	return &ReminderService{
+		db:                  db,
+		taskRepo:            taskRepo,
+		userRepo:            userRepo,
+		reminderRepo:        reminderRepo,
+		accessPolicy:        accessPolicy,
+		notificationService: notificationService,
+		watcherService:      watcherService,
+	}
+}
+
+type ReminderSettings struct {
+	Windows models.MinuteList `json:"windows"` // minutes before the due date
+}
+
+// CreateReminderRequest takes either offsetMinutes (relative to the due date) or an absolute remindAt
+type CreateReminderRequest struct {
+	OffsetMinutes *int       `json:"offsetMinutes"`
+	RemindAt      *time.Time `json:"remindAt"`
+}
+
+type SnoozeRequest struct {
+	Minutes int `json:"minutes"` // default 60
+}
+
+// NotifyDueWindows reminds task watchers when a due date enters one of their reminder windows.
+// Only the closest window that already passed fires, so a task created an hour before it's due
+// doesn't also trigger the "1 day before" reminder. Dedupe key includes the due date so moving
+// the deadline gives a fresh reminder
+func (s *ReminderService) NotifyDueWindows(now time.Time) error {
+	tasks, err := s.taskRepo.FindDueBetween(now, now.Add(MaxReminderMinutes*time.Minute))
+	if err != nil {
+		return err
+	}
+
+	windows := map[string]models.MinuteList{}
+	for i := range tasks {
+		task := &tasks[i]
+		remaining := task.DueDate.Sub(now)
+		for _, userID := range s.watcherService.Recipients(task) {
+			if _, ok := windows[userID]; !ok {
+				windows[userID] = s.windowsFor(userID)
+			}
+			window, ok := closestWindow(windows[userID], remaining)
+			if !ok {
+				continue
+			}
+			s.notificationService.Notify(NotifyInput{
+				UserID:      userID,
+				Type:        models.NotificationTaskDueSoon,
+				WorkspaceID: task.WorkspaceID,
+				TaskID:      &task.ID,
+				Subject:     task.Title,
+				Excerpt:     dueExcerpt(task),
+				DedupeKey:   fmt.Sprintf("due_soon:%s:%d:%d", task.ID, task.DueDate.Unix(), window),
+			})
+		}
+	}
+	return nil
+}
+
+// closestWindow picks the smallest window (in minutes) that is at least `remaining` away from the due date
+func closestWindow(windows models.MinuteList, remaining time.Duration) (int, bool) {
+	best, found := 0, false
+	for _, window := range windows {
+		if time.Duration(window)*time.Minute < remaining {
+			continue
+		}
+		if !found || window < best {
+			best, found = window, true
+		}
+	}
+	return best, found
+}
+
+func (s *ReminderService) windowsFor(userID string) models.MinuteList {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user.ReminderWindows == nil {
+		return models.DefaultReminderWindows
+	}
+	return user.ReminderWindows
+}
+
+func dueExcerpt(task *models.Task) string {
+	if task.DueDate == nil {
+		return "Reminder"
+	}
+	return "Due " + task.DueDate.UTC().Format("Mon, 2 Jan 2006 15:04 MST")
+}
+
+// SendTaskReminders fires per-task reminders (custom and snoozed) that are due
+func (s *ReminderService) SendTaskReminders(now time.Time) error {
+	reminders, err := s.reminderRepo.FindDue(now)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		task, err := s.taskRepo.FindByID(reminder.TaskID)
+		// task udah dihapus / selesai / user udah gk punya akses -> anggep udah kekirim
+		if err == nil && task.Status != models.StatusDone && s.accessPolicy.CanView(task, reminder.UserID) {
+			s.notificationService.Notify(NotifyInput{
+				UserID:      reminder.UserID,
+				Type:        models.NotificationTaskDueSoon,
+				WorkspaceID: task.WorkspaceID,
+				TaskID:      &task.ID,
+				Subject:     task.Title,
+				Excerpt:     dueExcerpt(task),
+				DedupeKey:   fmt.Sprintf("reminder:%s:%d", reminder.ID, reminder.RemindAt.Unix()),
+			})
+		}
+
+		// one-off (snoozed) reminders are done after firing, relative ones wait for a new due date
+		if reminder.OffsetMinutes == nil {
+			err = s.reminderRepo.Delete(reminder.ID)
+		} else {
+			err = s.reminderRepo.MarkSent(reminder.ID, now)
+		}
+		if err != nil {
+			log.Printf("[ReminderService] failed to close reminder %s: %v", reminder.ID, err)
+		}
+	}
+	return nil
+}
+
+// Reschedule moves the relative reminders of a task after its due date changed
+func (s *ReminderService) Reschedule(task *models.Task) {
+	reminders, err := s.reminderRepo.FindRelativeByTaskID(task.ID)
+	if err != nil {
+		log.Printf("[ReminderService] failed to load reminders of task %s: %v", task.ID, err)
+		return
+	}
+	for i := range reminders {
+		reminders[i].Schedule(task.DueDate)
+		if err := s.reminderRepo.Update(&reminders[i]); err != nil {
+			log.Printf("[ReminderService] failed to reschedule reminder %s: %v", reminders[i].ID, err)
+		}
+	}
+}
+
+func (s *ReminderService) GetSettings(userID string) (*ReminderSettings, error) {
+	return &ReminderSettings{Windows: s.windowsFor(userID)}, nil
+}
+
+func (s *ReminderService) UpdateSettings(userID string, req *ReminderSettings) (*ReminderSettings, error) {
+	if len(req.Windows) > maxReminderWindows {
+		return nil, fmt.Errorf("at most %d reminder windows allowed", maxReminderWindows)
+	}
+	seen := map[int]bool{}
+	windows := models.MinuteList{}
+	for _, window := range req.Windows {
+		if window < 1 || window > MaxReminderMinutes {
+			return nil, fmt.Errorf("reminder windows must be between 1 and %d minutes", MaxReminderMinutes)
+		}
+		if !seen[window] {
+			seen[window] = true
+			windows = append(windows, window)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(windows)))
+
+	// list kosong = matiin reminder otomatis
+	err := s.db.Model(&models.User{}).Where("id = ?", userID).Update("reminder_windows", windows).Error
+	if err != nil {
+		return nil, errors.New("failed to save reminder settings")
+	}
+	return &ReminderSettings{Windows: windows}, nil
+}
+
+func (s *ReminderService) GetForTask(taskID, userID string) ([]models.TaskReminder, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	reminders, err := s.reminderRepo.FindByTaskAndUser(task.ID, userID)
+	if err != nil {
+		return nil, errors.New("failed to load reminders")
+	}
+	if reminders == nil {
+		reminders = []models.TaskReminder{}
+	}
+	return reminders, nil
+}
+
+func (s *ReminderService) Create(taskID, userID string, req *CreateReminderRequest) (*models.TaskReminder, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder := &models.TaskReminder{TaskID: task.ID, UserID: userID}
+	switch {
+	case req.OffsetMinutes != nil && req.RemindAt != nil:
+		return nil, errors.New("use either offsetMinutes or remindAt, not both")
+	case req.OffsetMinutes != nil:
+		if *req.OffsetMinutes < 0 || *req.OffsetMinutes > MaxReminderMinutes {
+			return nil, fmt.Errorf("offsetMinutes must be between 0 and %d", MaxReminderMinutes)
+		}
+		reminder.OffsetMinutes = req.OffsetMinutes
+		reminder.Schedule(task.DueDate)
+	case req.RemindAt != nil:
+		if req.RemindAt.Before(time.Now()) {
+			return nil, errors.New("remindAt must be in the future")
+		}
+		reminder.RemindAt = req.RemindAt
+	default:
+		return nil, errors.New("offsetMinutes or remindAt is required")
+	}
+
+	if err := s.reminderRepo.Create(reminder); err != nil {
+		return nil, errors.New("failed to create reminder")
+	}
+	return reminder, nil
+}
+
+func (s *ReminderService) Delete(id, userID string) error {
+	reminder, err := s.reminderRepo.FindByID(id, userID)
+	if err != nil {
+		return errors.New("reminder not found")
+	}
+	if err := s.reminderRepo.Delete(reminder.ID); err != nil {
+		return errors.New("failed to delete reminder")
+	}
+	return nil
+}
+
+// Snooze pushes a reminder back by req.Minutes from now, also re-arms one that already went off
+func (s *ReminderService) Snooze(id, userID string, req *SnoozeRequest) (*models.TaskReminder, error) {
+	until, err := snoozeUntil(req)
+	if err != nil {
+		return nil, err
+	}
+	reminder, err := s.reminderRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, errors.New("reminder not found")
+	}
+
+	reminder.RemindAt = &until
+	reminder.SentAt = nil
+	if err := s.reminderRepo.Update(reminder); err != nil {
+		return nil, errors.New("failed to snooze reminder")
+	}
+	return reminder, nil
+}
+
+// SnoozeNotification turns a task notification into a one-off reminder later and marks it read
+func (s *ReminderService) SnoozeNotification(notificationID, userID string, req *SnoozeRequest) (*models.TaskReminder, error) {
+	until, err := snoozeUntil(req)
+	if err != nil {
+		return nil, err
+	}
+	notification, err := s.notificationService.GetByID(notificationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if notification.TaskID == nil {
+		return nil, errors.New("only task notifications can be snoozed")
+	}
+	if _, err := s.accessPolicy.LoadViewable(*notification.TaskID, userID); err != nil {
+		return nil, err
+	}
+
+	reminder := &models.TaskReminder{TaskID: *notification.TaskID, UserID: userID, RemindAt: &until}
+	if err := s.reminderRepo.Create(reminder); err != nil {
+		return nil, errors.New("failed to snooze notification")
+	}
+	if err := s.notificationService.MarkRead(notification.ID, userID); err != nil {
+		log.Printf("[ReminderService] failed to mark snoozed notification %s read: %v", notification.ID, err)
+	}
+	return reminder, nil
+}
+
+func snoozeUntil(req *SnoozeRequest) (time.Time, error) {
+	minutes := req.Minutes
+	if minutes == 0 {
+		minutes = defaultSnooze
+	}
+	if minutes < 1 || minutes > MaxReminderMinutes {
+		return time.Time{}, fmt.Errorf("snooze must be between 1 and %d minutes", MaxReminderMinutes)
+	}
+	return time.Now().Add(time.Duration(minutes) * time.Minute), nil
+}
+
+// DigestHour is the hour (server time) after which the daily digest goes out, DIGEST_HOUR, default 8
+func DigestHour() int {
+	hour, err := strconv.Atoi(os.Getenv("DIGEST_HOUR"))
+	if err != nil || hour < 0 || hour > 23 {
+		return 8
+	}
+	return hour
+}
+
+// SendDailyDigests emails every opted-in user their open and overdue tasks, once per day.
+// Dipanggil tiap jam, sebelum DigestHour gk ngapa2in
+func (s *ReminderService) SendDailyDigests(now time.Time) error {
+	if now.Hour() < DigestHour() {
+		return nil
+	}
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	users, err := s.userRepo.FindDigestRecipients(startOfDay)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if err := s.sendDigest(&user, now); err != nil {
+			log.Printf("[ReminderService] failed to send digest to %s: %v", user.ID, err)
+			continue
+		}
+		if err := s.userRepo.SetLastDigestAt(user.ID, now); err != nil {
+			log.Printf("[ReminderService] failed to mark digest sent for %s: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *ReminderService) sendDigest(user *models.User, now time.Time) error {
+	tasks, err := s.taskRepo.FindOpenForUser(user.ID)
+	if err != nil {
+		return err
+	}
+	// gk ada task = gk usah kirim email kosong
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	digest := mailer.DigestEmail{
+		Recipient: user.Username,
+		Date:      now.Format("Mon, 2 Jan 2006"),
+		Link:      frontendURL() + "/dashboard",
+	}
+	for _, task := range tasks {
+		item := mailer.DigestTask{
+			Title:  task.Title,
+			Status: task.Status,
+			Link:   TaskLink(task.WorkspaceID, task.ID),
+		}
+		if task.Workspace != nil {
+			item.Workspace = task.Workspace.Name
+		}
+		if task.DueDate != nil {
+			item.DueDate = task.DueDate.Format("2 Jan 2006")
+		}
+		if task.DueDate != nil && task.DueDate.Before(now) {
+			digest.Overdue = append(digest.Overdue, item)
+		} else {
+			digest.Open = append(digest.Open, item)
+		}
+	}
+
+	msg, err := mailer.Render(mailer.TemplateDailyDigest, user.Email, digest)
+	if err != nil {
+		return err
+	}
+	s.notificationService.SendAsync(msg)
+	return nil
+}
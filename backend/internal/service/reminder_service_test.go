@@ -0,0 +1,363 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"minitask/internal/mailer"
+	"minitask/internal/mailer/mailertest"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+)
+
+// digestUserRepo / digestTaskRepo cuma implement method yg dipake SendDailyDigests,
+// sisanya nil interface (panic kalo kepanggil)
+type digestUserRepo struct {
+	repository.UserRepository
+	recipients []models.User
+	sentBefore time.Time
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+func (r *digestUserRepo) FindDigestRecipients(sentBefore time.Time) ([]models.User, error) {
+	r.sentBefore = sentBefore
+	return r.recipients, nil
+}
+
+func (r *digestUserRepo) SetLastDigestAt(id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent[id] = at
+	return nil
+}
+
+type digestTaskRepo struct {
+	repository.TaskRepository
+	open map[string][]models.Task
+	fail map[string]bool
+}
+
+func (r *digestTaskRepo) FindOpenForUser(userID string) ([]models.Task, error) {
+	if r.fail[userID] {
+		return nil, errors.New("db down")
+	}
+	return r.open[userID], nil
+}
+
+func TestSendDailyDigests(t *testing.T) {
+	t.Setenv("DIGEST_HOUR", "8")
+	t.Setenv("FRONTEND_URL", "https://app.test/")
+
+	server := mailertest.NewServer(t)
+	mail := &mailer.SMTPMailer{Addr: server.Addr, From: "MiniTask <no-reply@minitask.test>"}
+
+	now := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	nextWeek := now.AddDate(0, 0, 7)
+	workspaceID := "ws-1"
+
+	users := &digestUserRepo{
+		recipients: []models.User{
+			{ID: "alice", Username: "alice", Email: "alice@example.com"},
+			{ID: "bob", Username: "bob", Email: "bob@example.com"},
+			{ID: "carol", Username: "carol", Email: "carol@example.com"},
+		},
+		sent: map[string]time.Time{},
+	}
+	tasks := &digestTaskRepo{
+		open: map[string][]models.Task{
+			"alice": {
+				{ID: "t1", Title: "Pay invoice", Status: models.StatusNotStarted, DueDate: &yesterday},
+				{ID: "t2", Title: "Write spec", Status: models.StatusInProgress, DueDate: &nextWeek, WorkspaceID: &workspaceID, Workspace: &models.Workspace{Name: "Acme"}},
+			},
+		},
+		fail: map[string]bool{"carol": true},
+	}
+	notifications := NewNotificationService(nil, nil, users, mail)
+	reminders := NewReminderService(nil, tasks, users, nil, nil, notifications, nil)
+
+	if err := reminders.SendDailyDigests(now); err != nil {
+		t.Fatalf("SendDailyDigests() error = %v", err)
+	}
+	if want := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC); !users.sentBefore.Equal(want) {
+		t.Errorf("recipients queried with sentBefore = %v, want %v", users.sentBefore, want)
+	}
+
+	env := server.Next(t, 2*time.Second)
+	if len(env.To) != 1 || env.To[0] != "alice@example.com" {
+		t.Fatalf("digest sent to %v, want alice", env.To)
+	}
+	data := strings.ReplaceAll(env.Data, "=\r\n", "")
+	for _, want := range []string{"Pay invoice", "Write spec", "[Acme]", "https://app.test/tasks/t1", "https://app.test/workspace/ws-1?task=t2"} {
+		if !strings.Contains(data, want) {
+			t.Errorf("digest is missing %q", want)
+		}
+	}
+	overdue := strings.Index(data, "Overdue")
+	if overdue < 0 || strings.Index(data, "Pay invoice") < overdue {
+		t.Error("Pay invoice should be listed under Overdue")
+	}
+
+	// bob gk punya task: gk dapet email tapi tetep ditandai, carol gagal: gk ditandai biar dicoba lagi
+	notifications.Wait()
+	if n := server.Pending(); n != 0 {
+		t.Errorf("%d extra digests sent, want only alice's", n)
+	}
+	users.mu.Lock()
+	defer users.mu.Unlock()
+	if _, ok := users.sent["alice"]; !ok {
+		t.Error("alice's digest not marked as sent")
+	}
+	if _, ok := users.sent["bob"]; !ok {
+		t.Error("bob should be marked even without tasks")
+	}
+	if _, ok := users.sent["carol"]; ok {
+		t.Error("carol's failed digest shouldn't be marked as sent")
+	}
+}
+
+func TestSendDailyDigestsBeforeDigestHour(t *testing.T) {
+	t.Setenv("DIGEST_HOUR", "8")
+	users := &digestUserRepo{sent: map[string]time.Time{}}
+	reminders := NewReminderService(nil, &digestTaskRepo{}, users, nil, nil, nil, nil)
+
+	if err := reminders.SendDailyDigests(time.Date(2026, 1, 5, 7, 59, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SendDailyDigests() error = %v", err)
+	}
+	if !users.sentBefore.IsZero() {
+		t.Error("recipients shouldn't be queried before the digest hour")
+	}
+}
+
+// fakes buat reminder scheduler, sama kayak di atas cuma method yg kepake yg di-implement
+type reminderTaskRepo struct {
+	repository.TaskRepository
+	tasks map[string]*models.Task
+}
+
+func (r *reminderTaskRepo) FindByID(id string) (*models.Task, error) {
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return task, nil
+}
+
+func (r *reminderTaskRepo) FindDueBetween(from, to time.Time) ([]models.Task, error) {
+	var due []models.Task
+	for _, task := range r.tasks {
+		if task.DueDate != nil && !task.DueDate.Before(from) && task.DueDate.Before(to) && task.Status != models.StatusDone {
+			due = append(due, *task)
+		}
+	}
+	return due, nil
+}
+
+type reminderUserRepo struct {
+	repository.UserRepository
+	windows map[string]models.MinuteList
+}
+
+func (r *reminderUserRepo) FindByID(id string) (*models.User, error) {
+	return &models.User{ID: id, ReminderWindows: r.windows[id]}, nil
+}
+
+type memberRepo struct {
+	repository.WorkspaceRepository
+	members map[string]bool // workspaceID + "/" + userID
+}
+
+func (r *memberRepo) IsMember(workspaceID, userID string) (bool, error) {
+	return r.members[workspaceID+"/"+userID], nil
+}
+
+type watcherRepo struct {
+	repository.TaskWatcherRepository
+	watchers map[string][]string
+}
+
+func (r *watcherRepo) FindUserIDsByTaskID(taskID string) ([]string, error) {
+	return r.watchers[taskID], nil
+}
+
+// inboxRepo keeps notifications in memory, email dimatiin lewat preference biar gk ada SMTP
+type inboxRepo struct {
+	repository.NotificationRepository
+	created []models.Notification
+}
+
+func (r *inboxRepo) FindPreference(userID, notificationType string) (*models.NotificationPreference, error) {
+	email := false
+	return &models.NotificationPreference{UserID: userID, Type: notificationType, InApp: true, Email: &email}, nil
+}
+
+func (r *inboxRepo) Create(notification *models.Notification) (bool, error) {
+	for _, n := range r.created {
+		if n.UserID == notification.UserID && n.DedupeKey != nil && notification.DedupeKey != nil && *n.DedupeKey == *notification.DedupeKey {
+			return false, nil
+		}
+	}
+	r.created = append(r.created, *notification)
+	return true, nil
+}
+
+func (r *inboxRepo) recipients() []string {
+	var users []string
+	for _, n := range r.created {
+		users = append(users, n.UserID)
+	}
+	return users
+}
+
+type fakeReminderRepo struct {
+	repository.ReminderRepository
+	due     []models.TaskReminder
+	deleted []string
+	sent    []string
+}
+
+func (r *fakeReminderRepo) FindDue(now time.Time) ([]models.TaskReminder, error) {
+	return r.due, nil
+}
+
+func (r *fakeReminderRepo) Delete(id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *fakeReminderRepo) MarkSent(id string, at time.Time) error {
+	r.sent = append(r.sent, id)
+	return nil
+}
+
+func TestClosestWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		windows   models.MinuteList
+		remaining time.Duration
+		want      int
+		wantOK    bool
+	}{
+		{"inside the smallest window", models.MinuteList{1440, 120, 30}, 20 * time.Minute, 30, true},
+		{"between windows", models.MinuteList{1440, 120, 30}, 90 * time.Minute, 120, true},
+		{"exactly on a window", models.MinuteList{1440, 120}, 120 * time.Minute, 120, true},
+		{"not in any window yet", models.MinuteList{120, 30}, 3 * time.Hour, 0, false},
+		{"reminders turned off", models.MinuteList{}, time.Minute, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := closestWindow(tt.windows, tt.remaining)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("closestWindow() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNotifyDueWindows(t *testing.T) {
+	now := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	due := now.Add(90 * time.Minute)
+	workspaceID := "ws-1"
+
+	tasks := &reminderTaskRepo{tasks: map[string]*models.Task{
+		"t1": {ID: "t1", Title: "Ship release", Status: models.StatusInProgress, DueDate: &due, WorkspaceID: &workspaceID},
+	}}
+	users := &reminderUserRepo{windows: map[string]models.MinuteList{
+		"alice": {1440, 120, 30},
+		"dave":  {30},
+	}}
+	members := &memberRepo{members: map[string]bool{"ws-1/alice": true, "ws-1/bob": true, "ws-1/dave": true}}
+	inbox := &inboxRepo{}
+
+	access := NewTaskAccessPolicy(tasks, members)
+	notifications := NewNotificationService(nil, inbox, users, nil)
+	// carol masih watch tapi udah keluar workspace
+	watchers := NewWatcherService(nil, &watcherRepo{watchers: map[string][]string{"t1": {"alice", "bob", "carol", "dave"}}}, access, notifications)
+	reminders := NewReminderService(nil, tasks, users, nil, access, notifications, watchers)
+
+	for i := 0; i < 2; i++ {
+		if err := reminders.NotifyDueWindows(now); err != nil {
+			t.Fatalf("NotifyDueWindows() error = %v", err)
+		}
+	}
+
+	// alice: window 120 menit, bob: default 1 hari, dave: 30 menit belum masuk
+	got := inbox.recipients()
+	if strings.Join(got, ",") != "alice,bob" {
+		t.Fatalf("notified %v, want [alice bob] once each", got)
+	}
+	if key := *inbox.created[0].DedupeKey; key != fmt.Sprintf("due_soon:t1:%d:120", due.Unix()) {
+		t.Errorf("alice's dedupe key = %q", key)
+	}
+}
+
+func TestSendTaskReminders(t *testing.T) {
+	now := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	offset := 120
+	tasks := &reminderTaskRepo{tasks: map[string]*models.Task{
+		"open": {ID: "open", Title: "Open task", UserID: "alice", Status: models.StatusNotStarted},
+		"done": {ID: "done", Title: "Done task", UserID: "alice", Status: models.StatusDone},
+	}}
+	repo := &fakeReminderRepo{due: []models.TaskReminder{
+		{ID: "snoozed", TaskID: "open", UserID: "alice", RemindAt: &now},
+		{ID: "relative", TaskID: "open", UserID: "alice", OffsetMinutes: &offset, RemindAt: &now},
+		{ID: "finished", TaskID: "done", UserID: "alice", RemindAt: &now},
+		{ID: "deleted", TaskID: "gone", UserID: "alice", RemindAt: &now},
+		{ID: "foreign", TaskID: "open", UserID: "mallory", RemindAt: &now},
+	}}
+	inbox := &inboxRepo{}
+	access := NewTaskAccessPolicy(tasks, &memberRepo{})
+	notifications := NewNotificationService(nil, inbox, &reminderUserRepo{}, nil)
+	reminders := NewReminderService(nil, tasks, &reminderUserRepo{}, repo, access, notifications, nil)
+
+	if err := reminders.SendTaskReminders(now); err != nil {
+		t.Fatalf("SendTaskReminders() error = %v", err)
+	}
+
+	if len(inbox.created) != 2 {
+		t.Fatalf("%d notifications, want 2 (snoozed + relative on the open task)", len(inbox.created))
+	}
+	for _, n := range inbox.created {
+		if n.UserID != "alice" || *n.TaskID != "open" {
+			t.Errorf("unexpected notification %+v", n)
+		}
+	}
+	// one-off reminders dihapus, yg relatif cuma ditandai biar bisa bunyi lagi kalo due date nya pindah
+	if got := strings.Join(repo.deleted, ","); got != "snoozed,finished,deleted,foreign" {
+		t.Errorf("deleted = %s", got)
+	}
+	if got := strings.Join(repo.sent, ","); got != "relative" {
+		t.Errorf("marked sent = %s", got)
+	}
+}
+
+func TestSnoozeUntil(t *testing.T) {
+	tests := []struct {
+		minutes int
+		want    time.Duration
+		wantErr bool
+	}{
+		{0, time.Hour, false},
+		{15, 15 * time.Minute, false},
+		{MaxReminderMinutes, MaxReminderMinutes * time.Minute, false},
+		{-5, 0, true},
+		{MaxReminderMinutes + 1, 0, true},
+	}
+	for _, tt := range tests {
+		before := time.Now()
+		until, err := snoozeUntil(&SnoozeRequest{Minutes: tt.minutes})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("snoozeUntil(%d) error = %v, wantErr %v", tt.minutes, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (until.Before(before.Add(tt.want)) || until.After(time.Now().Add(tt.want))) {
+			t.Errorf("snoozeUntil(%d) = %v, want now + %s", tt.minutes, until, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"minitask/internal/markdown"
+	"minitask/internal/models"
+)
+
+// render* ngisi field HTML hasil markdown pas mau dikirim ke client, bukan di hook GORM
+// biar query yg cuma butuh data mentah gk ikut nge-render
+
+func renderTask(task *models.Task) {
+	task.DescriptionHTML = markdown.Render(task.Description)
+	renderComments(task.Comments)
+}
+
+func renderTasks(tasks []models.Task) {
+	for i := range tasks {
+		renderTask(&tasks[i])
+	}
+}
+
+func renderComment(comment *models.Comment) {
+	comment.ContentHTML = markdown.Render(comment.Content)
+}
+
+func renderComments(comments []models.Comment) {
+	for i := range comments {
+		renderComment(&comments[i])
+	}
+}
+
+func renderRevisions(revisions []models.CommentRevision) {
+	for i := range revisions {
+		revisions[i].ContentHTML = markdown.Render(revisions[i].Content)
+	}
+}
+
+func renderMentions(mentions []models.Mention) {
+	for i := range mentions {
+		if mentions[i].Task != nil {
+			renderTask(mentions[i].Task)
+		}
+		if mentions[i].Comment != nil {
+			renderComment(mentions[i].Comment)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionService struct {
+	db          *gorm.DB
+	sessionRepo repository.SessionRepository
+}
+
+func NewSessionService(db *gorm.DB, sessionRepo repository.SessionRepository) *SessionService {
+	return &SessionService{db: db, sessionRepo: sessionRepo}
+}
+
+// GetAll lists the user's active sessions, yg lagi dipake ditandai Current
+func (s *SessionService) GetAll(userID, currentID string) ([]models.Session, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID, time.Now())
+	if err != nil {
+		return nil, errors.New("failed to load sessions")
+	}
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+	for i := range sessions {
+		sessions[i].Device = describeUserAgent(sessions[i].UserAgent)
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// Revoke signs one session out, access token nya langsung ditolak middleware
+func (s *SessionService) Revoke(id, userID string) error {
+	if err := s.sessionRepo.Revoke(id, userID, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("session not found")
+		}
+		return errors.New("failed to revoke session")
+	}
+	return nil
+}
+
+// RevokeOthers is "sign out everywhere else", sesi yg lagi dipake tetep jalan
+func (s *SessionService) RevokeOthers(userID, currentID string) error {
+	if err := s.sessionRepo.RevokeAllForUser(userID, currentID, time.Now()); err != nil {
+		return errors.New("failed to revoke sessions")
+	}
+	return nil
+}
+
+// describeUserAgent turns a user agent into "Browser on OS", cukup buat dikenalin user nya aja
+func describeUserAgent(ua string) string {
+	if ua == "" {
+		return "Unknown device"
+	}
+	browser := "Unknown browser"
+	for _, candidate := range []struct{ token, name string }{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"}, {"Chrome/", "Chrome"},
+		{"Safari/", "Safari"}, {"curl/", "curl"}, {"PostmanRuntime/", "Postman"},
+	} {
+		if strings.Contains(ua, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+	platform := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"Android", "Android"}, {"iPhone", "iOS"}, {"iPad", "iPadOS"}, {"Windows", "Windows"},
+		{"Mac OS X", "macOS"}, {"CrOS", "ChromeOS"}, {"Linux", "Linux"},
+	} {
+		if strings.Contains(ua, candidate.token) {
+			platform = candidate.name
+			break
+		}
+	}
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
+
+func truncate(value string, max int) string {
+	if runes := []rune(value); len(runes) > max {
+		return string(runes[:max])
+	}
+	return value
+}
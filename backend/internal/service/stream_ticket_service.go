@@ -0,0 +1,86 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"minitask/internal/middleware"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	streamTicketPrefix = "mt_st_"
+	// StreamTicketTTL cukup buat client langsung buka EventSource abis minta ticket
+	StreamTicketTTL = 30 * time.Second
+)
+
+var ErrInvalidStreamTicket = errors.New("invalid or expired stream ticket")
+
+type StreamTicketService struct {
+	db         *gorm.DB
+	ticketRepo repository.StreamTicketRepository
+}
+
+func NewStreamTicketService(db *gorm.DB, ticketRepo repository.StreamTicketRepository) *StreamTicketService {
+	return &StreamTicketService{db: db, ticketRepo: ticketRepo}
+}
+
+type StreamTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expiresIn"` // detik
+}
+
+// Issue hands out a single-use ticket for GET /events?ticket=, tied to the caller's access token
+func (s *StreamTicketService) Issue(claims *middleware.JWTClaims) (*StreamTicketResponse, error) {
+	if claims == nil || claims.ExpiresAt == nil {
+		return nil, errors.New("failed to create stream ticket")
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.New("failed to create stream ticket")
+	}
+	token := streamTicketPrefix + hex.EncodeToString(b)
+
+	ticket := &models.StreamTicket{
+		UserID:          claims.UserID,
+		Username:        claims.Username,
+		SessionID:       claims.SessionID,
+		TokenHash:       hashToken(token),
+		ExpiresAt:       time.Now().Add(StreamTicketTTL),
+		AccessExpiresAt: claims.ExpiresAt.Time,
+	}
+	if err := s.ticketRepo.Create(ticket); err != nil {
+		return nil, errors.New("failed to create stream ticket")
+	}
+	return &StreamTicketResponse{Ticket: token, ExpiresIn: int(StreamTicketTTL.Seconds())}, nil
+}
+
+// Redeem burns the ticket and returns the claims of the access token it was issued with
+func (s *StreamTicketService) Redeem(token string) (*middleware.JWTClaims, error) {
+	if !strings.HasPrefix(token, streamTicketPrefix) {
+		return nil, ErrInvalidStreamTicket
+	}
+	ticket, err := s.ticketRepo.Consume(hashToken(token), time.Now())
+	if err != nil {
+		return nil, ErrInvalidStreamTicket
+	}
+	return &middleware.JWTClaims{
+		UserID:    ticket.UserID,
+		Username:  ticket.Username,
+		SessionID: ticket.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(ticket.AccessExpiresAt),
+		},
+	}, nil
+}
+
+// PurgeExpired removes tickets nobody redeemed, dijalanin scheduler
+func (s *StreamTicketService) PurgeExpired() error {
+	return s.ticketRepo.DeleteExpired(time.Now())
+}
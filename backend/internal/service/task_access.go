@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrTaskAccessDenied = errors.New("task not found or access denied")
+
+// taskKeyPattern sama kayak yg di-parse gitlink, "ws-42" juga diterima
+var taskKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{1,9}-[0-9]+$`)
+
+// TaskAccessPolicy is the single place that decides who can see a task (and everything hanging off it,
+// like comments and reactions) and who can moderate it.
+//   - personal task: only the creator
+//   - workspace task: any workspace member can view, only the workspace owner can moderate
+type TaskAccessPolicy struct {
+	taskRepo      repository.TaskRepository
+	workspaceRepo repository.WorkspaceRepository
+}
+
+func NewTaskAccessPolicy(taskRepo repository.TaskRepository, workspaceRepo repository.WorkspaceRepository) *TaskAccessPolicy {
+	return &TaskAccessPolicy{taskRepo: taskRepo, workspaceRepo: workspaceRepo}
+}
+
+func (p *TaskAccessPolicy) CanView(task *models.Task, userID string) bool {
+	if task.WorkspaceID == nil {
+		return task.UserID == userID
+	}
+	isMember, err := p.workspaceRepo.IsMember(*task.WorkspaceID, userID)
+	return err == nil && isMember
+}
+
+func (p *TaskAccessPolicy) CanModerate(task *models.Task, userID string) bool {
+	if task.WorkspaceID == nil {
+		return task.UserID == userID
+	}
+	member, err := p.workspaceRepo.FindMember(*task.WorkspaceID, userID)
+	return err == nil && member.Role == models.RoleOwner
+}
+
+// LoadViewable fetches a task by UUID or key ("WS-42") and returns ErrTaskAccessDenied both when it
+// doesn't exist and when the user may not see it, so task IDs can't be probed. Key dicari di workspace
+// yg user nya masih member; caller harus pake task.ID yg dibalikin, bukan ref nya
+func (p *TaskAccessPolicy) LoadViewable(ref, userID string) (*models.Task, error) {
+	if _, err := uuid.Parse(ref); err != nil {
+		return p.loadByKey(ref, userID)
+	}
+	task, err := p.taskRepo.FindByID(ref)
+	if err != nil || !p.CanView(task, userID) {
+		return nil, ErrTaskAccessDenied
+	}
+	return task, nil
+}
+
+func (p *TaskAccessPolicy) loadByKey(key, userID string) (*models.Task, error) {
+	if !taskKeyPattern.MatchString(key) {
+		return nil, ErrTaskAccessDenied
+	}
+	tasks, err := p.taskRepo.FindByKeyForMember(strings.ToUpper(key), userID, 2)
+	if err != nil || len(tasks) == 0 || !p.CanView(&tasks[0], userID) {
+		return nil, ErrTaskAccessDenied
+	}
+	// dua workspace dengan prefix yg sama: gk nebak, suruh pake UUID
+	if len(tasks) > 1 {
+		return nil, fmt.Errorf("%w: key %s matches tasks in more than one workspace, use the task id", ErrTaskAccessDenied, strings.ToUpper(key))
+	}
+	return &tasks[0], nil
+}
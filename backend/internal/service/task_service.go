@@ -2,7 +2,9 @@ package service
 
 import (
 	"errors"
+	"log"
 	"sync"
+	"time"
 
 	"minitask/internal/models"
 	"minitask/internal/repository"
@@ -11,15 +13,49 @@ import (
 )
 
 type TaskService struct {
-	db       *gorm.DB
-	taskRepo repository.TaskRepository
+	db              *gorm.DB
+	taskRepo        repository.TaskRepository
+	mentionService  *MentionService
+	reactionService *ReactionService
+	activityService *ActivityService
+	watcherService  *WatcherService
+	reminderService *ReminderService
 }
 
-func NewTaskService(db *gorm.DB, taskRepo repository.TaskRepository) *TaskService { // bikin instance task services baru
-	return &TaskService{db: db, taskRepo: taskRepo}
+func NewTaskService(
+	db *gorm.DB,
+	taskRepo repository.TaskRepository,
+	mentionService *MentionService,
+	reactionService *ReactionService,
+	activityService *ActivityService,
+	watcherService *WatcherService,
+	reminderService *ReminderService,
+) *TaskService { // bikin instance task services baru
+	return &TaskService{
+		db:              db,
+		taskRepo:        taskRepo,
+		mentionService:  mentionService,
+		reactionService: reactionService,
+		activityService: activityService,
+		watcherService:  watcherService,
+		reminderService: reminderService,
+	}
 }
 
 func (s *TaskService) Create(task *models.Task) error {
+	if err := s.prepare(task); err != nil {
+		return err
+	}
+	if err := s.taskRepo.Create(task); err != nil {
+		return err
+	}
+	s.afterCreate(task)
+	renderTask(task)
+	return nil
+}
+
+// prepare validates & fills defaults of a new personal task, dipake juga sama import
+func (s *TaskService) prepare(task *models.Task) error {
 	if task.Title == "" {
 		return errors.New("Title cannot be empty")
 	}
@@ -29,7 +65,32 @@ func (s *TaskService) Create(task *models.Task) error {
 	if task.Status == "" {
 		task.Status = models.StatusNotStarted // ini auto jadi kalo misal bikin task baru, pasti masuk ke notstarted
 	}
-	return s.taskRepo.Create(task)
+	labels, err := NormalizeLabels(task.Labels)
+	if err != nil {
+		return err
+	}
+	task.Labels = labels
+	return nil
+}
+
+func (s *TaskService) afterCreate(task *models.Task) {
+	s.syncDescriptionMentions(task, task.UserID)
+	s.watcherService.AutoWatch(task.ID, task.UserID)
+	s.activityService.Record(ActivityEntry{
+		ActorID:     task.UserID,
+		EntityType:  models.ActivityEntityTask,
+		EntityID:    task.ID,
+		Action:      models.ActivityCreated,
+		WorkspaceID: task.WorkspaceID,
+		TaskID:      &task.ID,
+		After:       TaskSnapshot(task),
+	})
+}
+
+func (s *TaskService) syncDescriptionMentions(task *models.Task, authorID string) {
+	if _, err := s.mentionService.SyncTaskMentions(task, authorID); err != nil {
+		log.Printf("[TaskService] failed to save mentions for task %s: %v", task.ID, err)
+	}
 }
 
 // getByID ini buat ambil task berdasarkan ID
@@ -38,12 +99,28 @@ func (s *TaskService) GetByID(id string, userID string) (*models.Task, error) {
 	if err != nil {
 		return nil, errors.New("task not found!")
 	}
-	return task, nil
+
+	tasks := []models.Task{*task}
+	s.attachReactions(tasks, userID)
+	renderTasks(tasks)
+	return &tasks[0], nil
 }
 
 // GetAllByUserID mengambil semua task milik user
 func (s *TaskService) GetAllByUserID(userID string) ([]models.Task, error) {
-	return s.taskRepo.FindAllByUserID(userID)
+	tasks, err := s.taskRepo.FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	s.attachReactions(tasks, userID)
+	renderTasks(tasks)
+	return tasks, nil
+}
+
+func (s *TaskService) attachReactions(tasks []models.Task, userID string) {
+	if err := s.reactionService.AttachToTasks(tasks, userID); err != nil {
+		log.Printf("[TaskService] failed to load reactions: %v", err)
+	}
 }
 
 // Update task yang udah ada
@@ -59,20 +136,132 @@ func (s *TaskService) Update(id string, userID string, updates map[string]interf
 			return nil, errors.New("invalid Status")
 		}
 	}
+	if err := normalizeDueDate(updates); err != nil {
+		return nil, err
+	}
+	if err := normalizeLabelsUpdate(updates); err != nil {
+		return nil, err
+	}
 
+	before := TaskSnapshot(&task)
 	err = s.db.Model(&task).Updates(updates).Error //✋✊✋✊✋✊
 	if err != nil {
 		return nil, err
 	}
+	after := TaskSnapshot(&task)
+	s.activityService.Record(ActivityEntry{
+		ActorID:     userID,
+		EntityType:  models.ActivityEntityTask,
+		EntityID:    task.ID,
+		Action:      models.ActivityUpdated,
+		WorkspaceID: task.WorkspaceID,
+		TaskID:      &task.ID,
+		Before:      before,
+		After:       after,
+	})
+	if before["dueDate"] != after["dueDate"] {
+		s.reminderService.Reschedule(&task)
+	}
+
+	if description, ok := updates["description"].(string); ok {
+		task.Description = description
+		s.syncDescriptionMentions(&task, userID)
+	}
+	renderTask(&task)
 	return &task, nil
 }
 
+// normalizeDueDate turns the json "dueDate" key into a column gorm understands.
+// null/"" clears the due date
+func normalizeDueDate(updates map[string]interface{}) error {
+	value, ok := updates["dueDate"]
+	if !ok {
+		return nil
+	}
+	delete(updates, "dueDate")
+
+	switch v := value.(type) {
+	case nil:
+		updates["due_date"] = nil
+	case string:
+		if v == "" {
+			updates["due_date"] = nil
+			return nil
+		}
+		dueDate, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return errors.New("invalid dueDate, use RFC3339 format")
+		}
+		updates["due_date"] = dueDate
+	default:
+		return errors.New("invalid dueDate, use RFC3339 format")
+	}
+	return nil
+}
+
+// normalizeLabelsUpdate turns the json labels array ([]interface{}) into a StringList
+func normalizeLabelsUpdate(updates map[string]interface{}) error {
+	value, ok := updates["labels"]
+	if !ok {
+		return nil
+	}
+
+	var raw []string
+	switch v := value.(type) {
+	case nil:
+	case []interface{}:
+		for _, item := range v {
+			label, ok := item.(string)
+			if !ok {
+				return errors.New("labels must be a list of strings")
+			}
+			raw = append(raw, label)
+		}
+	default:
+		return errors.New("labels must be a list of strings")
+	}
+
+	labels, err := NormalizeLabels(raw)
+	if err != nil {
+		return err
+	}
+	updates["labels"] = labels
+	return nil
+}
+
 func (s *TaskService) Delete(id string, userID string) error {
-	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Task{})
-	if result.RowsAffected == 0 {
+	var task models.Task
+	if err := s.db.First(&task, "id = ? AND user_id = ?", id, userID).Error; err != nil {
 		return errors.New("Task not found")
 	}
-	return result.Error
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteTaskReactions(tx, []string{task.ID}); err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Task{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("Task not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.activityService.Record(ActivityEntry{
+		ActorID:     userID,
+		EntityType:  models.ActivityEntityTask,
+		EntityID:    task.ID,
+		Action:      models.ActivityDeleted,
+		WorkspaceID: task.WorkspaceID,
+		TaskID:      &task.ID,
+		Before:      TaskSnapshot(&task),
+	})
+	return nil
 }
 
 func (s *TaskService) GetStats(userID string) (models.TaskStats, error) {
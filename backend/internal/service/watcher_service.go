@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"minitask/internal/models"
+	"minitask/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type WatcherService struct {
+	db                  *gorm.DB
+	watcherRepo         repository.TaskWatcherRepository
+	accessPolicy        *TaskAccessPolicy
+	notificationService *NotificationService
+}
+
+func NewWatcherService(
+	db *gorm.DB,
+	watcherRepo repository.TaskWatcherRepository,
+	accessPolicy *TaskAccessPolicy,
+	notificationService *NotificationService,
+) *WatcherService {
+	return &WatcherService{
+		db:                  db,
+		watcherRepo:         watcherRepo,
+		accessPolicy:        accessPolicy,
+		notificationService: notificationService,
+	}
+}
+
+type WatcherList struct {
+	Watchers []models.TaskWatcher `json:"watchers"`
+	Watching bool                 `json:"watching"`
+}
+
+func (s *WatcherService) GetWatchers(taskID, userID string) (*WatcherList, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.list(task.ID, userID)
+}
+
+func (s *WatcherService) Watch(taskID, userID string) (*WatcherList, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.watcherRepo.Add(task.ID, userID); err != nil {
+		return nil, errors.New("failed to watch task")
+	}
+	return s.list(task.ID, userID)
+}
+
+func (s *WatcherService) Unwatch(taskID, userID string) (*WatcherList, error) {
+	task, err := s.accessPolicy.LoadViewable(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.watcherRepo.Remove(task.ID, userID); err != nil {
+		return nil, errors.New("failed to unwatch task")
+	}
+	return s.list(task.ID, userID)
+}
+
+func (s *WatcherService) list(taskID, userID string) (*WatcherList, error) {
+	watchers, err := s.watcherRepo.FindByTaskID(taskID)
+	if err != nil {
+		return nil, errors.New("failed to load watchers")
+	}
+	list := &WatcherList{Watchers: watchers}
+	if list.Watchers == nil {
+		list.Watchers = []models.TaskWatcher{}
+	}
+	for _, watcher := range watchers {
+		if watcher.UserID == userID {
+			list.Watching = true
+		}
+	}
+	return list, nil
+}
+
+// AutoWatch subscribes users to a task as a side effect of creating, being assigned to or
+// commenting on it. Gagal cuma di log
+func (s *WatcherService) AutoWatch(taskID string, userIDs ...string) {
+	for _, userID := range userIDs {
+		if userID == "" {
+			continue
+		}
+		if err := s.watcherRepo.Add(taskID, userID); err != nil {
+			log.Printf("[WatcherService] failed to add watcher %s to task %s: %v", userID, taskID, err)
+		}
+	}
+}
+
+// RemoveFromWorkspace stops a removed member from watching the workspace's tasks
+func (s *WatcherService) RemoveFromWorkspace(workspaceID, userID string) {
+	if err := s.watcherRepo.RemoveFromWorkspace(workspaceID, userID); err != nil {
+		log.Printf("[WatcherService] failed to remove watches of %s in workspace %s: %v", userID, workspaceID, err)
+	}
+}
+
+// Recipients returns the watchers of a task that can still see it
+func (s *WatcherService) Recipients(task *models.Task) []string {
+	userIDs, err := s.watcherRepo.FindUserIDsByTaskID(task.ID)
+	if err != nil {
+		log.Printf("[WatcherService] failed to load watchers of task %s: %v", task.ID, err)
+		return nil
+	}
+
+	recipients := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if s.accessPolicy.CanView(task, userID) {
+			recipients = append(recipients, userID)
+		}
+	}
+	return recipients
+}
+
+// NotifyWatchers sends input to every watcher that can still see the task, except the ones in skip
+// (e.g. users that already got a mention notification for the same thing)
+func (s *WatcherService) NotifyWatchers(task *models.Task, input NotifyInput, skip map[string]bool) {
+	input.WorkspaceID = task.WorkspaceID
+	input.TaskID = &task.ID
+	for _, userID := range s.Recipients(task) {
+		if skip[userID] {
+			continue
+		}
+		input.UserID = userID
+		s.notificationService.Notify(input)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// errWebhookAddress dipake waktu endpoint nya ngarah ke jaringan internal, response nya gk boleh
+// sampe ke delivery log (itu SSRF)
+var errWebhookAddress = errors.New("webhook url points to a private or local address")
+
+// carrier-grade NAT (100.64.0.0/10) gk ke-cover net.IP.IsPrivate
+var cgnatRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// newWebhookClient checks every address right before connecting (Dialer.Control dapet IP yg udah
+// di-resolve), jadi DNS rebinding gk bisa lolos. Redirect gk diikutin, 3xx dianggap gagal biasa
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || blockedWebhookIP(ip) {
+				return errWebhookAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // lewat proxy = check nya cuma kena IP proxy
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   webhookTimeout,
+			ResponseHeaderTimeout: webhookTimeout,
+			MaxIdleConns:          20,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func blockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || cgnatRange.Contains(ip)
+}
+
+// validateWebhookURL rejects urls that obviously point inside, biar owner langsung dapet error
+// pas bikin webhook. Yg beneran ngejaga tetep newWebhookClient
+func validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("webhook url must be an absolute http(s) url")
+	}
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if blockedWebhookIP(ip) {
+			return errWebhookAddress
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("webhook host %s could not be resolved", host)
+	}
+	for _, addr := range addrs {
+		if blockedWebhookIP(addr.IP) {
+			return errWebhookAddress
+		}
+	}
+	return nil
+}
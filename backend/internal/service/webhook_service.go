@@ -0,0 +1,470 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"minitask/internal/models"
+	"minitask/internal/realtime"
+	"minitask/internal/repository"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	webhookTimeout      = 10 * time.Second
+	webhookMaxAttempts  = 8
+	webhookBaseBackoff  = time.Minute
+	webhookMaxBackoff   = 6 * time.Hour
+	webhookResponseMax  = 2048
+	webhookRetryBatch   = 50
+	webhookSignaturePfx = "sha256="
+	// webhookWorkers caps how many deliveries are sent at once, sisanya nunggu di queue
+	webhookWorkers   = 8
+	webhookQueueSize = 256
+)
+
+// WebhookEvents are the realtime events that can be sent to outgoing webhooks
+var WebhookEvents = []string{
+	realtime.EventTaskCreated,
+	realtime.EventTaskUpdated,
+	realtime.EventTaskDeleted,
+	realtime.EventTaskAssigned,
+	realtime.EventCommentCreated,
+	realtime.EventMemberJoined,
+	realtime.EventMemberRemoved,
+}
+
+type WebhookService struct {
+	db            *gorm.DB
+	webhookRepo   repository.WebhookRepository
+	workspaceRepo repository.WorkspaceRepository
+	client        *http.Client
+	queue         chan webhookJob
+}
+
+// webhookJob is a first attempt of a delivery waiting for a free worker
+type webhookJob struct {
+	deliveryID string
+	webhook    models.Webhook
+}
+
+func NewWebhookService(
+	db *gorm.DB,
+	webhookRepo repository.WebhookRepository,
+	workspaceRepo repository.WorkspaceRepository,
+) *WebhookService {
+	s := &WebhookService{
+		db:            db,
+		webhookRepo:   webhookRepo,
+		workspaceRepo: workspaceRepo,
+		client:        newWebhookClient(),
+		queue:         make(chan webhookJob, webhookQueueSize),
+	}
+	for i := 0; i < webhookWorkers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url"`
+	Events []string `json:"events"` // null = gk diubah, [] = semua event
+	Active *bool    `json:"active"`
+}
+
+// WebhookWithSecret is only returned on create, setelah itu secret gk pernah ditampilin lagi
+type WebhookWithSecret struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryPage struct {
+	Items []models.WebhookDelivery `json:"items"`
+	Page  int                      `json:"page"`
+	Limit int                      `json:"limit"`
+	Total int64                    `json:"total"`
+}
+
+// Publish implements realtime.Publisher. Dipanggil di instance yg bikin event, so every
+// delivery is queued exactly once no matter how many instances are running
+func (s *WebhookService) Publish(event realtime.Event) {
+	if event.Type == realtime.EventWorkspaceDeleted {
+		if err := s.webhookRepo.DeleteByWorkspaceID(event.WorkspaceID); err != nil {
+			log.Printf("[WebhookService] failed to remove webhooks of workspace %s: %v", event.WorkspaceID, err)
+		}
+		return
+	}
+	if !isWebhookEvent(event.Type) {
+		return
+	}
+
+	webhooks, err := s.webhookRepo.FindActiveByWorkspaceID(event.WorkspaceID)
+	if err != nil {
+		log.Printf("[WebhookService] failed to load webhooks of workspace %s: %v", event.WorkspaceID, err)
+		return
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhookWants(&webhook, event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				log.Printf("[WebhookService] failed to encode %s: %v", event.Type, err)
+				return
+			}
+		}
+		now := time.Now()
+		delivery := &models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := s.webhookRepo.CreateDelivery(delivery); err != nil {
+			log.Printf("[WebhookService] failed to queue %s for webhook %s: %v", event.Type, webhook.ID, err)
+			continue
+		}
+		s.enqueue(delivery.ID, webhook)
+	}
+}
+
+// enqueue hands a fresh delivery to the workers without blocking the request. Kalo queue nya
+// penuh delivery nya tetep pending di db, nanti dikirim RetryDeliveries
+func (s *WebhookService) enqueue(deliveryID string, webhook models.Webhook) {
+	select {
+	case s.queue <- webhookJob{deliveryID: deliveryID, webhook: webhook}:
+	default:
+		log.Printf("[WebhookService] delivery queue full, %s waits for the retry job", deliveryID)
+	}
+}
+
+func (s *WebhookService) work() {
+	for job := range s.queue {
+		s.attempt(job.deliveryID, job.webhook)
+	}
+}
+
+// RetryDeliveries sends pending deliveries that are due again, dijalanin scheduler
+func (s *WebhookService) RetryDeliveries() error {
+	deliveries, err := s.webhookRepo.FindRetryableDeliveries(time.Now(), webhookRetryBatch)
+	if err != nil {
+		return err
+	}
+
+	webhooks := map[string]*models.Webhook{}
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			var found models.Webhook
+			if err := s.db.First(&found, "id = ?", delivery.WebhookID).Error; err != nil {
+				continue
+			}
+			webhook = &found
+			webhooks[delivery.WebhookID] = webhook
+		}
+		s.attempt(delivery.ID, *webhook)
+	}
+	return nil
+}
+
+// attempt sends one delivery once. The claim makes sure the first try (worker right after the
+// event) and the retry job never send the same delivery at the same time
+func (s *WebhookService) attempt(deliveryID string, webhook models.Webhook) {
+	now := time.Now()
+	claimed, err := s.webhookRepo.ClaimDelivery(deliveryID, now, now.Add(2*webhookTimeout))
+	if err != nil || !claimed {
+		return
+	}
+	var delivery models.WebhookDelivery
+	if err := s.db.First(&delivery, "id = ?", deliveryID).Error; err != nil {
+		return
+	}
+
+	s.send(&webhook, &delivery)
+	if err := s.webhookRepo.UpdateDelivery(&delivery); err != nil {
+		log.Printf("[WebhookService] failed to save delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// send posts the payload and records the outcome on delivery, scheduling a retry on failure
+func (s *WebhookService) send(webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	started := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &started
+	delivery.Error = ""
+	delivery.ResponseStatus = 0
+	delivery.ResponseBody = ""
+
+	timestamp := strconv.FormatInt(started.Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "MiniTask-Webhook/1.0")
+		req.Header.Set("X-MiniTask-Event", delivery.EventType)
+		req.Header.Set("X-MiniTask-Delivery", delivery.ID)
+		req.Header.Set("X-MiniTask-Timestamp", timestamp)
+		req.Header.Set("X-MiniTask-Signature", SignWebhookPayload(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+		var resp *http.Response
+		resp, err = s.client.Do(req)
+		if err == nil {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseMax))
+			resp.Body.Close()
+			delivery.ResponseStatus = resp.StatusCode
+			delivery.ResponseBody = string(body)
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				err = fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+			}
+		}
+	}
+	delivery.DurationMs = time.Since(started).Milliseconds()
+
+	if err == nil {
+		delivery.Status = models.DeliverySucceeded
+		delivery.NextAttemptAt = nil
+		return
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = models.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+	next := time.Now().Add(webhookBackoff(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+// webhookBackoff: 1m, 2m, 4m, ... capped at 6h
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff << uint(attempts-1)
+	if backoff <= 0 || backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
+
+// SignWebhookPayload returns the X-MiniTask-Signature header value: HMAC-SHA256 of "<timestamp>.<body>"
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return webhookSignaturePfx + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookService) authorizeOwner(workspaceID, userID string) error {
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil || workspace.OwnerID != userID {
+		return errors.New("workspace not found or not authorized")
+	}
+	return nil
+}
+
+func (s *WebhookService) GetAll(workspaceID, userID string) ([]models.Webhook, error) {
+	if err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	webhooks, err := s.webhookRepo.FindAllByWorkspaceID(workspaceID)
+	if err != nil {
+		return nil, errors.New("failed to load webhooks")
+	}
+	if webhooks == nil {
+		webhooks = []models.Webhook{}
+	}
+	return webhooks, nil
+}
+
+func (s *WebhookService) Create(workspaceID, userID string, req *CreateWebhookRequest) (*WebhookWithSecret, error) {
+	if err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	events, err := validateWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, errors.New("failed to create webhook")
+	}
+
+	webhook := &models.Webhook{
+		WorkspaceID: workspaceID,
+		CreatedByID: userID,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      events,
+		Active:      true,
+	}
+	if err := s.webhookRepo.Create(webhook); err != nil {
+		return nil, errors.New("failed to create webhook")
+	}
+	return &WebhookWithSecret{Webhook: *webhook, Secret: secret}, nil
+}
+
+func (s *WebhookService) Update(workspaceID, webhookID, userID string, req *UpdateWebhookRequest) (*models.Webhook, error) {
+	if err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	webhook, err := s.webhookRepo.FindByID(webhookID, workspaceID)
+	if err != nil {
+		return nil, errors.New("webhook not found")
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		events, err := validateWebhookEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Events = events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.webhookRepo.Update(webhook); err != nil {
+		return nil, errors.New("failed to update webhook")
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) Delete(workspaceID, webhookID, userID string) error {
+	if err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return err
+	}
+	webhook, err := s.webhookRepo.FindByID(webhookID, workspaceID)
+	if err != nil {
+		return errors.New("webhook not found")
+	}
+	if err := s.webhookRepo.Delete(webhook.ID); err != nil {
+		return errors.New("failed to delete webhook")
+	}
+	return nil
+}
+
+func (s *WebhookService) GetDeliveries(workspaceID, webhookID, userID string, page, limit int) (*WebhookDeliveryPage, error) {
+	if err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.webhookRepo.FindByID(webhookID, workspaceID); err != nil {
+		return nil, errors.New("webhook not found")
+	}
+
+	page, limit = normalizePage(page, limit)
+	items, total, err := s.webhookRepo.FindDeliveries(webhookID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.New("failed to load deliveries")
+	}
+	if items == nil {
+		items = []models.WebhookDelivery{}
+	}
+	return &WebhookDeliveryPage{Items: items, Page: page, Limit: limit, Total: total}, nil
+}
+
+// Redeliver queues a fresh copy of an old delivery (same payload and event id) and sends it right away
+func (s *WebhookService) Redeliver(workspaceID, webhookID, deliveryID, userID string) (*models.WebhookDelivery, error) {
+	if err := s.authorizeOwner(workspaceID, userID); err != nil {
+		return nil, err
+	}
+	webhook, err := s.webhookRepo.FindByID(webhookID, workspaceID)
+	if err != nil {
+		return nil, errors.New("webhook not found")
+	}
+	original, err := s.webhookRepo.FindDelivery(deliveryID, webhook.ID)
+	if err != nil {
+		return nil, errors.New("delivery not found")
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		WebhookID:      webhook.ID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.DeliveryPending,
+		NextAttemptAt:  &now,
+		RedeliveryOfID: &original.ID,
+	}
+	if err := s.webhookRepo.CreateDelivery(delivery); err != nil {
+		return nil, errors.New("failed to redeliver")
+	}
+
+	s.attempt(delivery.ID, *webhook)
+	if reloaded, err := s.webhookRepo.FindDelivery(delivery.ID, webhook.ID); err == nil {
+		delivery = reloaded
+	}
+	return delivery, nil
+}
+
+func webhookWants(webhook *models.Webhook, eventType string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, t := range webhook.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func isWebhookEvent(eventType string) bool {
+	for _, t := range WebhookEvents {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func validateWebhookEvents(events []string) (models.StringList, error) {
+	list := models.StringList{}
+	seen := map[string]bool{}
+	for _, eventType := range events {
+		if !isWebhookEvent(eventType) {
+			return nil, fmt.Errorf("unsupported webhook event: %s", eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			list = append(list, eventType)
+		}
+	}
+	return list, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"minitask/internal/models"
+)
+
+func TestSendSignsPayload(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	received := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// client biasa, newWebhookClient nolak 127.0.0.1 (lihat TestWebhookClientRefusesLocalTargets)
+	s := &WebhookService{client: srv.Client()}
+	webhook := &models.Webhook{URL: srv.URL, Secret: "whsec_test"}
+	delivery := &models.WebhookDelivery{ID: "d1", EventType: "task.created", Payload: `{"id":"e1"}`, Status: models.DeliveryPending}
+	s.send(webhook, delivery)
+
+	if delivery.Status != models.DeliverySucceeded || delivery.ResponseStatus != http.StatusNoContent {
+		t.Fatalf("delivery = %s %d %q", delivery.Status, delivery.ResponseStatus, delivery.Error)
+	}
+	req := <-received
+	timestamp := req.header.Get("X-MiniTask-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+		t.Fatalf("timestamp header = %q", timestamp)
+	}
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte(timestamp + "." + string(req.body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.header.Get("X-MiniTask-Signature"); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if string(req.body) != delivery.Payload {
+		t.Errorf("body = %q", req.body)
+	}
+	if req.header.Get("X-MiniTask-Event") != "task.created" || req.header.Get("X-MiniTask-Delivery") != "d1" {
+		t.Errorf("event headers = %v", req.header)
+	}
+}
+
+func TestSignWebhookPayloadDependsOnSecretAndTimestamp(t *testing.T) {
+	body := []byte(`{"id":"e1"}`)
+	base := SignWebhookPayload("a", "100", body)
+	if base == SignWebhookPayload("b", "100", body) || base == SignWebhookPayload("a", "101", body) {
+		t.Error("signature should change with the secret and the timestamp")
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"http://127.0.0.1:8080/hook", true},
+		{"http://localhost/hook", true},
+		{"http://[::1]/hook", true},
+		{"http://10.1.2.3/hook", true},
+		{"http://172.16.0.5/hook", true},
+		{"http://192.168.1.10/hook", true},
+		{"http://169.254.169.254/latest/meta-data", true},
+		{"http://[fe80::1]/hook", true},
+		{"http://100.64.0.1/hook", true},
+		{"http://0.0.0.0/hook", true},
+		{"https://93.184.216.34/hook", false},
+	}
+	for _, tt := range tests {
+		err := validateWebhookURL(tt.url)
+		if tt.blocked && !errors.Is(err, errWebhookAddress) {
+			t.Errorf("validateWebhookURL(%q) = %v, want errWebhookAddress", tt.url, err)
+		}
+		if !tt.blocked && err != nil {
+			t.Errorf("validateWebhookURL(%q) = %v, want nil", tt.url, err)
+		}
+	}
+	for _, raw := range []string{"ftp://example.com/x", "/relative", "http://"} {
+		if err := validateWebhookURL(raw); err == nil {
+			t.Errorf("validateWebhookURL(%q) should fail", raw)
+		}
+	}
+}
+
+// validateWebhookURL cuma ngecek pas disimpen, yg beneran ngejaga (DNS rebinding dll) itu client nya
+func TestWebhookClientRefusesLocalTargets(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer srv.Close()
+
+	s := &WebhookService{client: newWebhookClient()}
+	delivery := &models.WebhookDelivery{ID: "d1", EventType: "task.created", Payload: `{}`, Status: models.DeliveryPending}
+	s.send(&models.Webhook{URL: srv.URL, Secret: "whsec_test"}, delivery)
+
+	if hit {
+		t.Fatal("request reached the loopback server")
+	}
+	if delivery.Status != models.DeliveryPending || delivery.Attempts != 1 || delivery.NextAttemptAt == nil {
+		t.Errorf("delivery = %s attempts=%d next=%v, want a scheduled retry", delivery.Status, delivery.Attempts, delivery.NextAttemptAt)
+	}
+	if _, err := newWebhookClient().Get(srv.URL); !errors.Is(err, errWebhookAddress) {
+		t.Errorf("Get() error = %v, want errWebhookAddress", err)
+	}
+}
+
+func TestBlockedWebhookIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "::1", "10.0.0.1", "192.168.0.1", "172.31.255.255", "169.254.1.1", "fe80::1", "fc00::1", "100.127.0.1", "224.0.0.1", "::"} {
+		if !blockedWebhookIP(parseIP(t, ip)) {
+			t.Errorf("%s should be blocked", ip)
+		}
+	}
+	for _, ip := range []string{"1.1.1.1", "93.184.216.34", "2606:4700:4700::1111", "100.128.0.1"} {
+		if blockedWebhookIP(parseIP(t, ip)) {
+			t.Errorf("%s should be allowed", ip)
+		}
+	}
+}
+
+func TestEnqueueDoesNotBlockWhenQueueIsFull(t *testing.T) {
+	// tanpa worker, queue nya langsung penuh
+	s := &WebhookService{queue: make(chan webhookJob, 1)}
+	done := make(chan struct{})
+	go func() {
+		s.enqueue("d1", models.Webhook{})
+		s.enqueue("d2", models.Webhook{})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("enqueue blocked on a full queue")
+	}
+	if job := <-s.queue; job.deliveryID != "d1" {
+		t.Errorf("queued %s, want d1", job.deliveryID)
+	}
+}
+
+func parseIP(t *testing.T, s string) net.IP {
+	t.Helper()
+	ip := net.ParseIP(s)
+	if ip == nil {
+		t.Fatalf("bad ip %q", s)
+	}
+	return ip
+}
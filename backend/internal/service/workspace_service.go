@@ -2,25 +2,47 @@ package service
 
 import (
 	"errors"
+	"log"
+	"minitask/internal/mailer"
 	"minitask/internal/models"
+	"minitask/internal/realtime"
 	"minitask/internal/repository"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// keyPrefixPattern harus cocok sama yg di-parse gitlink, jadi max 10 char
+var keyPrefixPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
 type WorkspaceService struct {
-	db            *gorm.DB
-	workspaceRepo repository.WorkspaceRepository
-	taskRepo      repository.TaskRepository
-	userRepo      repository.UserRepository
+	db                  *gorm.DB
+	workspaceRepo       repository.WorkspaceRepository
+	taskRepo            repository.TaskRepository
+	userRepo            repository.UserRepository
+	mentionService      *MentionService
+	reactionService     *ReactionService
+	activityService     *ActivityService
+	publisher           realtime.Publisher
+	notificationService *NotificationService
+	watcherService      *WatcherService
+	reminderService     *ReminderService
 }
 
 type UpdateWorkspaceTaskRequest struct {
-	Status      *string `json:"status"`
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	AssigneeID  *string `json:"assigneeId"`
+	Status      *string    `json:"status"`
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	AssigneeID  *string    `json:"assigneeId"`
+	DueDate     *time.Time `json:"dueDate"`
+	Labels      *[]string  `json:"labels"`
+	// ClearDueDate removes the due date, soalnya null di DueDate = gk diubah
+	ClearDueDate bool `json:"clearDueDate"`
 }
 
 func NewWorkspaceService(
@@ -28,12 +50,26 @@ func NewWorkspaceService(
 	workspaceRepo repository.WorkspaceRepository,
 	taskRepo repository.TaskRepository,
 	userRepo repository.UserRepository,
+	mentionService *MentionService,
+	reactionService *ReactionService,
+	activityService *ActivityService,
+	publisher realtime.Publisher,
+	notificationService *NotificationService,
+	watcherService *WatcherService,
+	reminderService *ReminderService,
 ) *WorkspaceService {
 	return &WorkspaceService{
-		db:            db,
-		workspaceRepo: workspaceRepo,
-		taskRepo:      taskRepo,
-		userRepo:      userRepo,
+		db:                  db,
+		workspaceRepo:       workspaceRepo,
+		taskRepo:            taskRepo,
+		userRepo:            userRepo,
+		mentionService:      mentionService,
+		reactionService:     reactionService,
+		activityService:     activityService,
+		publisher:           publisher,
+		notificationService: notificationService,
+		watcherService:      watcherService,
+		reminderService:     reminderService,
 	}
 }
 
@@ -45,6 +81,12 @@ type CreateWorkspaceRequest struct {
 type UpdateWorkspaceRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// KeyPrefix renames the task key prefix, key task yg udah ada ikut diganti
+	KeyPrefix string `json:"keyPrefix"`
+}
+
+type InviteByEmailRequest struct {
+	Email string `json:"email"`
 }
 
 type JoinWorkspaceRequest struct {
@@ -52,9 +94,11 @@ type JoinWorkspaceRequest struct {
 }
 
 type CreateWorkspaceTaskRequest struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	AssigneeID  *string `json:"assigneeId"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	AssigneeID  *string    `json:"assigneeId"`
+	DueDate     *time.Time `json:"dueDate"`
+	Labels      []string   `json:"labels"`
 }
 
 type AssignTaskRequest struct {
@@ -88,6 +132,7 @@ func (s *WorkspaceService) Create(req *CreateWorkspaceRequest, ownerID string) (
 		return nil, errors.New("failed to assign owner role")
 	}
 
+	s.recordWorkspace(ownerID, workspace.ID, models.ActivityCreated, nil, WorkspaceSnapshot(workspace))
 	return s.workspaceRepo.FindByID(workspace.ID)
 }
 
@@ -114,23 +159,49 @@ func (s *WorkspaceService) Update(id, ownerID string, req *UpdateWorkspaceReques
 		return nil, errors.New("workspace not found or not authorized")
 	}
 
+	before := WorkspaceSnapshot(workspace)
 	if req.Name != "" {
 		workspace.Name = req.Name
 	}
 	workspace.Description = req.Description
 
+	var prefix string
+	if req.KeyPrefix != "" {
+		prefix = strings.ToUpper(strings.TrimSpace(req.KeyPrefix))
+		if !keyPrefixPattern.MatchString(prefix) {
+			return nil, errors.New("key prefix must be 2-10 letters or digits and start with a letter")
+		}
+	}
+
 	err = s.workspaceRepo.Update(workspace)
 	if err != nil {
 		return nil, errors.New("failed to update workspace")
 	}
+	if prefix != "" && prefix != workspace.KeyPrefix {
+		if err := s.workspaceRepo.UpdateKeyPrefix(workspace.ID, prefix); err != nil {
+			return nil, errors.New("failed to update key prefix")
+		}
+		workspace.KeyPrefix = prefix
+	}
+
+	s.recordWorkspace(ownerID, workspace.ID, models.ActivityUpdated, before, WorkspaceSnapshot(workspace))
+	s.publisher.Publish(realtime.NewEvent(realtime.EventWorkspaceUpdated, workspace.ID, ownerID, workspace))
 	return workspace, nil
 }
 
 func (s *WorkspaceService) Delete(id, ownerID string) error {
-	err := s.workspaceRepo.Delete(id, ownerID)
+	workspace, err := s.workspaceRepo.FindByID(id)
+	if err != nil || workspace.OwnerID != ownerID {
+		return errors.New("workspace not found or not authorized")
+	}
+
+	err = s.workspaceRepo.Delete(id, ownerID)
 	if err != nil {
 		return errors.New("workspace not found or not authorized")
 	}
+
+	s.recordWorkspace(ownerID, id, models.ActivityDeleted, WorkspaceSnapshot(workspace), nil)
+	s.publisher.Publish(realtime.NewEvent(realtime.EventWorkspaceDeleted, id, ownerID, nil))
 	return nil
 }
 
@@ -138,6 +209,10 @@ func (s *WorkspaceService) JoinByInviteCode(req *JoinWorkspaceRequest, userID st
 	if req.InviteCode == "" {
 		return nil, errors.New("invite code is required")
 	}
+	// akun yg belum verified gk boleh join, biar invite code yg bocor gk bisa dipake akun bodong
+	if user, err := s.userRepo.FindByID(userID); err != nil || !user.EmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
 
 	workspace, err := s.workspaceRepo.FindByInviteCode(req.InviteCode)
 	if err != nil {
@@ -163,6 +238,8 @@ func (s *WorkspaceService) JoinByInviteCode(req *JoinWorkspaceRequest, userID st
 		return nil, errors.New("failed to join workspace")
 	}
 
+	s.recordMember(userID, workspace.ID, userID, models.ActivityJoined)
+	s.publisher.Publish(realtime.NewEvent(realtime.EventMemberJoined, workspace.ID, userID, realtime.MemberData{UserID: userID}))
 	return s.workspaceRepo.FindByID(workspace.ID)
 }
 
@@ -180,7 +257,21 @@ func (s *WorkspaceService) RemoveMember(workspaceID, ownerID, targetUserID strin
 		return errors.New("owner cannot be removed from the workspace")
 	}
 
-	return s.workspaceRepo.RemoveMember(workspaceID, targetUserID)
+	if err := s.workspaceRepo.RemoveMember(workspaceID, targetUserID); err != nil {
+		return err
+	}
+
+	s.watcherService.RemoveFromWorkspace(workspaceID, targetUserID)
+	s.recordMember(ownerID, workspaceID, targetUserID, models.ActivityRemoved)
+	s.notificationService.Notify(NotifyInput{
+		UserID:      targetUserID,
+		Type:        models.NotificationWorkspaceRemoved,
+		ActorID:     ownerID,
+		WorkspaceID: &workspaceID,
+		Subject:     workspace.Name,
+	})
+	s.publisher.Publish(realtime.NewEvent(realtime.EventMemberRemoved, workspaceID, ownerID, realtime.MemberData{UserID: targetUserID}))
+	return nil
 }
 
 func (s *WorkspaceService) RefreshInviteCode(workspaceID, ownerID string) (string, error) {
@@ -194,9 +285,60 @@ func (s *WorkspaceService) RefreshInviteCode(workspaceID, ownerID string) (strin
 	if err != nil {
 		return "", errors.New("failed to refresh invite code")
 	}
+
+	s.recordWorkspace(ownerID, workspaceID, models.ActivityInviteRefreshed, nil, nil)
 	return newCode, nil
 }
+
+// InviteByEmail emails the workspace invite code to someone (owner only)
+func (s *WorkspaceService) InviteByEmail(workspaceID, ownerID string, req *InviteByEmailRequest) error {
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil || workspace.OwnerID != ownerID {
+		return errors.New("workspace not found or not authorized")
+	}
+	address, err := mail.ParseAddress(req.Email)
+	if err != nil {
+		return errors.New("invalid email address")
+	}
+	owner, err := s.userRepo.FindByID(ownerID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	// email invite dikirim atas nama kita, jadi pengirimnya harus udah verified
+	if !owner.EmailVerified() {
+		return ErrEmailNotVerified
+	}
+
+	msg, err := mailer.Render(mailer.TemplateWorkspaceInvite, address.Address, mailer.InviteEmail{
+		InviterName:   owner.Username,
+		WorkspaceName: workspace.Name,
+		InviteCode:    workspace.InviteCode,
+		Link:          frontendURL() + "/dashboard?invite=" + url.QueryEscape(workspace.InviteCode),
+	})
+	if err != nil {
+		return errors.New("failed to prepare invite email")
+	}
+	s.notificationService.SendAsync(msg)
+	return nil
+}
 func (s *WorkspaceService) CreateTask(workspaceID, requesterID string, req *CreateWorkspaceTaskRequest) (*models.Task, error) {
+	task, err := s.prepareTask(workspaceID, requesterID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.taskRepo.Create(task)
+	if err != nil {
+		return nil, errors.New("failed to create task")
+	}
+
+	s.afterCreateTask(task, requesterID)
+	renderTask(task)
+	return task, nil
+}
+
+// prepareTask validates a new workspace task without saving it, dipake juga sama import
+func (s *WorkspaceService) prepareTask(workspaceID, requesterID string, req *CreateWorkspaceTaskRequest) (*models.Task, error) {
 	if req.Title == "" {
 		return nil, errors.New("title cannot be empty")
 	}
@@ -207,7 +349,8 @@ func (s *WorkspaceService) CreateTask(workspaceID, requesterID string, req *Crea
 	}
 
 	if req.AssigneeID != nil && *req.AssigneeID != requesterID {
-		if member.Role != models.RoleOwner {
+		// bot (incoming webhook) boleh assign, payload nya nentuin assignee
+		if member.Role != models.RoleOwner && member.Role != models.RoleBot {
 			return nil, errors.New("only the owner can assign tasks to others")
 		}
 		isMember, _ := s.workspaceRepo.IsMember(workspaceID, *req.AssigneeID)
@@ -215,21 +358,32 @@ func (s *WorkspaceService) CreateTask(workspaceID, requesterID string, req *Crea
 			return nil, errors.New("assignee is not a member of this workspace")
 		}
 	}
+	labels, err := NormalizeLabels(req.Labels)
+	if err != nil {
+		return nil, err
+	}
 
-	task := &models.Task{
+	return &models.Task{
 		Title:       req.Title,
 		Description: req.Description,
 		UserID:      requesterID,
 		WorkspaceID: &workspaceID,
 		AssigneeID:  req.AssigneeID,
+		DueDate:     req.DueDate,
+		Labels:      labels,
 		Status:      models.StatusNotStarted,
-	}
+	}, nil
+}
 
-	err = s.taskRepo.Create(task)
-	if err != nil {
-		return nil, errors.New("failed to create task")
+func (s *WorkspaceService) afterCreateTask(task *models.Task, requesterID string) {
+	if _, err := s.mentionService.SyncTaskMentions(task, requesterID); err != nil {
+		log.Printf("[WorkspaceService] failed to save mentions for task %s: %v", task.ID, err)
 	}
-	return task, nil
+
+	s.recordTask(requesterID, task, models.ActivityCreated, nil, TaskSnapshot(task))
+	s.publishTask(realtime.EventTaskCreated, requesterID, task)
+	s.watcherService.AutoWatch(task.ID, requesterID)
+	s.notifyAssignee(task, requesterID, nil)
 }
 
 func (s *WorkspaceService) GetTasks(workspaceID, userID string) ([]models.Task, error) {
@@ -238,7 +392,13 @@ func (s *WorkspaceService) GetTasks(workspaceID, userID string) ([]models.Task,
 		return nil, errors.New("workspace not found or access denied")
 	}
 
-	return s.taskRepo.FindAllByWorkspaceID(workspaceID)
+	tasks, err := s.taskRepo.FindAllByWorkspaceID(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	s.attachReactions(tasks, userID)
+	renderTasks(tasks)
+	return tasks, nil
 }
 
 func (s *WorkspaceService) GetTask(workspaceID, taskID, userID string) (*models.Task, error) {
@@ -247,11 +407,29 @@ func (s *WorkspaceService) GetTask(workspaceID, taskID, userID string) (*models.
 		return nil, errors.New("access denied")
 	}
 
-	task, err := s.taskRepo.FindByWorkspaceAndTaskID(workspaceID, taskID)
+	task, err := s.findTask(workspaceID, taskID)
 	if err != nil {
 		return nil, errors.New("task not found")
 	}
-	return task, nil
+
+	tasks := []models.Task{*task}
+	s.attachReactions(tasks, userID)
+	renderTasks(tasks)
+	return &tasks[0], nil
+}
+
+// findTask accepts either the task UUID or its key ("WS-42", case insensitive)
+func (s *WorkspaceService) findTask(workspaceID, ref string) (*models.Task, error) {
+	if _, err := uuid.Parse(ref); err == nil {
+		return s.taskRepo.FindByWorkspaceAndTaskID(workspaceID, ref)
+	}
+	return s.taskRepo.FindByWorkspaceAndKey(workspaceID, strings.ToUpper(ref))
+}
+
+func (s *WorkspaceService) attachReactions(tasks []models.Task, userID string) {
+	if err := s.reactionService.AttachToTasks(tasks, userID); err != nil {
+		log.Printf("[WorkspaceService] failed to load reactions: %v", err)
+	}
 }
 
 func (s *WorkspaceService) AssignTask(workspaceID, taskID, ownerID string, req *AssignTaskRequest) (*models.Task, error) {
@@ -260,7 +438,7 @@ func (s *WorkspaceService) AssignTask(workspaceID, taskID, ownerID string, req *
 		return nil, errors.New("workspace not found or not authorized")
 	}
 
-	task, err := s.taskRepo.FindByWorkspaceAndTaskID(workspaceID, taskID)
+	task, err := s.findTask(workspaceID, taskID)
 	if err != nil {
 		return nil, errors.New("task not found in this workspace")
 	}
@@ -272,37 +450,42 @@ func (s *WorkspaceService) AssignTask(workspaceID, taskID, ownerID string, req *
 		}
 	}
 
+	before := TaskSnapshot(task)
+	previousAssignee := task.AssigneeID
 	task.AssigneeID = req.AssigneeID
 	err = s.taskRepo.Update(task)
 	if err != nil {
 		return nil, errors.New("failed to assign task")
 	}
+
+	s.recordTask(ownerID, task, models.ActivityAssigned, before, TaskSnapshot(task))
+	s.publishTask(realtime.EventTaskAssigned, ownerID, task)
+	s.notifyAssignee(task, ownerID, previousAssignee)
+	renderTask(task)
 	return task, nil
 }
-func (s *WorkspaceService) UpdateTask(workspaceID, taskID, requesterID string, req *UpdateWorkspaceTaskRequest) (*models.Task, error) {
-	println("DEBUG Service - workspaceID:", workspaceID, "taskID:", taskID, "requesterID:", requesterID)
 
+func (s *WorkspaceService) UpdateTask(workspaceID, taskID, requesterID string, req *UpdateWorkspaceTaskRequest) (*models.Task, error) {
 	// Check if user is a member of the workspace
 	member, err := s.workspaceRepo.FindMember(workspaceID, requesterID)
 	if err != nil {
-		println("DEBUG Service - FindMember error:", err.Error())
 		return nil, errors.New("access denied: not a workspace member")
 	}
-	println("DEBUG Service - member role:", member.Role)
 
 	// Find the task
-	task, err := s.taskRepo.FindByWorkspaceAndTaskID(workspaceID, taskID)
+	task, err := s.findTask(workspaceID, taskID)
 	if err != nil {
-		println("DEBUG Service - FindByWorkspaceAndTaskID error:", err.Error())
 		return nil, errors.New("task not found")
 	}
-	println("DEBUG Service - task found:", task.ID, "title:", task.Title)
 
-	// Members can only update status
+	before := TaskSnapshot(task)
+	previousAssignee := task.AssigneeID
+
+	// Members (and bots) can only update status
 	// Owners can update all fields
-	if member.Role == models.RoleMember {
+	if member.Role != models.RoleOwner {
 		// Members trying to edit title/description/assignee should be rejected
-		if req.Title != nil || req.Description != nil || req.AssigneeID != nil {
+		if req.Title != nil || req.Description != nil || req.AssigneeID != nil || req.DueDate != nil || req.ClearDueDate || req.Labels != nil {
 			return nil, errors.New("members can only update task status")
 		}
 		if req.Status != nil {
@@ -319,6 +502,18 @@ func (s *WorkspaceService) UpdateTask(workspaceID, taskID, requesterID string, r
 		if req.AssigneeID != nil {
 			task.AssigneeID = req.AssigneeID
 		}
+		if req.DueDate != nil {
+			task.DueDate = req.DueDate
+		} else if req.ClearDueDate {
+			task.DueDate = nil
+		}
+		if req.Labels != nil {
+			labels, err := NormalizeLabels(*req.Labels)
+			if err != nil {
+				return nil, err
+			}
+			task.Labels = labels
+		}
 		if req.Status != nil {
 			task.Status = *req.Status
 		}
@@ -328,6 +523,29 @@ func (s *WorkspaceService) UpdateTask(workspaceID, taskID, requesterID string, r
 	if err != nil {
 		return nil, errors.New("failed to update task")
 	}
+
+	if req.Description != nil {
+		if _, err := s.mentionService.SyncTaskMentions(task, requesterID); err != nil {
+			log.Printf("[WorkspaceService] failed to save mentions for task %s: %v", task.ID, err)
+		}
+	}
+
+	after := TaskSnapshot(task)
+	s.recordTask(requesterID, task, models.ActivityUpdated, before, after)
+	s.publishTask(realtime.EventTaskUpdated, requesterID, task)
+	s.notifyAssignee(task, requesterID, previousAssignee)
+	if before["dueDate"] != after["dueDate"] {
+		s.reminderService.Reschedule(task)
+	}
+	if before["status"] != task.Status {
+		s.watcherService.NotifyWatchers(task, NotifyInput{
+			Type:    models.NotificationTaskUpdated,
+			ActorID: requesterID,
+			Subject: task.Title,
+			Excerpt: "Status changed to " + task.Status,
+		}, nil)
+	}
+	renderTask(task)
 	return task, nil
 }
 
@@ -336,5 +554,88 @@ func (s *WorkspaceService) DeleteTask(workspaceID, taskID, requesterID string) e
 	if err != nil || member.Role != models.RoleOwner {
 		return errors.New("only the owner can delete workspace tasks")
 	}
-	return s.db.Where("id = ? AND workspace_id = ?", taskID, workspaceID).Delete(&models.Task{}).Error
+
+	task, err := s.findTask(workspaceID, taskID)
+	if err != nil {
+		return errors.New("task not found")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteTaskReactions(tx, []string{task.ID}); err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND workspace_id = ?", task.ID, workspaceID).Delete(&models.Task{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recordTask(requesterID, task, models.ActivityDeleted, TaskSnapshot(task), nil)
+	s.publisher.Publish(realtime.NewEvent(realtime.EventTaskDeleted, workspaceID, requesterID, map[string]string{"id": task.ID}))
+	return nil
+}
+
+// notifyAssignee only fires when the assignee actually changed to someone, and makes them a watcher
+func (s *WorkspaceService) notifyAssignee(task *models.Task, actorID string, previousAssignee *string) {
+	if task.AssigneeID == nil {
+		return
+	}
+	if previousAssignee != nil && *previousAssignee == *task.AssigneeID {
+		return
+	}
+	s.watcherService.AutoWatch(task.ID, *task.AssigneeID)
+	s.notificationService.Notify(NotifyInput{
+		UserID:      *task.AssigneeID,
+		Type:        models.NotificationTaskAssigned,
+		ActorID:     actorID,
+		WorkspaceID: task.WorkspaceID,
+		TaskID:      &task.ID,
+		Subject:     task.Title,
+	})
+}
+
+// publishTask sends the task without its comments, those have their own events
+func (s *WorkspaceService) publishTask(eventType, actorID string, task *models.Task) {
+	if task.WorkspaceID == nil {
+		return
+	}
+	payload := *task
+	payload.Comments = nil
+	renderTask(&payload)
+	s.publisher.Publish(realtime.NewEvent(eventType, *task.WorkspaceID, actorID, payload))
+}
+
+func (s *WorkspaceService) recordTask(actorID string, task *models.Task, action string, before, after map[string]interface{}) {
+	s.activityService.Record(ActivityEntry{
+		ActorID:     actorID,
+		EntityType:  models.ActivityEntityTask,
+		EntityID:    task.ID,
+		Action:      action,
+		WorkspaceID: task.WorkspaceID,
+		TaskID:      &task.ID,
+		Before:      before,
+		After:       after,
+	})
+}
+
+func (s *WorkspaceService) recordWorkspace(actorID, workspaceID, action string, before, after map[string]interface{}) {
+	s.activityService.Record(ActivityEntry{
+		ActorID:     actorID,
+		EntityType:  models.ActivityEntityWorkspace,
+		EntityID:    workspaceID,
+		Action:      action,
+		WorkspaceID: &workspaceID,
+		Before:      before,
+		After:       after,
+	})
+}
+
+func (s *WorkspaceService) recordMember(actorID, workspaceID, memberUserID, action string) {
+	s.activityService.Record(ActivityEntry{
+		ActorID:     actorID,
+		EntityType:  models.ActivityEntityMember,
+		EntityID:    memberUserID,
+		Action:      action,
+		WorkspaceID: &workspaceID,
+	})
 }
@@ -51,6 +51,7 @@ export const useLogin = () => {
         mutationFn: (data: LoginRequest) => authService.login(data),
         onSuccess: (data: AuthResponse) => {
             localStorage.setItem(STORAGE_KEYS.TOKEN, data.token);
+            localStorage.setItem(STORAGE_KEYS.REFRESH_TOKEN, data.refreshToken);
             localStorage.setItem(STORAGE_KEYS.USER, JSON.stringify(data.user));
             queryClient.setQueryData([QUERY_KEYS.AUTH.PROFILE], data.user);
         }
@@ -63,6 +64,7 @@ export const useRegister = () => {
         mutationFn: (data: RegisterRequest) => authService.register(data),
         onSuccess: (data: AuthResponse) => {
             localStorage.setItem(STORAGE_KEYS.TOKEN, data.token);
+            localStorage.setItem(STORAGE_KEYS.REFRESH_TOKEN, data.refreshToken);
             localStorage.setItem(STORAGE_KEYS.USER, JSON.stringify(data.user));
             queryClient.setQueryData([QUERY_KEYS.AUTH.PROFILE], data.user);
         }
@@ -90,10 +92,20 @@ export const useAuth = (): UseAuthReturn => {
     }, [user]);
 
     const logout = () => {
-        localStorage.removeItem(STORAGE_KEYS.TOKEN);
-        localStorage.removeItem(STORAGE_KEYS.USER);
-        queryClient.clear();
-        window.location.href = '/login';
+        const refreshToken = localStorage.getItem(STORAGE_KEYS.REFRESH_TOKEN);
+        const clear = () => {
+            localStorage.removeItem(STORAGE_KEYS.TOKEN);
+            localStorage.removeItem(STORAGE_KEYS.REFRESH_TOKEN);
+            localStorage.removeItem(STORAGE_KEYS.USER);
+            queryClient.clear();
+            window.location.href = '/login';
+        };
+        if (!refreshToken) {
+            clear();
+            return;
+        }
+        // revoke the session server side first, local state is cleared either way
+        authService.logout(refreshToken).catch(() => undefined).finally(clear);
     };
 
     // If we have initial data from localStorage, consider auth check done
@@ -1,6 +1,7 @@
 import { API_BASE_URL, STORAGE_KEYS } from '@/utils/constants';
 import axios from 'axios';
 import type { AxiosError, AxiosResponse, InternalAxiosRequestConfig } from 'axios';
+import type { AuthResponse } from '@/types';
 
 const api = axios.create({
     baseURL: `${API_BASE_URL}/api/v1`,
@@ -20,12 +21,53 @@ api.interceptors.request.use(
         return Promise.reject(error);
     }
 )
+// one refresh at a time, concurrent 401s wait for the same rotation
+let refreshing: Promise<string> | null = null;
+
+// tabs share localStorage, so they take turns through a Web Lock; a tab that waited just
+// picks up the token the other tab already rotated instead of reusing the old refresh token
+const refreshAccessToken = (staleToken: string | null): Promise<string> => {
+    if (!refreshing) {
+        const rotate = async (): Promise<string> => {
+            const current = localStorage.getItem(STORAGE_KEYS.TOKEN);
+            if (current && current !== staleToken) {
+                return current;
+            }
+            const refreshToken = localStorage.getItem(STORAGE_KEYS.REFRESH_TOKEN);
+            if (!refreshToken) {
+                throw new Error('no refresh token');
+            }
+            const { data } = await axios.post<AuthResponse>(`${API_BASE_URL}/api/v1/auth/refresh`, { refreshToken });
+            localStorage.setItem(STORAGE_KEYS.TOKEN, data.token);
+            localStorage.setItem(STORAGE_KEYS.REFRESH_TOKEN, data.refreshToken);
+            return data.token;
+        };
+        refreshing = ('locks' in navigator ? navigator.locks.request('minitask-token-refresh', rotate) : rotate()).finally(() => {
+            refreshing = null;
+        });
+    }
+    return refreshing;
+};
+
 api.interceptors.response.use(
     (response: AxiosResponse) => response,
-    (error: AxiosError<{ error?: string; message?: string }>) => {
-        if (error.response?.status === 401) {
+    async (error: AxiosError<{ error?: string; message?: string }>) => {
+        const original = error.config as (InternalAxiosRequestConfig & { _retried?: boolean }) | undefined;
+        if (error.response?.status === 401 && original && !original._retried && !original.url?.startsWith('/auth/')) {
+            original._retried = true;
+            try {
+                const sent = String(original.headers.Authorization ?? '').replace(/^Bearer /, '');
+                const token = await refreshAccessToken(sent || null);
+                original.headers.Authorization = `Bearer ${token}`;
+                return api(original);
+            } catch {
+                // fall through to logout below
+            }
+        }
+        if (error.response?.status === 401 && !original?.url?.startsWith('/auth/')) {
             //clear token, redirect login
             localStorage.removeItem(STORAGE_KEYS.TOKEN);
+            localStorage.removeItem(STORAGE_KEYS.REFRESH_TOKEN);
             localStorage.removeItem(STORAGE_KEYS.USER);
             window.location.href = '/login';
         }
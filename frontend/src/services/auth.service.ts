@@ -12,6 +12,10 @@ export const authService = {
         const response = await api.post<AuthResponse>('/auth/login', data);
         return response.data;
     },
+    //revoke the session behind this refresh token
+    logout: async (refreshToken: string): Promise<void> => {
+        await api.post('/auth/logout', { refreshToken });
+    },
     //get current profile
     getProfile: async (): Promise<User> => {
         const response = await api.get<User>('/profile');
@@ -9,10 +9,13 @@ export interface User {
     tasks?: Task[];
     plan: 'free' | 'pro';
     planExpiresAt: string | null;
+    emailVerifiedAt: string | null;
 }
 
 export interface AuthResponse {
     token: string;
+    expiresAt: string;
+    refreshToken: string;
     user: User;
 }
 export interface RegisterRequest {
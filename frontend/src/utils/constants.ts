@@ -27,5 +27,6 @@ export const QUERY_KEYS = {
 
 export const STORAGE_KEYS = {
     TOKEN: 'token',
+    REFRESH_TOKEN: 'refreshToken',
     USER: 'user'
 } as const
\ No newline at end of file